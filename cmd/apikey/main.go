@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kuromii5/time-tracker/internal/audit"
+	"github.com/kuromii5/time-tracker/internal/auth"
+	"github.com/kuromii5/time-tracker/internal/config"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+const usage = `Manage API keys.
+
+Usage:
+  apikey create -name NAME [-user ID] [-admin]
+  apikey list
+  apikey revoke -id ID
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.MustLoad()
+	// api keys don't touch passports, so no keyring is needed
+	db, err := repo.New(cfg.DbUrl, l.New(cfg.Env), nil)
+	if err != nil {
+		log.Fatalf("Failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	ctx = audit.WithActor(ctx, "cli:apikey")
+
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "create":
+		err = create(ctx, db, args)
+	case "list":
+		err = list(ctx, db)
+	case "revoke":
+		err = revoke(ctx, db, args)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("%s: %v", os.Args[1], err)
+	}
+}
+
+// create issues a key and prints it. This is how the first admin key is made.
+func create(ctx context.Context, db *repo.DB, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	name := fs.String("name", "", "Key name")
+	userID := fs.Int("user", 0, "ID of the user the key acts as")
+	admin := fs.Bool("admin", false, "Allow managing API keys")
+	fs.Parse(args)
+
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+
+	key, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return err
+	}
+
+	keyID, err := db.CreateAPIKey(ctx, models.APIKey{
+		Name:   *name,
+		Prefix: prefix,
+		UserID: int32(*userID),
+		Admin:  *admin,
+	}, hash)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created key %d. Store it now, it is not shown again:\n%s\n", keyID, key)
+	return nil
+}
+
+func list(ctx context.Context, db *repo.DB) error {
+	keys, err := db.APIKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tPREFIX\tUSER\tADMIN\tLAST USED\tREVOKED")
+	for _, key := range keys {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%t\t%s\t%s\n",
+			key.ID, key.Name, key.Prefix, key.UserID, key.Admin, optionalTime(key.LastUsedAt), optionalTime(key.RevokedAt))
+	}
+
+	return tw.Flush()
+}
+
+func revoke(ctx context.Context, db *repo.DB, args []string) error {
+	fs := flag.NewFlagSet("revoke", flag.ExitOnError)
+	id := fs.Int("id", 0, "Key ID")
+	fs.Parse(args)
+
+	if *id == 0 {
+		return fmt.Errorf("-id is required")
+	}
+	if err := db.RevokeAPIKey(ctx, int32(*id)); err != nil {
+		return err
+	}
+
+	fmt.Printf("Revoked key %d\n", *id)
+	return nil
+}
+
+func optionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
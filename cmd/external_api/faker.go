@@ -0,0 +1,49 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+)
+
+// fakePerson makes up a person for the passport. The same passport always gets the same person.
+func fakePerson(passport string) models.People {
+	sum := sha256.Sum256([]byte(passport))
+	rnd := rand.New(rand.NewPCG(binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:16])))
+
+	pick := func(list []string) string {
+		return list[rnd.IntN(len(list))]
+	}
+
+	surname := pick(surnames)
+	father := pick(fatherNames)
+	person := models.People{
+		Address: fmt.Sprintf("г. %s, %s %s, д. %d, кв. %d", pick(cities), pick(streetKinds), pick(streets), rnd.IntN(120)+1, rnd.IntN(300)+1),
+	}
+	if rnd.IntN(2) == 0 {
+		person.Name = pick(maleNames)
+		person.Surname = surname
+		person.Patronymic = father + "ович"
+	} else {
+		person.Name = pick(femaleNames)
+		person.Surname = surname + "а"
+		person.Patronymic = father + "овна"
+	}
+
+	return person
+}
+
+var (
+	maleNames   = []string{"Иван", "Алексей", "Дмитрий", "Сергей", "Андрей", "Михаил", "Николай", "Павел", "Артём", "Егор"}
+	femaleNames = []string{"Анна", "Мария", "Елена", "Ольга", "Татьяна", "Наталья", "Ирина", "Екатерина", "Дарья", "Светлана"}
+	// surnames take "а" in the feminine
+	surnames = []string{"Иванов", "Смирнов", "Кузнецов", "Попов", "Васильев", "Петров", "Соколов", "Михайлов", "Новиков", "Фёдоров", "Морозов", "Волков"}
+	// father names take "ович" or "овна"
+	fatherNames = []string{"Иван", "Петр", "Степан", "Роман", "Антон", "Богдан", "Глеб", "Олег", "Семен", "Федор"}
+	cities      = []string{"Москва", "Санкт-Петербург", "Казань", "Новосибирск", "Екатеринбург", "Нижний Новгород", "Самара", "Томск"}
+	streetKinds = []string{"ул.", "пр.", "пер.", "б-р"}
+	streets     = []string{"Ленина", "Мира", "Гагарина", "Пушкина", "Чехова", "Толстого", "Кирова", "Победы", "Маяковского", "Лермонтова"}
+)
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// Fault is injected into every request of an endpoint: the response is delayed by Latency plus
+// a random part of Jitter, and ErrorRate of the requests fail with ErrorStatus (500 by default).
+type Fault struct {
+	Latency     Duration `json:"latency" yaml:"latency"`
+	Jitter      Duration `json:"jitter" yaml:"jitter"`
+	ErrorRate   float64  `json:"error_rate" yaml:"error_rate"`
+	ErrorStatus int      `json:"error_status,omitempty" yaml:"error_status"`
+}
+
+func (f Fault) validate() error {
+	if f.Latency < 0 || f.Jitter < 0 {
+		return fmt.Errorf("latency and jitter should not be negative")
+	}
+	if f.ErrorRate < 0 || f.ErrorRate > 1 {
+		return fmt.Errorf("error_rate should be between 0 and 1")
+	}
+	if f.ErrorStatus != 0 && (f.ErrorStatus < 400 || f.ErrorStatus > 599) {
+		return fmt.Errorf("error_status should be 4xx or 5xx")
+	}
+	return nil
+}
+
+// Duration is a time.Duration written as "200ms" in JSON and YAML
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return d.parse(s)
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	return d.parse(node.Value)
+}
+
+func (d *Duration) parse(s string) error {
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Faults holds the faults of endpoints by their route, e.g. "/info"
+type Faults struct {
+	mu     sync.RWMutex
+	faults map[string]Fault
+}
+
+func NewFaults(faults map[string]Fault) (*Faults, error) {
+	f := &Faults{}
+	return f, f.Set(faults)
+}
+
+func (f *Faults) Get() map[string]Fault {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	faults := make(map[string]Fault, len(f.faults))
+	for route, fault := range f.faults {
+		faults[route] = fault
+	}
+	return faults
+}
+
+// Set replaces all faults
+func (f *Faults) Set(faults map[string]Fault) error {
+	for route, fault := range faults {
+		if err := fault.validate(); err != nil {
+			return fmt.Errorf("%s: %w", route, err)
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.faults = make(map[string]Fault, len(faults))
+	for route, fault := range faults {
+		f.faults[route] = fault
+	}
+	return nil
+}
+
+// Middleware injects the fault of the matched route
+func (f *Faults) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		f.mu.RLock()
+		fault, ok := f.faults[c.FullPath()]
+		f.mu.RUnlock()
+		if !ok {
+			c.Next()
+			return
+		}
+
+		delay := time.Duration(fault.Latency)
+		if fault.Jitter > 0 {
+			delay += rand.N(time.Duration(fault.Jitter))
+		}
+		if delay > 0 {
+			select {
+			case <-c.Request.Context().Done():
+				c.Abort()
+				return
+			case <-time.After(delay):
+			}
+		}
+
+		if fault.ErrorRate > 0 && rand.Float64() < fault.ErrorRate {
+			status := fault.ErrorStatus
+			if status == 0 {
+				status = http.StatusInternalServerError
+			}
+			c.AbortWithStatusJSON(status, gin.H{"error": "injected fault"})
+			return
+		}
+
+		c.Next()
+	}
+}
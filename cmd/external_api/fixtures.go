@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixtures is what the API knows, loaded from a JSON or YAML file:
+//
+//	people:
+//	  "1234 567890": {name: Иван, surname: Иванов, patronymic: Иванович, address: "г. Москва, ул. Ленина, д. 5, кв. 1"}
+//	blacklist: ["0000 000000"]
+//	faults:
+//	  /info: {latency: 200ms, jitter: 100ms, error_rate: 0.1, error_status: 503}
+//
+// Passports are "<serie> <number>". People of passports that are neither listed nor blacklisted are made up.
+type Fixtures struct {
+	People    map[string]models.People `json:"people" yaml:"people"`
+	Blacklist []string                 `json:"blacklist" yaml:"blacklist"`
+	Faults    map[string]Fault         `json:"faults" yaml:"faults"`
+}
+
+// LoadFixtures reads fixtures from a .json, .yaml or .yml file
+func LoadFixtures(path string) (Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fixtures{}, err
+	}
+
+	var fixtures Fixtures
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, &fixtures)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &fixtures)
+	default:
+		return Fixtures{}, fmt.Errorf("unsupported fixtures format %q, should be .json, .yaml or .yml", ext)
+	}
+	if err != nil {
+		return Fixtures{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// passports are matched however they were spaced in the file
+	people := make(map[string]models.People, len(fixtures.People))
+	for passport, p := range fixtures.People {
+		people[passportKey(passport)] = p
+	}
+	fixtures.People = people
+	for i, passport := range fixtures.Blacklist {
+		fixtures.Blacklist[i] = passportKey(passport)
+	}
+
+	return fixtures, nil
+}
+
+// passportKey normalizes a passport to "<serie> <number>"
+func passportKey(passport string) string {
+	return strings.Join(strings.Fields(passport), " ")
+}
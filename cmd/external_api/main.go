@@ -1,3 +1,7 @@
+// External API is a stand-in for the API that knows people by their passports.
+// It serves people from fixtures, makes up deterministic people for other passports,
+// answers 404 for blacklisted passports, and injects latency and errors configured
+// at runtime through /admin/faults.
 package main
 
 import (
@@ -5,10 +9,10 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
-	"github.com/kuromii5/time-tracker/internal/models"
 )
 
 func main() {
@@ -16,9 +20,60 @@ func main() {
 		log.Fatalf(".env loading error: %v", err)
 	}
 
+	var fixtures Fixtures
+	if path := os.Getenv("EXTERNAL_API_FIXTURES"); path != "" {
+		var err error
+		if fixtures, err = LoadFixtures(path); err != nil {
+			log.Fatalf("Failed to load fixtures: %v", err)
+		}
+		fmt.Printf("loaded %d people and %d blacklisted passports from %s\n", len(fixtures.People), len(fixtures.Blacklist), path)
+	}
+
+	faults, err := NewFaults(fixtures.Faults)
+	if err != nil {
+		log.Fatalf("Invalid faults: %v", err)
+	}
+
 	r := gin.Default()
 
-	r.GET("/info", func(c *gin.Context) {
+	api := r.Group("/", faults.Middleware())
+	api.GET("/info", info(fixtures))
+
+	admin := r.Group("/admin")
+	admin.GET("/faults", func(c *gin.Context) {
+		c.JSON(http.StatusOK, faults.Get())
+	})
+	admin.PUT("/faults", func(c *gin.Context) {
+		var req map[string]Fault
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := faults.Set(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, faults.Get())
+	})
+	admin.DELETE("/faults", func(c *gin.Context) {
+		faults.Set(nil)
+		c.Status(http.StatusNoContent)
+	})
+
+	port := os.Getenv("EXTERNAL_API_PORT")
+
+	fmt.Printf("external server is running on port: %s\n", port)
+
+	r.Run(fmt.Sprintf(":%s", port))
+}
+
+func info(fixtures Fixtures) gin.HandlerFunc {
+	blacklist := make(map[string]bool, len(fixtures.Blacklist))
+	for _, passport := range fixtures.Blacklist {
+		blacklist[passport] = true
+	}
+
+	return func(c *gin.Context) {
 		passportSerie := c.Query("passportSerie")
 		passportNumber := c.Query("passportNumber")
 
@@ -26,19 +81,26 @@ func main() {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing parameters"})
 			return
 		}
+		if !digits(passportSerie, 4) || !digits(passportNumber, 6) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Passport serie should be 4 digits and number 6 digits"})
+			return
+		}
 
-		// response example
-		c.JSON(http.StatusOK, models.People{
-			Name:       "Иван",
-			Surname:    "Иванов",
-			Patronymic: "Иванович",
-			Address:    "г. Москва, ул. Ленина, д. 5, кв. 1",
-		})
-	})
+		passport := passportSerie + " " + passportNumber
+		if blacklist[passport] {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Person not found"})
+			return
+		}
 
-	port := os.Getenv("EXTERNAL_API_PORT")
+		person, ok := fixtures.People[passport]
+		if !ok {
+			person = fakePerson(passport)
+		}
 
-	fmt.Printf("external server is running on port: %s\n", port)
+		c.JSON(http.StatusOK, person)
+	}
+}
 
-	r.Run(fmt.Sprintf(":%s", port))
+func digits(s string, n int) bool {
+	return len(s) == n && strings.Trim(s, "0123456789") == ""
 }
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kuromii5/time-tracker/internal/app"
+	"github.com/kuromii5/time-tracker/internal/app/userimport"
+	"github.com/kuromii5/time-tracker/internal/app/worklogimport"
+	"github.com/kuromii5/time-tracker/internal/audit"
+	"github.com/kuromii5/time-tracker/internal/config"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+const usage = `Import data in bulk.
+
+Usage:
+  importer users [-format csv|ndjson] [-json] FILE
+  importer worklogs [-format csv|json] [-rules FILE] [-tz ZONE] [-day-first] [-dry-run] [-json] FILE
+
+users creates users from a CSV file with a header, e.g. "passportNumber,name,surname,patronymic,address",
+or from NDJSON with an object like the request of POST /users per line. Only passportNumber is required,
+missing names and addresses are looked up in the people info API. FILE is - for the standard input.
+The format is taken from the file extension unless -format is given.
+
+worklogs creates finished worklogs from a detailed report exported from Toggl or Clockify as CSV or JSON.
+People are mapped to users and projects to projects by the JSON -rules file, e.g.
+{"people": {"ivan@example.com": 12}, "match_names": true, "projects": {"Site": 3}, "ignore_unknown_projects": false}
+Without it every person is matched by name. CSV exports have no offsets, their times are in -tz.
+-dry-run reports mapping problems and overlaps without creating anything.
+
+Rows that were not created are listed, or every row with -json. The exit status is 1 if any row failed.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.MustLoad()
+	logger := l.New(cfg.Env)
+	keyring, err := cfg.PassportKeyring()
+	if err != nil {
+		log.Fatalf("Failed to configure passport encryption: %v", err)
+	}
+	db, err := repo.New(cfg.DbUrl, logger, keyring)
+	if err != nil {
+		log.Fatalf("Failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	// every batch is committed on its own, interrupting keeps what was imported so far
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	ctx = audit.WithActor(ctx, "cli:importer")
+
+	var report models.ImportReport
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "users":
+		report, err = users(ctx, logger, cfg, db, args)
+	case "worklogs":
+		report, err = worklogs(ctx, logger, db, args)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("%s: %v", os.Args[1], err)
+	}
+
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func users(ctx context.Context, logger *slog.Logger, cfg *config.Config, db *repo.DB, args []string) (models.ImportReport, error) {
+	fs := flag.NewFlagSet("users", flag.ExitOnError)
+	format := fs.String("format", "", "File format, csv or ndjson")
+	asJSON := fs.Bool("json", false, "Print the outcome of every row as JSON")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return models.ImportReport{}, fmt.Errorf("a FILE is required")
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = userimport.FormatOf(path)
+	}
+
+	file, err := open(path)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+	defer file.Close()
+
+	peopleInfo, err := app.NewPeopleInfo(logger, cfg, db)
+	if err != nil {
+		return models.ImportReport{}, fmt.Errorf("failed to configure people info api: %w", err)
+	}
+
+	importer := userimport.New(logger, peopleInfo, db, cfg.UserImport())
+	report, err := importer.Import(ctx, file, *format)
+	if err != nil {
+		// an interrupted import reports what it saved before it stopped
+		if len(report.Rows) > 0 {
+			printReport(report, *asJSON)
+		}
+		return models.ImportReport{}, err
+	}
+
+	return report, printReport(report, *asJSON)
+}
+
+func worklogs(ctx context.Context, logger *slog.Logger, db *repo.DB, args []string) (models.ImportReport, error) {
+	fs := flag.NewFlagSet("worklogs", flag.ExitOnError)
+	format := fs.String("format", "", "File format, csv or json")
+	rulesPath := fs.String("rules", "", "JSON file of the rules mapping people to users and projects to projects")
+	tz := fs.String("tz", "UTC", "Time zone of the times of CSV exports")
+	dayFirst := fs.Bool("day-first", false, "Read slashed dates of CSV exports day first, like 31/01/2024")
+	dryRun := fs.Bool("dry-run", false, "Report what would be imported without creating anything")
+	asJSON := fs.Bool("json", false, "Print the outcome of every row as JSON")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return models.ImportReport{}, fmt.Errorf("a FILE is required")
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = worklogimport.FormatOf(path)
+	}
+
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return models.ImportReport{}, fmt.Errorf("invalid time zone: %w", err)
+	}
+
+	rules := worklogimport.Rules{MatchNames: true}
+	if *rulesPath != "" {
+		if rules, err = loadRules(*rulesPath); err != nil {
+			return models.ImportReport{}, fmt.Errorf("invalid rules: %w", err)
+		}
+	}
+
+	file, err := open(path)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+	defer file.Close()
+
+	importer := worklogimport.New(logger, db)
+	report, err := importer.Import(ctx, file, worklogimport.Options{
+		Format:   *format,
+		Location: loc,
+		DayFirst: *dayFirst,
+		DryRun:   *dryRun,
+		Rules:    rules,
+	})
+	if err != nil {
+		// an interrupted import reports what it created before it stopped
+		if len(report.Rows) > 0 {
+			printReport(report, *asJSON)
+		}
+		return models.ImportReport{}, err
+	}
+
+	return report, printReport(report, *asJSON)
+}
+
+// loadRules reads the rules of a worklogs import, unknown fields are refused to catch typos
+func loadRules(path string) (worklogimport.Rules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return worklogimport.Rules{}, err
+	}
+	defer f.Close()
+
+	var rules worklogimport.Rules
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rules); err != nil {
+		return worklogimport.Rules{}, err
+	}
+
+	return rules, nil
+}
+
+// open opens the file to import, - is the standard input
+func open(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// printReport prints the counts and the rows that were not created, or the whole report as JSON
+func printReport(report models.ImportReport, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	created := "created"
+	if report.DryRun {
+		created = "to be created (dry run)"
+	}
+	fmt.Printf("%d %s, %d duplicates, %d failed\n", report.Created, created, report.Duplicates, report.Failed)
+	if report.Duplicates+report.Failed == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "LINE\tSTATUS\tERROR")
+	for _, row := range report.Rows {
+		if row.Status != models.ImportCreated {
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", row.Line, row.Status, row.Error)
+		}
+	}
+
+	return tw.Flush()
+}
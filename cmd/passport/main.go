@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/kuromii5/time-tracker/internal/config"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	"github.com/kuromii5/time-tracker/pkg/fieldcrypt"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+const usage = `Manage passport encryption.
+
+Usage:
+  passport genkey
+  passport reencrypt [-batch N]
+  passport decrypt [-batch N]
+
+To rotate keys, add a new key with genkey to PASSPORT_KEYS, make it PASSPORT_PRIMARY_KEY,
+restart the tracker and run reencrypt. The old key can be removed once it is done.
+reencrypt also encrypts passports stored before encryption was introduced.
+decrypt writes passports back in plain text before migrating below the encryption migration.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cmd, args := os.Args[1], os.Args[2:]
+	if cmd == "genkey" {
+		if err := genkey(); err != nil {
+			log.Fatalf("%s: %v", cmd, err)
+		}
+		return
+	}
+
+	cfg := config.MustLoad()
+	keyring, err := cfg.PassportKeyring()
+	if err != nil {
+		log.Fatalf("Failed to configure passport encryption: %v", err)
+	}
+	db, err := repo.New(cfg.DbUrl, l.New(cfg.Env), keyring)
+	if err != nil {
+		log.Fatalf("Failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	// every batch is committed on its own, so interrupting is safe
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	batch := fs.Int("batch", 500, "Rows per transaction")
+	fs.Parse(args)
+
+	var count int
+	switch cmd {
+	case "reencrypt":
+		count, err = db.ReencryptPassports(ctx, *batch)
+	case "decrypt":
+		count, err = db.DecryptPassports(ctx, *batch)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("%s: %v (%d rows done)", cmd, err, count)
+	}
+
+	fmt.Printf("%s: %d rows done\n", cmd, count)
+}
+
+// genkey prints a random key for PASSPORT_KEYS or PASSPORT_INDEX_KEY
+func genkey() error {
+	key := make([]byte, fieldcrypt.KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+
+	fmt.Println(base64.StdEncoding.EncodeToString(key))
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log/slog"
+	_ "time/tzdata" // user time zones must resolve even without system zoneinfo
 
 	"github.com/kuromii5/time-tracker/internal/app"
 	"github.com/kuromii5/time-tracker/internal/config"
@@ -14,19 +15,21 @@ import (
 
 // @host localhost:8080
 // @BasePath /
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT or API key as "Bearer <token>"
 func main() {
 	cfg := config.MustLoad()
 	logger := l.New(cfg.Env)
 
 	// Create and configure the app
-	application := app.New(
-		logger,
-		cfg.DbUrl,
-		cfg.Port,
-		cfg.RequestTimeout,
-		cfg.IdleTimeout,
-		cfg.ExternalAPIPort,
-	)
+	application := app.New(logger, cfg)
 
 	logger.Info("starting server", slog.Int("port", cfg.Port))
 
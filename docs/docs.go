@@ -15,9 +15,17 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/users": {
+        "/admin/api-keys": {
             "get": {
-                "description": "Retrieve a list of users with optional filtering and pagination",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve all issued API keys, including revoked ones. Keys themselves are never returned.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,80 +33,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
-                ],
-                "summary": "Get a list of users",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Name",
-                        "name": "name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Surname",
-                        "name": "surname",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Patronymic",
-                        "name": "patronymic",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Address",
-                        "name": "address",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Passport Serie",
-                        "name": "serie",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Passport Number",
-                        "name": "number",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Created After (timestamp)",
-                        "name": "created_after",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Created Before (timestamp)",
-                        "name": "created_before",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Offset",
-                        "name": "offset",
-                        "in": "query"
-                    }
+                    "api-keys"
                 ],
+                "summary": "Get a list of API keys",
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved users",
+                        "description": "Successfully retrieved keys",
                         "schema": {
-                            "$ref": "#/definitions/user.UsersResponse"
+                            "$ref": "#/definitions/apikey.APIKeysResponse"
                         }
                     },
                     "500": {
-                        "description": "Failed to get users",
+                        "description": "Failed to get keys",
                         "schema": {
                             "$ref": "#/definitions/httperr.ErrResponse"
                         }
@@ -106,7 +52,15 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create a new user with provided passport number and fetch additional data from an external API",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issue a new API key. The key is shown only once, only its hash is stored.\nKeys bound to a user act as that user, admin keys can manage keys.",
                 "consumes": [
                     "application/json"
                 ],
@@ -114,33 +68,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "api-keys"
                 ],
-                "summary": "Create a new user",
+                "summary": "Issue an API key",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 8081,
-                        "description": "External API Port",
-                        "name": "extAPIPort",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "description": "Create User Request",
+                        "description": "Create API Key Request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.CreateUserRequest"
+                            "$ref": "#/definitions/apikey.CreateAPIKeyRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Successfully created user",
+                        "description": "Issued key",
                         "schema": {
-                            "$ref": "#/definitions/user.CreateUserResponse"
+                            "$ref": "#/definitions/apikey.CreateAPIKeyResponse"
                         }
                     },
                     "400": {
@@ -149,8 +95,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/httperr.ErrResponse"
                         }
                     },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Failed to issue key",
                         "schema": {
                             "$ref": "#/definitions/httperr.ErrResponse"
                         }
@@ -158,9 +110,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
+        "/admin/api-keys/{id}": {
             "delete": {
-                "description": "Delete a user by ID",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke an API key by ID. It stops working immediately and is kept in the list.",
                 "consumes": [
                     "application/json"
                 ],
@@ -168,13 +128,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "api-keys"
                 ],
-                "summary": "Delete a user",
+                "summary": "Revoke an API key",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "API key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -185,21 +145,37 @@ const docTemplate = `{
                         "description": "No Content"
                     },
                     "400": {
-                        "description": "Invalid user ID",
+                        "description": "Invalid key ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Active key not found",
                         "schema": {
                             "$ref": "#/definitions/httperr.ErrResponse"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete user",
+                        "description": "Failed to revoke key",
                         "schema": {
                             "$ref": "#/definitions/httperr.ErrResponse"
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Update a user's details using the provided information",
+            }
+        },
+        "/admin/audit-events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve recorded changes, newest first. Actors are \"api_key:\u003cid\u003e\" or \"jwt:\u003csubject\u003e\" for requests and \"system:...\" for background jobs.",
                 "consumes": [
                     "application/json"
                 ],
@@ -207,39 +183,75 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "audit"
                 ],
-                "summary": "Update an existing user",
+                "summary": "Get audit events",
                 "parameters": [
+                    {
+                        "enum": [
+                            "user",
+                            "worklog",
+                            "client",
+                            "project",
+                            "api_key"
+                        ],
+                        "type": "string",
+                        "description": "Entity",
+                        "name": "entity",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Entity ID",
+                        "name": "entity_id",
+                        "in": "query"
                     },
                     {
-                        "description": "Update User Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user.UpdateUserRequest"
-                        }
+                        "type": "string",
+                        "description": "Actor",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Occurred at or after (RFC3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Occurred before (RFC3339 or YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Successfully updated user"
+                    "200": {
+                        "description": "Successfully retrieved audit events",
+                        "schema": {
+                            "$ref": "#/definitions/auditlog.AuditEventsResponse"
+                        }
                     },
                     "400": {
-                        "description": "Invalid request payload",
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/httperr.ErrResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Failed to get audit events",
                         "schema": {
                             "$ref": "#/definitions/httperr.ErrResponse"
                         }
@@ -247,9 +259,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{userID}/worklogs": {
+        "/admin/enrichment-jobs": {
             "get": {
-                "description": "Get worklogs for a user within a specified date range. Time format should be YYYY-MM-DDTHH:MM:SSZ (ISO 8601).",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the jobs that fetch people info for new users, newest first. Dead jobs ran out of attempts or failed for good and wait to be retried.",
                 "consumes": [
                     "application/json"
                 ],
@@ -257,45 +277,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "worklogs"
+                    "enrichment"
                 ],
-                "summary": "Get worklogs for a user",
+                "summary": "Get enrichment jobs",
                 "parameters": [
+                    {
+                        "enum": [
+                            "queued",
+                            "running",
+                            "done",
+                            "dead"
+                        ],
+                        "type": "string",
+                        "description": "Status",
+                        "name": "status",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "userID",
-                        "in": "path",
-                        "required": true
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
-                        "description": "Worklogs Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/worklog.WorklogsRequest"
-                        }
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of worklogs",
+                        "description": "Successfully retrieved jobs",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/worklog.WorklogResponse"
-                            }
+                            "$ref": "#/definitions/enrichment.JobsResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload or user ID",
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/httperr.ErrResponse"
                         }
                     },
                     "500": {
-                        "description": "Failed to get worklogs",
+                        "description": "Failed to get jobs",
                         "schema": {
                             "$ref": "#/definitions/httperr.ErrResponse"
                         }
@@ -303,9 +328,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/worklogs/finish/{id}": {
-            "patch": {
-                "description": "Finish a worklog with the specified ID",
+        "/admin/enrichment-jobs/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queue a dead-lettered job again with fresh attempts. Its user is put back to pending_enrichment.",
                 "consumes": [
                     "application/json"
                 ],
@@ -313,30 +346,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "worklogs"
+                    "enrichment"
                 ],
-                "summary": "Finish a worklog",
+                "summary": "Retry a dead enrichment job",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Worklog ID",
+                        "description": "Job ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "Requeued job",
+                        "schema": {
+                            "$ref": "#/definitions/models.EnrichmentJob"
+                        }
                     },
                     "400": {
-                        "description": "Invalid worklog ID",
+                        "description": "Invalid job ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Dead job not found",
                         "schema": {
                             "$ref": "#/definitions/httperr.ErrResponse"
                         }
                     },
                     "500": {
-                        "description": "Failed to finish worklog",
+                        "description": "Failed to retry job",
                         "schema": {
                             "$ref": "#/definitions/httperr.ErrResponse"
                         }
@@ -344,9 +386,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/worklogs/start": {
-            "post": {
-                "description": "Start a new worklog for a specified user with a given task",
+        "/admin/people-info-cache": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove the cached answer for a passport, or all cached answers if no passport is given. The next lookup asks the people info API again.",
                 "consumes": [
                     "application/json"
                 ],
@@ -354,56 +404,2506 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "worklogs"
+                    "people-info"
                 ],
-                "summary": "Start a worklog",
+                "summary": "Invalidate cached people info",
                 "parameters": [
                     {
-                        "description": "Start Worklog Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/worklog.StartWorklogRequest"
-                        }
+                        "type": "string",
+                        "description": "Passport serie and number, e.g. 1234 567890",
+                        "name": "passportNumber",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully started worklog",
+                    "200": {
+                        "description": "Number of removed entries",
                         "schema": {
-                            "$ref": "#/definitions/worklog.StartWorklogResponse"
+                            "$ref": "#/definitions/peoplecache.InvalidateResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload",
+                        "description": "Invalid passport",
                         "schema": {
                             "$ref": "#/definitions/httperr.ErrResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Failed to invalidate cache",
                         "schema": {
                             "$ref": "#/definitions/httperr.ErrResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "httperr.ErrResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "description": "application-level error message, for debugging",
-                    "type": "string"
-                },
-                "status": {
-                    "description": "user-level status message",
-                    "type": "string"
-                }
-            }
+        },
+        "/admin/users/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create users from a CSV file with a header, e.g. \"passportNumber,name,surname,patronymic,address\", or from NDJSON with an object like the request of POST /users per line.\nOnly passportNumber is required. Missing names and addresses are looked up in the people info API, users are created enriched right away.\nEvery row is reported: created with the user ID, duplicate if the passport is taken or repeated in the file, or failed with the reason.\nUsers are saved in batches. If the import times out, the users saved so far are kept and reported with status 503.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Import users",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "File format, by default taken from the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Outcome of every row",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Unsupported format, invalid or too large file",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to import users",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "The import timed out, outcome of every row with the rows not saved failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/calendar/{token}.ics": {
+            "get": {
+                "description": "Calendar feed of a user's worklogs for calendar apps, protected by the token issued for the user.\nAccepts the same range parameters as the .ics export.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Subscribable calendar feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Calendar token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "iCalendar file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid range",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown token",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get worklogs",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/clients": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve all clients sorted by name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clients"
+                ],
+                "summary": "Get a list of clients",
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved clients",
+                        "schema": {
+                            "$ref": "#/definitions/client.ClientsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get clients",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new client, whose projects worklogs can be grouped by",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clients"
+                ],
+                "summary": "Create a new client",
+                "parameters": [
+                    {
+                        "description": "Create Client Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/client.CreateClientRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created client",
+                        "schema": {
+                            "$ref": "#/definitions/client.CreateClientResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Client already exists",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/clients/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a client by ID. Its projects are kept without a client",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clients"
+                ],
+                "summary": "Delete a client",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid client ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Client not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete client",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rename a client",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clients"
+                ],
+                "summary": "Update an existing client",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Client Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/client.UpdateClientRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successfully updated client"
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Client not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Client already exists",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve all projects sorted by name, optionally only the projects of one client",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Get a list of projects",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "client_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved projects",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid client ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get projects",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new project, optionally owned by a client",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Create a new project",
+                "parameters": [
+                    {
+                        "description": "Create Project Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.CreateProjectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created project",
+                        "schema": {
+                            "$ref": "#/definitions/project.CreateProjectResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Client not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Project already exists",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a project by ID. Its worklogs are kept without a project",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Delete a project",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid project ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Project not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete project",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rename a project or move it to another client. Empty fields are left unchanged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Update an existing project",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Project Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.UpdateProjectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successfully updated project"
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Project or client not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Project already exists",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/time": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sum tracked time within a date range, grouped by any of user, task, day, week (ISO) and month.\nPaused time is not counted, running worklogs are counted up to now.\nTime format should be YYYY-MM-DDTHH:MM:SSZ (ISO 8601), or YYYY-MM-DD for midnight in the report time zone (UTC by default).\nThe range defaults to everything up to now.\nDays, weeks and months are counted in the tz time zone, or in every user's own time zone if tz is not set.\nTime crossing midnight is split between the days, weeks and months it falls into, a worklog is counted in each of them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get a time report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated dimensions: user, task, day, week, month",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start (inclusive)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end (exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID, required unless the caller is an admin",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "client_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task",
+                        "name": "task",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone, e.g. Europe/Moscow",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Time report",
+                        "schema": {
+                            "$ref": "#/definitions/report.TimeReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid report parameters",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to build report",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a list of users with optional filtering and pagination. Managers only see themselves and their team. Soft-deleted users are hidden unless include_deleted is set.\nPages are either skipped by offset, or followed by passing next_cursor or prev_cursor of a page as cursor, which does not skip or repeat users that are created or deleted meanwhile.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a list of users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search in name, surname, patronymic and address, results are ranked by similarity",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Surname",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Patronymic",
+                        "name": "patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Address",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Passport Serie",
+                        "name": "serie",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Passport Number",
+                        "name": "number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created After (timestamp)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created Before (timestamp)",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted users",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset, ignored with a cursor",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated keys to sort by, descending if prefixed with -, e.g. -created_at,surname. Keys: id, created_at, updated_at, name, surname, patronymic, address",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next or previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Count all users matching the filter",
+                        "name": "with_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved users",
+                        "schema": {
+                            "$ref": "#/definitions/user.UsersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid creation range, cursor or sort",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get users",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new user with provided passport number. The user is created in the pending_enrichment status and the name and address are fetched from an external API in the background. Poll the enrichment status at the Location, or pass a callback_url on a public host to be notified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "description": "Create User Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created user, pending enrichment",
+                        "schema": {
+                            "$ref": "#/definitions/user.CreateUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "User with such passport already exists",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Soft-delete a user by ID. The user and their worklogs are kept until they are restored or the retention period runs out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete user",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a user's details using the provided information.\nUsers and their managers may change settings, only admins may edit passport data, roles and teams.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update an existing user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update User Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successfully updated user"
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "User with such passport already exists",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/enrichment": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get whether the user's name and address were fetched yet: pending_enrichment, enriched or enrichment_failed, with the latest enrichment job.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get enrichment status of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Enrichment status",
+                        "schema": {
+                            "$ref": "#/definitions/models.Enrichment"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get enrichment status",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/erase": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Anonymize a user by ID: the passport, name and address are cleared, also from the audit log, and the user's API keys are revoked. Worklogs are kept for aggregate reports. Erasure can't be undone and is recorded in the audit log.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Erase personal data of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "User was already erased",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to erase user",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/personal-data": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Download the user's profile, worklogs, API keys and profile history as a JSON archive. Every export is recorded in the audit log.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Export personal data of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Personal data archive",
+                        "schema": {
+                            "$ref": "#/definitions/user.PersonalDataResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to export personal data",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restore a soft-deleted user by ID, before the retention period runs out",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Restore a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Deleted user not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to restore user",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/calendar-token": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issue a new secret token for the user's subscribable calendar feed. The previous token stops working.\nThe token is shown only once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Issue a calendar feed token",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Token and feed URL",
+                        "schema": {
+                            "$ref": "#/definitions/calendar.CreateTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to issue token",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/worklogs": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get worklogs for a user overlapping the from-to range, running worklogs last until now. Times are YYYY-MM-DDTHH:MM:SSZ (ISO 8601) or YYYY-MM-DD dates, meaning midnight in the time zone the worklogs are shown in.\nDuration is the active time of a worklog, paused is the time it spent on pause. Unfinished worklogs have no end time, but the time elapsed since they started.\nTimes are shown in the tz time zone, or in the user's own time zone if tz is not set.\nPages are either skipped by offset, or followed by passing next_cursor or prev_cursor of a page as cursor. The duration of running worklogs grows, so cursors sorted by duration may skip or repeat them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "worklogs"
+                ],
+                "summary": "Get worklogs for a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "running",
+                            "paused",
+                            "finished"
+                        ],
+                        "type": "string",
+                        "description": "Worklog status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search in the task, case-insensitively",
+                        "name": "task",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Least active time, e.g. 1h30m",
+                        "name": "min_duration",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "client_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only worklogs stopped automatically, for review",
+                        "name": "auto_stopped",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone, e.g. Europe/Moscow",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset, ignored with a cursor",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated keys to sort by, descending if prefixed with -, e.g. -start_time,task, by default -start_time. Keys: id, project_id, task, start_time, end_time, duration",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next or previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Count all worklogs matching the filter",
+                        "name": "with_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of worklogs",
+                        "schema": {
+                            "$ref": "#/definitions/worklog.WorklogsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID, filter, cursor or sort",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get worklogs",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/worklogs.ics": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get worklogs of a user as an .ics file. Every worklog is an event, running worklogs are tentative and end now.\nTime format should be YYYY-MM-DDTHH:MM:SSZ (ISO 8601). The range defaults to the last 90 days.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Get worklogs as iCalendar",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "iCalendar file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or range",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get worklogs",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/worklogs": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Record a finished worklog with explicit start and end time, e.g. for a forgotten timer.\nThe worklog must not overlap with other worklogs of the same user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "worklogs"
+                ],
+                "summary": "Create a worklog manually",
+                "parameters": [
+                    {
+                        "description": "Create Worklog Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/worklog.CreateWorklogRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created worklog",
+                        "schema": {
+                            "$ref": "#/definitions/worklog.CreateWorklogResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or project not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Worklog overlaps with other worklogs, they are listed in details",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/worklogs/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Export worklogs started within a date range as CSV or XLSX. The file is streamed row by row.\nThe format is taken from the format parameter, then from the Accept header, and defaults to CSV.\nTimes are written in the tz time zone, or in each worklog owner's time zone if tz is not set.\nAvailable columns: id, user_id, name, surname, patronymic, client, project, task, status, start_time, end_time, duration_hours, paused_hours.\nIn CSV, text starting with =, +, -, @, a tab or a carriage return is prefixed with a quote, so spreadsheets don't run it as a formula.",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "worklogs"
+                ],
+                "summary": "Export worklogs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Range start (inclusive), YYYY-MM-DDTHH:MM:SSZ or YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end (exclusive), YYYY-MM-DDTHH:MM:SSZ or YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID, required unless the caller is an admin",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "client_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated columns, all by default",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv or xlsx",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone, e.g. Europe/Moscow",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Worklogs export",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid export parameters",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to export worklogs",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/worklogs/finish/{id}": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Finish a worklog with the specified ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "worklogs"
+                ],
+                "summary": "Finish a worklog",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Worklog ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid worklog ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Worklog not found or its owner is deleted",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Worklog is already finished",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to finish worklog",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/worklogs/start": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Start a new worklog for a specified user with a given task.\nA user can have only one open worklog. Depending on the user's worklog policy\nthe open one is either finished automatically or the request is rejected",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "worklogs"
+                ],
+                "summary": "Start a worklog",
+                "parameters": [
+                    {
+                        "description": "Start Worklog Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/worklog.StartWorklogRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully started worklog",
+                        "schema": {
+                            "$ref": "#/definitions/worklog.StartWorklogResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or project not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "User already has a running worklog",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/worklogs/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a worklog with all its segments",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "worklogs"
+                ],
+                "summary": "Delete a worklog",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Worklog ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid worklog ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Worklog not found or its owner is deleted",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete worklog",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Fix the task, project or time range of a worklog. Omitted fields are left unchanged, clear_project removes the project.\nSetting the end time of a running worklog finishes it. Pauses inside the new range are kept.\nThe worklog must not overlap with other worklogs of the same user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "worklogs"
+                ],
+                "summary": "Update a worklog",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Worklog ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Worklog Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/worklog.UpdateWorklogRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Worklog or project not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Worklog overlaps with other worklogs, they are listed in details",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/worklogs/{id}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pause a running worklog. Paused time is not counted in the worklog duration",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "worklogs"
+                ],
+                "summary": "Pause a worklog",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Worklog ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid worklog ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Worklog not found or its owner is deleted",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Worklog is already paused or finished",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to pause worklog",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/worklogs/{id}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Resume a paused worklog by opening a new active segment",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "worklogs"
+                ],
+                "summary": "Resume a worklog",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Worklog ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid worklog ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Worklog not found or its owner is deleted",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Worklog is not paused or already finished",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to resume worklog",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.ErrResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apikey.APIKeysResponse": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.APIKey"
+                    }
+                }
+            }
+        },
+        "apikey.CreateAPIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "admin": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "ci"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apikey.CreateAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "key_id": {
+                    "type": "integer"
+                },
+                "prefix": {
+                    "type": "string"
+                }
+            }
+        },
+        "auditlog.AuditEventsResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditEvent"
+                    }
+                }
+            }
+        },
+        "calendar.CreateTokenResponse": {
+            "type": "object",
+            "properties": {
+                "feed_url": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "client.ClientsResponse": {
+            "type": "object",
+            "properties": {
+                "clients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Client"
+                    }
+                }
+            }
+        },
+        "client.CreateClientRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "client.CreateClientResponse": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "client.UpdateClientRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "enrichment.JobsResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EnrichmentJob"
+                    }
+                }
+            }
+        },
+        "httperr.ErrResponse": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "description": "structured error details, if any"
+                },
+                "error": {
+                    "description": "application-level error message, for debugging",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "user-level status message",
+                    "type": "string"
+                }
+            }
+        },
+        "models.APIKey": {
+            "type": "object",
+            "properties": {
+                "admin": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Prefix is the beginning of the key, to recognize it in lists",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "UserID is the user the key acts as, zero for service keys",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "entity": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Client": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Enrichment": {
+            "type": "object",
+            "properties": {
+                "job": {
+                    "$ref": "#/definitions/models.EnrichmentJob"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.EnrichmentJob": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "callback_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "run_at": {
+                    "description": "RunAt is when the job is run next, for queued jobs",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ImportReport": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "description": "DryRun is set when nothing was written, Created counts the rows that would be created then",
+                    "type": "boolean"
+                },
+                "duplicates": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportRow"
+                    }
+                }
+            }
+        },
+        "models.ImportRow": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID of what the row created",
+                    "type": "integer"
+                },
+                "line": {
+                    "description": "Line is where the row starts in the file, counting from 1",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
         },
         "models.Passport": {
             "type": "object",
@@ -433,23 +2933,208 @@ const docTemplate = `{
                 }
             }
         },
+        "models.Project": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "models.User": {
             "type": "object",
             "properties": {
+                "deleted_at": {
+                    "description": "DeletedAt is set for soft-deleted users until they are restored or purged",
+                    "type": "string"
+                },
+                "end_of_day": {
+                    "description": "EndOfDay is the time of day (HH:MM) after which the user's forgotten worklogs are stopped",
+                    "type": "string"
+                },
+                "enrichment_status": {
+                    "description": "EnrichmentStatus tells whether the people info was fetched yet, see EnrichmentPending",
+                    "type": "string"
+                },
+                "erased_at": {
+                    "description": "ErasedAt is set for users whose personal data was erased",
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "manager_id": {
+                    "description": "ManagerID is the manager whose team the user is in, zero if none",
+                    "type": "integer"
+                },
                 "passport": {
                     "$ref": "#/definitions/models.Passport"
                 },
                 "people": {
                     "$ref": "#/definitions/models.People"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "description": "Timezone is an IANA time zone name, e.g. \"Europe/Moscow\". Days in reports and\nthe end of day are counted in this zone.",
+                    "type": "string"
+                },
+                "worklog_policy": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Worklog": {
+            "type": "object",
+            "properties": {
+                "auto_stopped": {
+                    "description": "AutoStopped is set when a forgotten worklog was stopped automatically and is waiting for review",
+                    "type": "boolean"
+                },
+                "duration": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "paused": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "task": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "peoplecache.InvalidateResponse": {
+            "type": "object",
+            "properties": {
+                "invalidated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "project.CreateProjectRequest": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "project.CreateProjectResponse": {
+            "type": "object",
+            "properties": {
+                "project_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "project.ProjectsResponse": {
+            "type": "object",
+            "properties": {
+                "projects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Project"
+                    }
+                }
+            }
+        },
+        "project.UpdateProjectRequest": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "report.TimeReportResponse": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "group_by": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.TimeReportRow"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "report.TimeReportRow": {
+            "type": "object",
+            "properties": {
+                "day": {
+                    "type": "string"
+                },
+                "duration": {
+                    "type": "string"
+                },
+                "month": {
+                    "type": "string"
+                },
+                "seconds": {
+                    "type": "integer"
+                },
+                "task": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "week": {
+                    "type": "string"
+                },
+                "worklogs": {
+                    "type": "integer"
                 }
             }
         },
         "user.CreateUserRequest": {
             "type": "object",
             "properties": {
+                "callback_url": {
+                    "description": "CallbackURL is notified with a POST of models.EnrichmentNotification once the user is enriched or enrichment failed",
+                    "type": "string"
+                },
                 "passportNumber": {
                     "type": "string"
                 }
@@ -458,14 +3143,66 @@ const docTemplate = `{
         "user.CreateUserResponse": {
             "type": "object",
             "properties": {
+                "enrichment_status": {
+                    "type": "string"
+                },
                 "user_id": {
                     "type": "integer"
                 }
             }
         },
+        "user.PersonalDataResponse": {
+            "type": "object",
+            "properties": {
+                "api_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.APIKey"
+                    }
+                },
+                "calendar_feed": {
+                    "description": "CalendarFeed tells whether a calendar feed token was issued, the token itself is not stored",
+                    "type": "boolean"
+                },
+                "exported_at": {
+                    "type": "string"
+                },
+                "history": {
+                    "description": "History is the audit log of changes of the user's profile",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditEvent"
+                    }
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "worklogs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Worklog"
+                    }
+                }
+            }
+        },
         "user.UpdateUserRequest": {
             "type": "object",
             "properties": {
+                "clear_end_of_day": {
+                    "description": "ClearEndOfDay unsets the end of day, so forgotten worklogs are only stopped after the maximum duration",
+                    "type": "boolean"
+                },
+                "clear_manager": {
+                    "description": "ClearManager takes the user out of their manager's team",
+                    "type": "boolean"
+                },
+                "end_of_day": {
+                    "type": "string",
+                    "example": "18:00"
+                },
+                "manager_id": {
+                    "type": "integer"
+                },
                 "passport": {
                     "type": "object",
                     "properties": {
@@ -493,12 +3230,41 @@ const docTemplate = `{
                             "type": "string"
                         }
                     }
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "manager",
+                        "employee"
+                    ]
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Moscow"
+                },
+                "worklog_policy": {
+                    "type": "string",
+                    "enum": [
+                        "reject",
+                        "switch"
+                    ]
                 }
             }
         },
         "user.UsersResponse": {
             "type": "object",
             "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Total is the number of rows of the listing, if it was asked for",
+                    "type": "integer"
+                },
                 "users": {
                     "type": "array",
                     "items": {
@@ -507,9 +3273,40 @@ const docTemplate = `{
                 }
             }
         },
+        "worklog.CreateWorklogRequest": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "task": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "worklog.CreateWorklogResponse": {
+            "type": "object",
+            "properties": {
+                "worklog_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "worklog.StartWorklogRequest": {
             "type": "object",
             "properties": {
+                "project_id": {
+                    "type": "integer"
+                },
                 "task": {
                     "type": "string"
                 },
@@ -521,26 +3318,69 @@ const docTemplate = `{
         "worklog.StartWorklogResponse": {
             "type": "object",
             "properties": {
+                "stopped_worklog_id": {
+                    "description": "StoppedWorklogID is the worklog that was finished to start this one, if any",
+                    "type": "integer"
+                },
                 "worklog_id": {
                     "type": "integer"
                 }
             }
         },
+        "worklog.UpdateWorklogRequest": {
+            "type": "object",
+            "properties": {
+                "clear_project": {
+                    "description": "ClearProject takes the worklog out of its project",
+                    "type": "boolean"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "task": {
+                    "type": "string"
+                }
+            }
+        },
         "worklog.WorklogResponse": {
             "type": "object",
             "properties": {
+                "auto_stopped": {
+                    "description": "AutoStopped is set if the worklog was forgotten and stopped automatically",
+                    "type": "boolean"
+                },
                 "duration": {
                     "type": "string"
                 },
+                "elapsed": {
+                    "description": "Elapsed is the time since an unfinished worklog started",
+                    "type": "string"
+                },
                 "end_time": {
+                    "description": "EndTime is only set for finished worklogs",
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "paused": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
                 "start_time": {
                     "type": "string"
                 },
+                "status": {
+                    "type": "string"
+                },
                 "task": {
                     "type": "string"
                 },
@@ -549,17 +3389,40 @@ const docTemplate = `{
                 }
             }
         },
-        "worklog.WorklogsRequest": {
+        "worklog.WorklogsResponse": {
             "type": "object",
             "properties": {
-                "end_date": {
+                "next_cursor": {
                     "type": "string"
                 },
-                "start_date": {
+                "prev_cursor": {
                     "type": "string"
+                },
+                "total": {
+                    "description": "Total is the number of rows of the listing, if it was asked for",
+                    "type": "integer"
+                },
+                "worklogs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/worklog.WorklogResponse"
+                    }
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "JWT or API key as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
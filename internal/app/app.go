@@ -2,46 +2,137 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/kuromii5/time-tracker/internal/app/enricher"
+	"github.com/kuromii5/time-tracker/internal/app/purger"
+	"github.com/kuromii5/time-tracker/internal/app/reaper"
 	"github.com/kuromii5/time-tracker/internal/app/server"
+	"github.com/kuromii5/time-tracker/internal/app/userimport"
+	"github.com/kuromii5/time-tracker/internal/auth"
+	"github.com/kuromii5/time-tracker/internal/config"
+	"github.com/kuromii5/time-tracker/internal/peopleinfo"
 	"github.com/kuromii5/time-tracker/internal/repo"
+	"github.com/kuromii5/time-tracker/pkg/jwt"
 	l "github.com/kuromii5/time-tracker/pkg/logger"
 )
 
+// passportBatchSize is how many plain passports are encrypted per transaction at startup
+const passportBatchSize = 500
+
 type App struct {
-	logger *slog.Logger
-	server *http.Server
-	db     *repo.DB
+	logger   *slog.Logger
+	server   *http.Server
+	reaper   *reaper.Reaper
+	purger   *purger.Purger
+	enricher *enricher.Enricher
+	db       *repo.DB
+
+	// stopJobs cancels background jobs, jobs is done when all of them have returned
+	stopJobs context.CancelFunc
+	jobs     sync.WaitGroup
 }
 
-func New(
-	logger *slog.Logger,
-	dbUrl string,
-	port int,
-	reqTimeout, idleTimeout time.Duration,
-	externalAPIPort int,
-) *App {
-	db, err := repo.New(dbUrl, logger)
+func New(logger *slog.Logger, cfg *config.Config) *App {
+	keyring, err := cfg.PassportKeyring()
+	if err != nil {
+		log.Fatalf("Failed to configure passport encryption: %v", err)
+	}
+
+	db, err := repo.New(cfg.DbUrl, logger, keyring)
 	if err != nil {
 		log.Fatalf("Failed to connect to db: %v", err)
 	}
 
-	server := server.New(logger, port, reqTimeout, idleTimeout, db, externalAPIPort)
+	// passports stored before encryption are missed by duplicate checks until they are indexed
+	encrypted, err := db.EncryptPlainPassports(context.Background(), passportBatchSize)
+	if err != nil {
+		log.Fatalf("Failed to encrypt plain passports: %v", err)
+	}
+	if encrypted > 0 {
+		logger.Info("encrypted plain passports", slog.Int("count", encrypted))
+	}
+
+	verifier, err := newJWTVerifier(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure jwt: %v", err)
+	}
+	authenticator := auth.New(logger, db, verifier)
+
+	peopleInfo, err := NewPeopleInfo(logger, cfg, db)
+	if err != nil {
+		log.Fatalf("Failed to configure people info api: %v", err)
+	}
+	importer := userimport.New(logger, peopleInfo, db, cfg.UserImport())
+
+	server := server.New(logger, cfg.Port, cfg.RequestTimeout, cfg.IdleTimeout, cfg.ImportTimeout, db, peopleInfo, importer, authenticator)
+	reaper := reaper.New(logger, db, cfg.ReaperInterval, cfg.ReaperMaxDuration)
+	purger := purger.New(logger, db, peopleInfo, cfg.PurgerInterval, cfg.PurgerRetention)
+	enricher := enricher.New(logger, db, peopleInfo, cfg.Enricher())
 
 	return &App{
-		logger: logger,
-		server: server,
-		db:     db,
+		logger:   logger,
+		server:   server,
+		reaper:   reaper,
+		purger:   purger,
+		enricher: enricher,
+		db:       db,
 	}
 }
 
+// NewPeopleInfo creates the people info API client behind the configured cache
+func NewPeopleInfo(logger *slog.Logger, cfg *config.Config, db *repo.DB) (*peopleinfo.Cache, error) {
+	client, err := peopleinfo.New(logger, cfg.PeopleInfo())
+	if err != nil {
+		return nil, err
+	}
+
+	var store peopleinfo.Store
+	switch cfg.PeopleCache {
+	case "memory":
+		store = peopleinfo.NewMemoryStore()
+	case "postgres":
+		store = db
+	case "off":
+	default:
+		return nil, fmt.Errorf("unknown PEOPLE_CACHE %q, should be memory, postgres or off", cfg.PeopleCache)
+	}
+
+	return peopleinfo.NewCache(logger, client, store, cfg.PeopleCacheTTL, cfg.PeopleCacheNegativeTTL), nil
+}
+
+// newJWTVerifier creates a verifier for the configured keys, or returns nil if JWTs are disabled
+func newJWTVerifier(cfg *config.Config) (*jwt.Verifier, error) {
+	jwtCfg := jwt.Config{
+		HMACSecret: []byte(cfg.AuthJWTSecret),
+		Issuer:     cfg.AuthJWTIssuer,
+		Audience:   cfg.AuthJWTAudience,
+		Leeway:     time.Minute,
+	}
+	if cfg.AuthJWTPublicKeyFile != "" {
+		data, err := os.ReadFile(cfg.AuthJWTPublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if jwtCfg.RSAPublicKey, err = jwt.ParseRSAPublicKeyPEM(data); err != nil {
+			return nil, err
+		}
+	}
+	if len(jwtCfg.HMACSecret) == 0 && jwtCfg.RSAPublicKey == nil {
+		return nil, nil
+	}
+
+	return jwt.NewVerifier(jwtCfg)
+}
+
 func (a *App) Run() error {
 	go func() {
 		if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -49,13 +140,30 @@ func (a *App) Run() error {
 		}
 	}()
 
+	// Start background jobs
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopJobs = cancel
+	a.jobs.Add(3)
+	go func() {
+		defer a.jobs.Done()
+		a.reaper.Run(ctx)
+	}()
+	go func() {
+		defer a.jobs.Done()
+		a.purger.Run(ctx)
+	}()
+	go func() {
+		defer a.jobs.Done()
+		a.enricher.Run(ctx)
+	}()
+
 	// Set up graceful shutdown
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 	<-done
 	a.logger.Info("shutting down server...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	if err := a.Shutdown(ctx); err != nil {
@@ -69,7 +177,23 @@ func (a *App) Run() error {
 }
 
 func (a *App) Shutdown(ctx context.Context) error {
-	// Close db first
+	// Stop accepting requests and wait for background jobs before closing db
+	err := a.server.Shutdown(ctx)
+
+	if a.stopJobs != nil {
+		a.stopJobs()
+	}
+	jobsDone := make(chan struct{})
+	go func() {
+		a.jobs.Wait()
+		close(jobsDone)
+	}()
+	select {
+	case <-jobsDone:
+	case <-ctx.Done():
+		a.logger.Warn("background jobs did not stop in time")
+	}
+
 	a.db.Close()
-	return a.server.Shutdown(ctx)
+	return err
 }
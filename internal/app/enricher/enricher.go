@@ -0,0 +1,219 @@
+package enricher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kuromii5/time-tracker/internal/audit"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/peopleinfo"
+	"github.com/kuromii5/time-tracker/internal/utils"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type JobQueue interface {
+	ClaimEnrichmentJob(ctx context.Context, lease time.Duration) (models.EnrichmentJob, bool, error)
+	CompleteEnrichmentJob(ctx context.Context, job models.EnrichmentJob, people models.People) error
+	RescheduleEnrichmentJob(ctx context.Context, job models.EnrichmentJob, jobErr string, runAt time.Time) error
+	DeadLetterEnrichmentJob(ctx context.Context, job models.EnrichmentJob, jobErr string) error
+}
+
+type PeopleInfoFetcher interface {
+	Info(ctx context.Context, passport models.Passport) (models.People, error)
+}
+
+type Config struct {
+	Workers int
+	// PollInterval is how long an idle worker waits before looking for due jobs again
+	PollInterval time.Duration
+	// Lease is how long a job may run before it is given to another worker
+	Lease time.Duration
+	// MaxAttempts failed attempts dead-letter a job. Passports the API doesn't know are dead-lettered at once.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, it doubles with every retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// CallbackTimeout limits notifying the callback URL of a job
+	CallbackTimeout time.Duration
+}
+
+// Enricher fetches people info for users created in the pending enrichment status.
+// Jobs are claimed from the queue with a lease and skipping jobs claimed by others,
+// so several replicas can run their enrichers at the same time.
+type Enricher struct {
+	log     *slog.Logger
+	queue   JobQueue
+	fetcher PeopleInfoFetcher
+	cfg     Config
+	http    *http.Client
+}
+
+func New(log *slog.Logger, queue JobQueue, fetcher PeopleInfoFetcher, cfg Config) *Enricher {
+	// callbacks only reach public addresses, whatever their hosts resolve to, and go direct
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = utils.PublicDialer().DialContext
+
+	return &Enricher{
+		log:     log.With(slog.String("job", "enricher")),
+		queue:   queue,
+		fetcher: fetcher,
+		cfg:     cfg,
+		http:    &http.Client{Timeout: cfg.CallbackTimeout, Transport: transport},
+	}
+}
+
+// Run runs the workers until ctx is cancelled
+func (e *Enricher) Run(ctx context.Context) {
+	e.log.Info("enricher started",
+		slog.Int("workers", e.cfg.Workers),
+		slog.Duration("poll_interval", e.cfg.PollInterval),
+		slog.Int("max_attempts", e.cfg.MaxAttempts),
+	)
+
+	// enrichments are attributed to the enricher in the audit log
+	ctx = audit.WithActor(ctx, audit.ActorEnricher)
+
+	var wg sync.WaitGroup
+	wg.Add(e.cfg.Workers)
+	for range e.cfg.Workers {
+		go func() {
+			defer wg.Done()
+			e.work(ctx)
+		}()
+	}
+	wg.Wait()
+
+	e.log.Info("enricher stopped")
+}
+
+func (e *Enricher) work(ctx context.Context) {
+	for {
+		// keep going while there are due jobs, wait when the queue is drained
+		if e.process(ctx) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.cfg.PollInterval):
+		}
+	}
+}
+
+// process runs one due job and reports whether there was one
+func (e *Enricher) process(ctx context.Context) bool {
+	job, ok, err := e.queue.ClaimEnrichmentJob(ctx, e.cfg.Lease)
+	if !ok {
+		if err != nil && ctx.Err() == nil {
+			e.log.Error("failed to claim enrichment job", l.Err(err))
+		}
+		return false
+	}
+
+	log := e.log.With(slog.Int64("job_id", job.ID), slog.Int("user_id", int(job.UserID)), slog.Int("attempt", job.Attempts))
+
+	// a job claimed with an error, e.g. an undecryptable passport, fails the attempt like a failed fetch
+	var people models.People
+	if err == nil {
+		people, err = e.fetch(ctx, job)
+	}
+	if ctx.Err() != nil {
+		// shutting down, the job is picked up again once its lease expires
+		return true
+	}
+
+	switch {
+	case err == nil:
+		err = e.queue.CompleteEnrichmentJob(ctx, job, people)
+		if err == nil {
+			log.Info("user enriched")
+			e.notify(ctx, log, job, models.EnrichmentNotification{UserID: job.UserID, Status: models.EnrichmentDone})
+		}
+	case errors.Is(err, peopleinfo.ErrNotFound) || errors.Is(err, peopleinfo.ErrRejected) || job.Attempts >= e.cfg.MaxAttempts:
+		log.Warn("enrichment failed for good, dead-lettering", l.Err(err))
+
+		jobErr := err.Error()
+		err = e.queue.DeadLetterEnrichmentJob(ctx, job, jobErr)
+		if err == nil {
+			e.notify(ctx, log, job, models.EnrichmentNotification{UserID: job.UserID, Status: models.EnrichmentFailed, Error: jobErr})
+		}
+	default:
+		runAt := time.Now().Add(e.backoff(job.Attempts))
+		log.Warn("enrichment failed, retrying later", slog.Time("run_at", runAt), l.Err(err))
+
+		err = e.queue.RescheduleEnrichmentJob(ctx, job, err.Error(), runAt)
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Error("failed to finish enrichment job", l.Err(err))
+	}
+
+	return true
+}
+
+func (e *Enricher) fetch(ctx context.Context, job models.EnrichmentJob) (models.People, error) {
+	if job.Passport.Serie == "" || job.Passport.Number == "" {
+		// the passport was erased before the user was enriched
+		return models.People{}, fmt.Errorf("%w: user has no passport", peopleinfo.ErrRejected)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.Lease)
+	defer cancel()
+
+	return e.fetcher.Info(ctx, job.Passport)
+}
+
+// backoff returns the jittered delay before the next attempt of a job that failed attempts times
+func (e *Enricher) backoff(attempts int) time.Duration {
+	delay := e.cfg.Backoff
+	for i := 1; i < attempts && delay < e.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if e.cfg.MaxBackoff > 0 && delay > e.cfg.MaxBackoff {
+		delay = e.cfg.MaxBackoff
+	}
+
+	// up to a quarter either way, so that jobs that failed together don't retry together
+	return delay + time.Duration(rand.Int64N(int64(delay/2)+1)) - delay/4
+}
+
+// notify posts the notification to the callback URL of the job, if it has one.
+// Notifications are best effort, a failed one is only logged.
+func (e *Enricher) notify(ctx context.Context, log *slog.Logger, job models.EnrichmentJob, notification models.EnrichmentNotification) {
+	if job.CallbackURL == "" {
+		return
+	}
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		log.Error("failed to encode notification", l.Err(err))
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		log.Error("failed to create notification request", l.Err(err))
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.http.Do(req)
+	if err != nil {
+		log.Warn("failed to notify callback url", l.Err(err))
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		log.Warn("callback url rejected notification", slog.Int("status", resp.StatusCode))
+	}
+}
@@ -0,0 +1,103 @@
+package purger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/kuromii5/time-tracker/internal/audit"
+	"github.com/kuromii5/time-tracker/internal/models"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type UsersPurger interface {
+	PurgeDeletedUsers(ctx context.Context, retention time.Duration) ([]models.User, error)
+}
+
+// PeopleInfoCache holds the people info of passports, see peopleinfo.Cache
+type PeopleInfoCache interface {
+	Invalidate(ctx context.Context, passport models.Passport) (int, error)
+	Sweep(ctx context.Context) (int, error)
+}
+
+// Purger periodically deletes for good the users that were soft-deleted longer than the retention period ago,
+// together with the people info cached for them, and sweeps expired people info from the cache.
+// Like the reaper, purging is a single statement that skips rows locked by others,
+// so several replicas can run their purgers at the same time.
+type Purger struct {
+	log        *slog.Logger
+	purger     UsersPurger
+	peopleInfo PeopleInfoCache
+	interval   time.Duration
+	retention  time.Duration
+}
+
+func New(log *slog.Logger, purger UsersPurger, peopleInfo PeopleInfoCache, interval, retention time.Duration) *Purger {
+	return &Purger{
+		log:        log.With(slog.String("job", "purger")),
+		purger:     purger,
+		peopleInfo: peopleInfo,
+		interval:   interval,
+		retention:  retention,
+	}
+}
+
+// Run purges on every interval until ctx is cancelled
+func (p *Purger) Run(ctx context.Context) {
+	p.log.Info("purger started",
+		slog.Duration("interval", p.interval),
+		slog.Duration("retention", p.retention),
+	)
+
+	// purges are attributed to the purger in the audit log
+	ctx = audit.WithActor(ctx, audit.ActorPurger)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			p.log.Info("purger stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Purger) purge(ctx context.Context) {
+	purged, err := p.purger.PurgeDeletedUsers(ctx, p.retention)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.log.Error("failed to purge deleted users", l.Err(err))
+		}
+		return
+	}
+
+	if len(purged) > 0 {
+		ids := make([]int32, len(purged))
+		for i, user := range purged {
+			ids[i] = user.ID
+			if user.Passport == (models.Passport{}) {
+				continue
+			}
+			if _, err := p.peopleInfo.Invalidate(ctx, user.Passport); err != nil {
+				p.log.Error("failed to invalidate people info", slog.Int("user_id", int(user.ID)), l.Err(err))
+			}
+		}
+		p.log.Info("purged deleted users", slog.Any("user_ids", ids))
+	}
+
+	swept, err := p.peopleInfo.Sweep(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.log.Error("failed to sweep people info cache", l.Err(err))
+		}
+		return
+	}
+	if swept > 0 {
+		p.log.Info("swept expired people info", slog.Int("count", swept))
+	}
+}
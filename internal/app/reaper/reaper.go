@@ -0,0 +1,72 @@
+package reaper
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/kuromii5/time-tracker/internal/audit"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type WorklogsStopper interface {
+	AutoStopWorklogs(ctx context.Context, maxDuration time.Duration) ([]int32, error)
+}
+
+// Reaper periodically stops worklogs that were left running for too long.
+// Stopping is a single idempotent statement that skips rows locked by others,
+// so several replicas can run their reapers at the same time.
+type Reaper struct {
+	log         *slog.Logger
+	stopper     WorklogsStopper
+	interval    time.Duration
+	maxDuration time.Duration
+}
+
+func New(log *slog.Logger, stopper WorklogsStopper, interval, maxDuration time.Duration) *Reaper {
+	return &Reaper{
+		log:         log.With(slog.String("job", "reaper")),
+		stopper:     stopper,
+		interval:    interval,
+		maxDuration: maxDuration,
+	}
+}
+
+// Run reaps on every interval until ctx is cancelled
+func (r *Reaper) Run(ctx context.Context) {
+	r.log.Info("reaper started",
+		slog.Duration("interval", r.interval),
+		slog.Duration("max_duration", r.maxDuration),
+	)
+
+	// stops are attributed to the reaper in the audit log
+	ctx = audit.WithActor(ctx, audit.ActorReaper)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.reap(ctx)
+
+		select {
+		case <-ctx.Done():
+			r.log.Info("reaper stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Reaper) reap(ctx context.Context) {
+	stopped, err := r.stopper.AutoStopWorklogs(ctx, r.maxDuration)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.log.Error("failed to stop forgotten worklogs", l.Err(err))
+		}
+		return
+	}
+
+	if len(stopped) > 0 {
+		r.log.Info("stopped forgotten worklogs", slog.Any("worklog_ids", stopped))
+	}
+}
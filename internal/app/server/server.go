@@ -9,9 +9,21 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	_ "github.com/kuromii5/time-tracker/docs"
+	"github.com/kuromii5/time-tracker/internal/app/userimport"
+	"github.com/kuromii5/time-tracker/internal/auth"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/apikey"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/auditlog"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/calendar"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/client"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/enrichment"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/peoplecache"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/project"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/report"
 	"github.com/kuromii5/time-tracker/internal/http-server/handlers/user"
 	"github.com/kuromii5/time-tracker/internal/http-server/handlers/worklog"
+	mwauth "github.com/kuromii5/time-tracker/internal/http-server/middleware/mw_auth"
 	mwlog "github.com/kuromii5/time-tracker/internal/http-server/middleware/mw_log"
+	"github.com/kuromii5/time-tracker/internal/peopleinfo"
 	"github.com/kuromii5/time-tracker/internal/repo"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
@@ -19,14 +31,16 @@ import (
 func New(
 	logger *slog.Logger,
 	port int,
-	reqTimeout, idleTimeout time.Duration,
+	reqTimeout, idleTimeout, importTimeout time.Duration,
 	db *repo.DB,
-	externalAPIPort int,
+	peopleInfo *peopleinfo.Cache,
+	importer *userimport.Importer,
+	authenticator *auth.Authenticator,
 ) *http.Server {
 	r := chi.NewRouter()
 
 	applyMiddlewares(r, logger)
-	setupRoutes(r, logger, db, externalAPIPort)
+	setupRoutes(r, logger, db, peopleInfo, importer, importTimeout, authenticator)
 
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", port),
@@ -46,20 +60,85 @@ func applyMiddlewares(r *chi.Mux, logger *slog.Logger) {
 	r.Use(middleware.Recoverer)
 }
 
-func setupRoutes(r *chi.Mux, logger *slog.Logger, db *repo.DB, extAPIPort int) {
+func setupRoutes(
+	r *chi.Mux,
+	logger *slog.Logger,
+	db *repo.DB,
+	peopleInfo *peopleinfo.Cache,
+	importer *userimport.Importer,
+	importTimeout time.Duration,
+	authenticator *auth.Authenticator,
+) {
 	// use swagger
 	r.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("http://localhost:8080/swagger/doc.json"), // The url pointing to API definition
 	))
 
-	// user routes
-	r.Get("/users", user.Users(logger, db))
-	r.Post("/users", user.CreateUser(logger, db, extAPIPort))
-	r.Patch("/users/{id}", user.UpdateUser(logger, db))
-	r.Delete("/users/{id}", user.DeleteUser(logger, db))
+	// calendar feeds are protected by their own secret token, so calendar apps can subscribe
+	r.Get("/calendar/{token}.ics", calendar.Feed(logger, db, db))
+
+	// everything else requires an API key or a JWT, handlers check permissions with the guard
+	guard := auth.NewGuard(db)
+	r.Group(func(r chi.Router) {
+		r.Use(mwauth.New(logger, authenticator))
+
+		// user routes
+		r.Get("/users", user.Users(logger, db, guard))
+		r.Post("/users", user.CreateUser(logger, db, guard))
+		r.Patch("/users/{id}", user.UpdateUser(logger, db, guard))
+		r.Delete("/users/{id}", user.DeleteUser(logger, db, guard))
+		r.Post("/users/{id}/restore", user.RestoreUser(logger, db, guard))
+		r.Get("/users/{id}/personal-data", user.PersonalData(logger, db, guard))
+		r.Post("/users/{id}/erase", user.EraseUser(logger, db, peopleInfo, guard))
+		r.Get("/users/{id}/enrichment", user.Enrichment(logger, db, guard))
+
+		// worklog routes
+		r.Get("/users/{userID}/worklogs", worklog.Worklogs(logger, db, guard))
+		r.Get("/worklogs/export", worklog.ExportWorklogs(logger, db, guard))
+		r.Post("/worklogs", worklog.CreateWorklog(logger, db, guard))
+		r.Patch("/worklogs/{id}", worklog.UpdateWorklog(logger, db, guard))
+		r.Delete("/worklogs/{id}", worklog.DeleteWorklog(logger, db, guard))
+		r.Post("/worklogs/start", worklog.StartWorklog(logger, db, guard))
+		r.Patch("/worklogs/finish/{id}", worklog.FinishWorklog(logger, db, guard))
+		r.Post("/worklogs/{id}/pause", worklog.PauseWorklog(logger, db, guard))
+		r.Post("/worklogs/{id}/resume", worklog.ResumeWorklog(logger, db, guard))
+
+		// client routes, the catalog is deliberately readable by every authenticated principal
+		// so that employees can pick a project for their worklogs
+		r.Get("/clients", client.Clients(logger, db))
+		r.Post("/clients", client.CreateClient(logger, db, guard))
+		r.Patch("/clients/{id}", client.UpdateClient(logger, db, guard))
+		r.Delete("/clients/{id}", client.DeleteClient(logger, db, guard))
+
+		// project routes, readable by everyone like clients
+		r.Get("/projects", project.Projects(logger, db))
+		r.Post("/projects", project.CreateProject(logger, db, guard))
+		r.Patch("/projects/{id}", project.UpdateProject(logger, db, guard))
+		r.Delete("/projects/{id}", project.DeleteProject(logger, db, guard))
+
+		// report routes
+		r.Get("/reports/time", report.TimeReport(logger, db, guard))
+
+		// calendar routes
+		r.Get("/users/{userID}/worklogs.ics", calendar.UserCalendar(logger, db, guard))
+		r.Post("/users/{userID}/calendar-token", calendar.CreateToken(logger, db, guard))
+
+		// admin routes
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(mwauth.RequireAdmin)
+
+			r.Get("/api-keys", apikey.APIKeys(logger, db))
+			r.Post("/api-keys", apikey.CreateAPIKey(logger, db))
+			r.Delete("/api-keys/{id}", apikey.RevokeAPIKey(logger, db))
+
+			r.Get("/audit-events", auditlog.AuditEvents(logger, db))
+
+			r.Post("/users/import", user.ImportUsers(logger, importer, importTimeout))
+
+			r.Delete("/people-info-cache", peoplecache.Invalidate(logger, peopleInfo))
 
-	// worklog routes
-	r.Get("/users/{userID}/worklogs", worklog.Worklogs(logger, db))
-	r.Post("/worklogs/start", worklog.StartWorklog(logger, db))
-	r.Patch("/worklogs/finish/{id}", worklog.FinishWorklog(logger, db))
+			r.Get("/enrichment-jobs", enrichment.Jobs(logger, db))
+			r.Post("/enrichment-jobs/{id}/retry", enrichment.RetryJob(logger, db))
+		})
+	})
 }
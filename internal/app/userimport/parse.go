@@ -0,0 +1,187 @@
+package userimport
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/utils"
+)
+
+// Formats of imported files
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// FormatOf returns the format of a file by its media type or name, or "" if it is neither CSV nor NDJSON
+func FormatOf(mediaTypeOrName string) string {
+	if mediaType, _, err := mime.ParseMediaType(mediaTypeOrName); err == nil {
+		switch mediaType {
+		case "text/csv":
+			return FormatCSV
+		case "application/x-ndjson", "application/ndjson", "application/jsonl":
+			return FormatNDJSON
+		}
+	}
+
+	switch strings.ToLower(filepath.Ext(mediaTypeOrName)) {
+	case ".csv":
+		return FormatCSV
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	}
+	return ""
+}
+
+// Record is a row of an imported file. It is named like the request of POST /users,
+// the people data is optional.
+type Record struct {
+	PassportNumber string `json:"passportNumber"`
+	Name           string `json:"name"`
+	Surname        string `json:"surname"`
+	Patronymic     string `json:"patronymic"`
+	Address        string `json:"address"`
+}
+
+// row is a parsed record, or why it could not be parsed
+type row struct {
+	line     int
+	passport models.Passport
+	people   models.People
+	err      error
+}
+
+func (r Record) row(line int) row {
+	passport, err := utils.ParsePassportData(r.PassportNumber)
+	if err != nil {
+		return row{line: line, err: err}
+	}
+
+	return row{
+		line:     line,
+		passport: passport,
+		people: models.People{
+			Name:       strings.TrimSpace(r.Name),
+			Surname:    strings.TrimSpace(r.Surname),
+			Patronymic: strings.TrimSpace(r.Patronymic),
+			Address:    strings.TrimSpace(r.Address),
+		},
+	}
+}
+
+// parse reads the rows of the file. Rows that can't be parsed are returned with their errors,
+// the error is only returned if the file can't be read at all.
+func parse(r io.Reader, format string, maxRows int) ([]row, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r, maxRows)
+	case FormatNDJSON:
+		return parseNDJSON(r, maxRows)
+	default:
+		return nil, fmt.Errorf("%w: %q, should be %s or %s", ErrUnsupportedFormat, format, FormatCSV, FormatNDJSON)
+	}
+}
+
+// parseCSV reads CSV with a header naming the columns of Record, e.g. "passportNumber,name,surname"
+func parseCSV(r io.Reader, maxRows int) ([]row, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: the header is missing", ErrInvalidFile)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// spreadsheets tend to start CSV with a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		switch name {
+		case "passportnumber", "name", "surname", "patronymic", "address":
+			columns[name] = i
+		default:
+			return nil, fmt.Errorf("%w: unknown column %q, should be passportNumber, name, surname, patronymic or address", ErrInvalidFile, name)
+		}
+	}
+	if _, ok := columns["passportnumber"]; !ok {
+		return nil, fmt.Errorf("%w: the passportNumber column is missing", ErrInvalidFile)
+	}
+
+	field := func(fields []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(fields) {
+			return ""
+		}
+		return fields[i]
+	}
+
+	var rows []row
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr) && errors.Is(err, csv.ErrFieldCount):
+			rows = append(rows, row{line: parseErr.StartLine, err: fmt.Errorf("expected %d fields, got %d", len(header), len(fields))})
+		case err != nil:
+			return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		default:
+			line, _ := reader.FieldPos(0)
+			rows = append(rows, Record{
+				PassportNumber: field(fields, "passportnumber"),
+				Name:           field(fields, "name"),
+				Surname:        field(fields, "surname"),
+				Patronymic:     field(fields, "patronymic"),
+				Address:        field(fields, "address"),
+			}.row(line))
+		}
+
+		if len(rows) > maxRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrTooManyRows, maxRows)
+		}
+	}
+}
+
+// parseNDJSON reads a JSON Record per line, blank lines are skipped
+func parseNDJSON(r io.Reader, maxRows int) ([]row, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var rows []row
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			rows = append(rows, row{line: line, err: fmt.Errorf("invalid JSON: %w", err)})
+		} else {
+			rows = append(rows, record.row(line))
+		}
+
+		if len(rows) > maxRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrTooManyRows, maxRows)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	return rows, nil
+}
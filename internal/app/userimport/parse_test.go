@@ -0,0 +1,187 @@
+package userimport
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+)
+
+// parsed is a row as the tests compare it
+type parsed struct {
+	line     int
+	passport models.Passport
+	name     string
+	failed   bool
+}
+
+func checkRows(t *testing.T, rows []row, want []parsed) {
+	t.Helper()
+
+	if len(rows) != len(want) {
+		t.Fatalf("parse() = %d rows, want %d", len(rows), len(want))
+	}
+	for i, row := range rows {
+		got := parsed{line: row.line, passport: row.passport, name: row.people.Name, failed: row.err != nil}
+		if got != want[i] {
+			t.Errorf("row %d = %+v (error %v), want %+v", i, got, row.err, want[i])
+		}
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"text/csv", FormatCSV},
+		{"text/csv; charset=utf-8", FormatCSV},
+		{"application/x-ndjson", FormatNDJSON},
+		{"application/jsonl", FormatNDJSON},
+		{"users.CSV", FormatCSV},
+		{"/tmp/users.jsonl", FormatNDJSON},
+		{"application/json", ""},
+		{"users.xlsx", ""},
+	}
+
+	for _, tt := range tests {
+		if got := FormatOf(tt.input); got != tt.want {
+			t.Errorf("FormatOf(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	passport := models.Passport{Serie: "1234", Number: "567890"}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []parsed
+		wantErr error
+	}{
+		{
+			name:  "passports only",
+			input: "passportNumber\n1234 567890\n4321 098765\n",
+			want: []parsed{
+				{line: 2, passport: passport},
+				{line: 3, passport: models.Passport{Serie: "4321", Number: "098765"}},
+			},
+		},
+		{
+			name:  "people data in any order",
+			input: "\ufeffName, PassportNumber,surname\n Ivan ,1234 567890,Ivanov\n",
+			want:  []parsed{{line: 2, passport: passport, name: "Ivan"}},
+		},
+		{
+			name:  "invalid passport",
+			input: "passportNumber,name\n1234567890,Ivan\n1234 567890,Petr\n",
+			want: []parsed{
+				{line: 2, failed: true},
+				{line: 3, passport: passport, name: "Petr"},
+			},
+		},
+		{
+			name:  "wrong field count",
+			input: "passportNumber,name\n1234 567890\n1234 567890,Ivan\n",
+			want: []parsed{
+				{line: 2, failed: true},
+				{line: 3, passport: passport, name: "Ivan"},
+			},
+		},
+		{
+			name:  "quoted multiline field",
+			input: "passportNumber,address\n1234 567890,\"Moscow,\nLenina 1\"\n4321 098765,Kazan\n",
+			want: []parsed{
+				{line: 2, passport: passport},
+				{line: 4, passport: models.Passport{Serie: "4321", Number: "098765"}},
+			},
+		},
+		{
+			name:    "empty file",
+			input:   "",
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "unknown column",
+			input:   "passportNumber,email\n1234 567890,a@b.c\n",
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "passport column missing",
+			input:   "name,surname\nIvan,Ivanov\n",
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "too many rows",
+			input:   "passportNumber\n1234 567890\n1234 567891\n1234 567892\n1234 567893\n",
+			wantErr: ErrTooManyRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parse(strings.NewReader(tt.input), FormatCSV, 3)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parse() error = %v, want %v", err, tt.wantErr)
+			}
+			checkRows(t, rows, tt.want)
+		})
+	}
+}
+
+func TestParseNDJSON(t *testing.T) {
+	passport := models.Passport{Serie: "1234", Number: "567890"}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []parsed
+		wantErr error
+	}{
+		{
+			name:  "records",
+			input: `{"passportNumber": "1234 567890", "name": "Ivan"}` + "\n" + `{"passportNumber": "4321 098765"}`,
+			want: []parsed{
+				{line: 1, passport: passport, name: "Ivan"},
+				{line: 2, passport: models.Passport{Serie: "4321", Number: "098765"}},
+			},
+		},
+		{
+			name:  "blank lines keep line numbers",
+			input: "\n" + `{"passportNumber": "1234 567890"}` + "\n  \n",
+			want:  []parsed{{line: 2, passport: passport}},
+		},
+		{
+			name:  "invalid JSON and passport",
+			input: `{"passportNumber": ` + "\n" + `{"passportNumber": "12 34"}` + "\n" + `{"passportNumber": "1234 567890"}`,
+			want: []parsed{
+				{line: 1, failed: true},
+				{line: 2, failed: true},
+				{line: 3, passport: passport},
+			},
+		},
+		{
+			name:    "too many rows",
+			input:   "{}\n{}\n{}\n{}\n",
+			wantErr: ErrTooManyRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parse(strings.NewReader(tt.input), FormatNDJSON, 3)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parse() error = %v, want %v", err, tt.wantErr)
+			}
+			checkRows(t, rows, tt.want)
+		})
+	}
+}
+
+func TestParseUnsupportedFormat(t *testing.T) {
+	if _, err := parse(strings.NewReader(""), "xlsx", 3); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("parse() error = %v, want %v", err, ErrUnsupportedFormat)
+	}
+}
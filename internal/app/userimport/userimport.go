@@ -0,0 +1,231 @@
+// Package userimport creates users in bulk from CSV or NDJSON files of passports
+// and, optionally, people data. Missing people data is looked up in the people info API.
+package userimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported format")
+	ErrInvalidFile       = errors.New("invalid file")
+	ErrTooManyRows       = errors.New("too many rows")
+)
+
+type PeopleInfoFetcher interface {
+	Info(ctx context.Context, passport models.Passport) (models.People, error)
+}
+
+type UsersCreator interface {
+	// CreateUsers creates users of distinct passports, the IDs of users whose passport is taken are zero
+	CreateUsers(ctx context.Context, users []models.User) ([]int32, error)
+}
+
+type Config struct {
+	// Concurrency is how many people info lookups are made at a time
+	Concurrency int
+	// BatchSize is how many users are created in a transaction
+	BatchSize int
+	// MaxRows is the most rows a file may have
+	MaxRows int
+}
+
+type Importer struct {
+	log     *slog.Logger
+	fetcher PeopleInfoFetcher
+	creator UsersCreator
+	cfg     Config
+}
+
+func New(log *slog.Logger, fetcher PeopleInfoFetcher, creator UsersCreator, cfg Config) *Importer {
+	return &Importer{
+		log:     log.With(slog.String("component", "userimport")),
+		fetcher: fetcher,
+		creator: creator,
+		cfg:     cfg,
+	}
+}
+
+// Import creates the users of the file and reports the outcome of every row. Rows whose passport is taken
+// or repeated in the file are duplicates, rows that can't be parsed, looked up or saved are failed.
+// An error is returned if the file can't be read, nothing is reported then. If ctx is done, the error is
+// returned with the report of the rows handled so far, the rows that were not saved are reported as failed.
+func (i *Importer) Import(ctx context.Context, r io.Reader, format string) (models.ImportReport, error) {
+	log := i.log.With(slog.String("format", format))
+
+	rows, err := parse(r, format, i.cfg.MaxRows)
+	if err != nil {
+		log.Warn("failed to parse file", l.Err(err))
+
+		return models.ImportReport{}, err
+	}
+	log.Info("importing users", slog.Int("rows", len(rows)))
+
+	var report models.ImportReport
+
+	// only the first row of a passport is imported
+	seen := make(map[models.Passport]int, len(rows))
+	var valid []row
+	for _, row := range rows {
+		if row.err != nil {
+			report.Add(models.ImportRow{Line: row.line, Status: models.ImportFailed, Error: row.err.Error()})
+			continue
+		}
+		if line, ok := seen[row.passport]; ok {
+			report.Add(models.ImportRow{
+				Line:   row.line,
+				Status: models.ImportDuplicate,
+				Error:  fmt.Sprintf("the passport is repeated from line %d", line),
+			})
+			continue
+		}
+		seen[row.passport] = row.line
+		valid = append(valid, row)
+	}
+
+	i.enrich(ctx, valid)
+	if err := ctx.Err(); err != nil {
+		return stopped(log, report, valid, err)
+	}
+
+	var enriched []row
+	for _, row := range valid {
+		if row.err != nil {
+			report.Add(models.ImportRow{Line: row.line, Status: models.ImportFailed, Error: row.err.Error()})
+			continue
+		}
+		enriched = append(enriched, row)
+	}
+
+	size := max(i.cfg.BatchSize, 1)
+	for start := 0; start < len(enriched); start += size {
+		batch := enriched[start:min(start+size, len(enriched))]
+		if err := i.create(ctx, log, batch, &report); err != nil {
+			return stopped(log, report, enriched[start:], err)
+		}
+	}
+
+	sortRows(&report)
+
+	log.Info("users imported",
+		slog.Int("created", report.Created),
+		slog.Int("duplicates", report.Duplicates),
+		slog.Int("failed", report.Failed),
+	)
+
+	return report, nil
+}
+
+// stopped reports the rows that were not saved because the import stopped with err, and returns the report with err
+func stopped(log *slog.Logger, report models.ImportReport, unsaved []row, err error) (models.ImportReport, error) {
+	for _, row := range unsaved {
+		report.Add(models.ImportRow{Line: row.line, Status: models.ImportFailed, Error: fmt.Sprintf("the import stopped before the user was saved: %v", err)})
+	}
+	sortRows(&report)
+
+	log.Warn("import stopped",
+		slog.Int("created", report.Created),
+		slog.Int("duplicates", report.Duplicates),
+		slog.Int("failed", report.Failed),
+		l.Err(err),
+	)
+
+	return report, err
+}
+
+// sortRows orders the rows of the report by line
+func sortRows(report *models.ImportReport) {
+	slices.SortFunc(report.Rows, func(a, b models.ImportRow) int {
+		return a.Line - b.Line
+	})
+}
+
+// enrich looks up people info of the rows missing a name, surname or address, at most Concurrency at a time.
+// People data given in the file takes precedence, rows that can't be looked up get the error.
+func (i *Importer) enrich(ctx context.Context, rows []row) {
+	sem := make(chan struct{}, max(i.cfg.Concurrency, 1))
+	var wg sync.WaitGroup
+
+	for n := range rows {
+		row := &rows[n]
+		if row.people.Name != "" && row.people.Surname != "" && row.people.Address != "" {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			people, err := i.fetcher.Info(ctx, row.passport)
+			if err != nil {
+				row.err = fmt.Errorf("failed to get people info: %w", err)
+				return
+			}
+
+			row.people.Name = coalesce(row.people.Name, people.Name)
+			row.people.Surname = coalesce(row.people.Surname, people.Surname)
+			row.people.Patronymic = coalesce(row.people.Patronymic, people.Patronymic)
+			row.people.Address = coalesce(row.people.Address, people.Address)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// coalesce returns the given value unless it is empty
+func coalesce(given, fetched string) string {
+	if given != "" {
+		return given
+	}
+	return fetched
+}
+
+// create creates the users of a batch and adds their outcomes to the report.
+// A batch that fails is reported as failed, only ctx being done is returned.
+func (i *Importer) create(ctx context.Context, log *slog.Logger, batch []row, report *models.ImportReport) error {
+	users := make([]models.User, len(batch))
+	for n, row := range batch {
+		users[n] = models.User{Passport: row.passport, People: row.people}
+	}
+
+	ids, err := i.creator.CreateUsers(ctx, users)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Error("failed to create users", slog.Int("first_line", batch[0].line), l.Err(err))
+
+		for _, row := range batch {
+			report.Add(models.ImportRow{Line: row.line, Status: models.ImportFailed, Error: "failed to save the user"})
+		}
+		return nil
+	}
+
+	for n, row := range batch {
+		if ids[n] == 0 {
+			report.Add(models.ImportRow{Line: row.line, Status: models.ImportDuplicate, Error: repo.ErrPassportDuplicate.Error()})
+			continue
+		}
+		report.Add(models.ImportRow{Line: row.line, Status: models.ImportCreated, ID: ids[n]})
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package userimport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+)
+
+type fakeFetcher struct{}
+
+func (fakeFetcher) Info(ctx context.Context, passport models.Passport) (models.People, error) {
+	if err := ctx.Err(); err != nil {
+		return models.People{}, err
+	}
+	return models.People{Name: "Ivan", Surname: "Ivanov", Address: "Moscow"}, nil
+}
+
+// fakeCreator creates the users of its batches, calls after each of them and fails once ctx is done
+type fakeCreator struct {
+	nextID int32
+	after  func()
+}
+
+func (c *fakeCreator) CreateUsers(ctx context.Context, users []models.User) ([]int32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int32, len(users))
+	for i := range users {
+		c.nextID++
+		ids[i] = c.nextID
+	}
+	if c.after != nil {
+		c.after()
+	}
+	return ids, nil
+}
+
+func TestImport(t *testing.T) {
+	input := "passportNumber\n1234 567890\n1234 567890\nbad\n1234 567891\n1234 567892\n"
+
+	tests := []struct {
+		name       string
+		cancel     bool
+		wantErr    error
+		wantStatus []string
+	}{
+		{
+			name: "everything",
+			wantStatus: []string{
+				models.ImportCreated, models.ImportDuplicate, models.ImportFailed, models.ImportCreated, models.ImportCreated,
+			},
+		},
+		{
+			name:    "stopped after the first batch",
+			cancel:  true,
+			wantErr: context.Canceled,
+			wantStatus: []string{
+				models.ImportCreated, models.ImportDuplicate, models.ImportFailed, models.ImportCreated, models.ImportFailed,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			creator := &fakeCreator{}
+			if tt.cancel {
+				creator.after = cancel
+			}
+			importer := New(slog.New(slog.NewTextHandler(io.Discard, nil)), fakeFetcher{}, creator, Config{Concurrency: 2, BatchSize: 2, MaxRows: 10})
+
+			report, err := importer.Import(ctx, strings.NewReader(input), FormatCSV)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Import() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(report.Rows) != len(tt.wantStatus) {
+				t.Fatalf("Import() reported %d rows, want %d", len(report.Rows), len(tt.wantStatus))
+			}
+			for i, row := range report.Rows {
+				if row.Line != i+2 || row.Status != tt.wantStatus[i] {
+					t.Errorf("row %d = line %d %s (%s), want line %d %s", i, row.Line, row.Status, row.Error, i+2, tt.wantStatus[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package worklogimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Formats of imported exports
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// FormatOf returns the format of an export by its media type or name, or "" if it is neither CSV nor JSON
+func FormatOf(mediaTypeOrName string) string {
+	if mediaType, _, err := mime.ParseMediaType(mediaTypeOrName); err == nil {
+		switch mediaType {
+		case "text/csv":
+			return FormatCSV
+		case "application/json":
+			return FormatJSON
+		}
+	}
+
+	switch strings.ToLower(filepath.Ext(mediaTypeOrName)) {
+	case ".csv":
+		return FormatCSV
+	case ".json":
+		return FormatJSON
+	}
+	return ""
+}
+
+// entry is a time entry of an export, or why it could not be parsed
+type entry struct {
+	line int
+	// name and email of the person who tracked the entry, either may be missing
+	name, email string
+	project     string
+	task        string
+	start, end  time.Time
+	err         error
+}
+
+// person names the person of the entry in reports
+func (e entry) person() string {
+	switch {
+	case e.name != "" && e.email != "":
+		return fmt.Sprintf("%s <%s>", e.name, e.email)
+	case e.name != "":
+		return e.name
+	default:
+		return e.email
+	}
+}
+
+// newEntry fills an entry from the fields shared by the exports. The task of a worklog is the description,
+// prefixed with the task of the export if there is one.
+func newEntry(line int, name, email, project, task, description string) entry {
+	name, email = strings.TrimSpace(name), strings.TrimSpace(email)
+	task, description = strings.TrimSpace(task), strings.TrimSpace(description)
+
+	e := entry{line: line, name: name, email: email, project: strings.TrimSpace(project)}
+	switch {
+	case task != "" && description != "":
+		e.task = task + ": " + description
+	case task != "":
+		e.task = task
+	case description != "":
+		e.task = description
+	default:
+		e.task = "(no description)"
+	}
+	if name == "" && email == "" {
+		e.err = errors.New("the entry has no user")
+	}
+
+	return e
+}
+
+// parse reads the entries of an export. Entries that can't be parsed are returned with their errors,
+// the error is only returned if the export can't be read at all.
+// CSV exports have no offsets, their times are read in loc.
+func parse(r io.Reader, format string, loc *time.Location, dayFirst bool) ([]entry, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r, loc, dayFirst)
+	case FormatJSON:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return parseJSON(data)
+	default:
+		return nil, fmt.Errorf("%w: %q, should be %s or %s", ErrUnsupportedFormat, format, FormatCSV, FormatJSON)
+	}
+}
+
+// csvColumns are the columns of detailed CSV reports that are imported, named like in both Toggl and Clockify
+// ignoring case. "member" is what newer Toggl exports call the user.
+var csvColumns = []string{"user", "member", "email", "project", "task", "description", "start date", "start time", "end date", "end time"}
+
+// parseCSV reads a detailed report exported as CSV from Toggl or Clockify. Other columns are ignored.
+func parseCSV(r io.Reader, loc *time.Location, dayFirst bool) ([]entry, error) {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: the header is missing", ErrInvalidFile)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	columns := make(map[string]int, len(csvColumns))
+	for i, name := range header {
+		// spreadsheets tend to start CSV with a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for _, column := range csvColumns {
+			if name == column {
+				columns[name] = i
+			}
+		}
+	}
+	for _, column := range []string{"start date", "start time", "end date", "end time"} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("%w: the %q column is missing, is it a detailed report?", ErrInvalidFile, column)
+		}
+	}
+	_, hasUser := columns["user"]
+	_, hasMember := columns["member"]
+	_, hasEmail := columns["email"]
+	if !hasUser && !hasMember && !hasEmail {
+		return nil, fmt.Errorf("%w: the \"user\" or \"email\" column is missing", ErrInvalidFile)
+	}
+
+	field := func(fields []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(fields) {
+			return ""
+		}
+		return strings.TrimSpace(fields[i])
+	}
+
+	var entries []entry
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr) && errors.Is(err, csv.ErrFieldCount):
+			entries = append(entries, entry{line: parseErr.StartLine, err: fmt.Errorf("expected %d fields, got %d", len(header), len(fields))})
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		name := field(fields, "user")
+		if name == "" {
+			name = field(fields, "member")
+		}
+		e := newEntry(line, name, field(fields, "email"), field(fields, "project"), field(fields, "task"), field(fields, "description"))
+
+		if field(fields, "end date") == "" && field(fields, "end time") == "" {
+			e.err = errStillRunning
+		}
+		if e.err == nil {
+			e.start, e.err = parseLocal(field(fields, "start date"), field(fields, "start time"), loc, dayFirst)
+		}
+		if e.err == nil {
+			e.end, e.err = parseLocal(field(fields, "end date"), field(fields, "end time"), loc, dayFirst)
+		}
+		entries = append(entries, e)
+	}
+}
+
+var (
+	// dateLayouts are the date formats of the exports, months come first in slashed dates unless dayFirst
+	dateLayouts         = []string{"2006-01-02", "1/2/2006", "2.1.2006"}
+	dayFirstDateLayouts = []string{"2006-01-02", "2/1/2006", "2.1.2006"}
+	clockLayouts        = []string{"15:04:05", "15:04", "3:04:05 PM", "3:04 PM"}
+)
+
+// parseLocal parses the date and time of a CSV export in loc
+func parseLocal(date, clock string, loc *time.Location, dayFirst bool) (time.Time, error) {
+	layouts := dateLayouts
+	if dayFirst {
+		layouts = dayFirstDateLayouts
+	}
+
+	value := date + " " + strings.ToUpper(clock)
+	for _, dateLayout := range layouts {
+		for _, clockLayout := range clockLayouts {
+			if t, err := time.ParseInLocation(dateLayout+" "+clockLayout, value, loc); err == nil {
+				return t, nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date and time %q", value)
+}
+
+// jsonEntry is a time entry of a detailed report exported as JSON, from the Toggl reports API (v2)
+// or the Clockify reports API. Toggl and Clockify name the same things differently.
+type jsonEntry struct {
+	// Toggl
+	User        string `json:"user"`
+	Email       string `json:"email"`
+	Project     string `json:"project"`
+	Task        string `json:"task"`
+	Description string `json:"description"`
+	Start       string `json:"start"`
+	End         string `json:"end"`
+	Stop        string `json:"stop"`
+
+	// Clockify
+	UserName     string `json:"userName"`
+	UserEmail    string `json:"userEmail"`
+	ProjectName  string `json:"projectName"`
+	TaskName     string `json:"taskName"`
+	TimeInterval struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"timeInterval"`
+}
+
+func (j jsonEntry) entry(line int) entry {
+	e := newEntry(line, first(j.UserName, j.User), first(j.UserEmail, j.Email), first(j.ProjectName, j.Project), first(j.TaskName, j.Task), j.Description)
+
+	start, end := first(j.TimeInterval.Start, j.Start), first(j.TimeInterval.End, j.End, j.Stop)
+	if end == "" {
+		e.err = errStillRunning
+	}
+	if e.err == nil {
+		e.start, e.err = parseTimestamp(start)
+	}
+	if e.err == nil {
+		e.end, e.err = parseTimestamp(end)
+	}
+
+	return e
+}
+
+// parseTimestamp parses a time of a JSON export, which has an offset
+func parseTimestamp(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", value)
+	}
+	return t, nil
+}
+
+// first returns the first of the values that is not empty
+func first(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseJSON reads the entries of a JSON export: an array of entries, or an object with the entries
+// under "data" like Toggl or under "timeentries" like Clockify. Lines are where the entries start.
+func parseJSON(data []byte) ([]entry, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+	switch tok {
+	case json.Delim('['):
+		return parseJSONEntries(dec, data)
+	case json.Delim('{'):
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+			}
+			if key == "data" || key == "timeentries" {
+				if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+					return nil, fmt.Errorf("%w: %q should be an array of time entries", ErrInvalidFile, key)
+				}
+				return parseJSONEntries(dec, data)
+			}
+
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: expected an array of time entries, or an object with them under \"data\" or \"timeentries\"", ErrInvalidFile)
+}
+
+// parseJSONEntries reads the elements of the array the decoder is in
+func parseJSONEntries(dec *json.Decoder, data []byte) ([]entry, error) {
+	var entries []entry
+	for dec.More() {
+		line := lineAt(data, dec.InputOffset())
+
+		var j jsonEntry
+		if err := dec.Decode(&j); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+			}
+			// the value was read whole, the next one can still be decoded
+			entries = append(entries, entry{line: line, err: fmt.Errorf("invalid entry: %w", err)})
+			continue
+		}
+		entries = append(entries, j.entry(line))
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	return entries, nil
+}
+
+// lineAt returns the line of the value following offset, skipping the separators in between
+func lineAt(data []byte, offset int64) int {
+	n := int(offset)
+	for n < len(data) && strings.IndexByte(", \t\r\n", data[n]) >= 0 {
+		n++
+	}
+	return 1 + bytes.Count(data[:n], []byte("\n"))
+}
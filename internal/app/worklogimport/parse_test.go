@@ -0,0 +1,208 @@
+package worklogimport
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parsed is an entry as the tests compare it
+type parsed struct {
+	line       int
+	person     string
+	project    string
+	task       string
+	start, end time.Time
+	failed     bool
+}
+
+func checkEntries(t *testing.T, entries []entry, want []parsed) {
+	t.Helper()
+
+	if len(entries) != len(want) {
+		t.Fatalf("parse() = %d entries, want %d", len(entries), len(want))
+	}
+	for i, e := range entries {
+		got := parsed{line: e.line, person: e.person(), project: e.project, task: e.task, start: e.start, end: e.end, failed: e.err != nil}
+		if got.failed {
+			got = parsed{line: e.line, failed: true}
+		}
+		if !got.start.Equal(want[i].start) || !got.end.Equal(want[i].end) {
+			t.Errorf("entry %d times = %v - %v, want %v - %v", i, got.start, got.end, want[i].start, want[i].end)
+		}
+		got.start, got.end = want[i].start, want[i].end
+		if got != want[i] {
+			t.Errorf("entry %d = %+v (error %v), want %+v", i, got, e.err, want[i])
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2024, month, day, hour, min, 0, 0, moscow)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		dayFirst bool
+		want     []parsed
+		wantErr  error
+	}{
+		{
+			name: "toggl",
+			input: "User,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration\n" +
+				"Ivan Ivanov,ivan@example.com,Acme,Site,Design,Header,No,2024-06-03,09:00:00,2024-06-03,10:30:00,01:30:00\n",
+			want: []parsed{{
+				line: 2, person: "Ivan Ivanov <ivan@example.com>", project: "Site", task: "Design: Header",
+				start: at(time.June, 3, 9, 0), end: at(time.June, 3, 10, 30),
+			}},
+		},
+		{
+			name: "clockify, slashed dates and 12-hour clock",
+			input: "\ufeffProject,Client,Description,Task,User,Email,Start Date,Start Time,End Date,End Time\n" +
+				"Site,Acme,,,Ivan Ivanov,,06/03/2024,09:00 pm,06/03/2024,11:15 PM\n",
+			want: []parsed{{
+				line: 2, person: "Ivan Ivanov", project: "Site", task: "(no description)",
+				start: at(time.June, 3, 21, 0), end: at(time.June, 3, 23, 15),
+			}},
+		},
+		{
+			name: "day first",
+			input: "Member,Description,Start date,Start time,End date,End time\n" +
+				"Ivan,Review,06/03/2024,09:00,06/03/2024,10:00\n",
+			dayFirst: true,
+			want: []parsed{{
+				line: 2, person: "Ivan", task: "Review",
+				start: at(time.March, 6, 9, 0), end: at(time.March, 6, 10, 0),
+			}},
+		},
+		{
+			name: "failed entries",
+			input: "User,Description,Start date,Start time,End date,End time\n" +
+				"Ivan,Running,2024-06-03,09:00,,\n" +
+				",No user,2024-06-03,09:00,2024-06-03,10:00\n" +
+				"Ivan,Bad date,2024-13-03,09:00,2024-06-03,10:00\n" +
+				"Ivan,Short\n" +
+				"Ivan,Fine,2024-06-03,11:00,2024-06-03,12:00\n",
+			want: []parsed{
+				{line: 2, failed: true},
+				{line: 3, failed: true},
+				{line: 4, failed: true},
+				{line: 5, failed: true},
+				{line: 6, person: "Ivan", task: "Fine", start: at(time.June, 3, 11, 0), end: at(time.June, 3, 12, 0)},
+			},
+		},
+		{
+			name:    "empty export",
+			input:   "",
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "summary report",
+			input:   "User,Project,Duration\nIvan,Site,01:30:00\n",
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "no user column",
+			input:   "Description,Start date,Start time,End date,End time\nReview,2024-06-03,09:00,2024-06-03,10:00\n",
+			wantErr: ErrInvalidFile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := parse(strings.NewReader(tt.input), FormatCSV, moscow, tt.dayFirst)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parse() error = %v, want %v", err, tt.wantErr)
+			}
+			checkEntries(t, entries, tt.want)
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	start := time.Date(2024, 6, 3, 6, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 3, 7, 30, 0, 0, time.UTC)
+
+	toggl := `{"user": "Ivan Ivanov", "email": "ivan@example.com", "project": "Site", "description": "Header",
+		"start": "2024-06-03T09:00:00+03:00", "end": "2024-06-03T10:30:00+03:00"}`
+	clockify := `{"userName": "Ivan Ivanov", "projectName": "Site", "taskName": "Design", "description": "Header",
+		"timeInterval": {"start": "2024-06-03T06:00:00Z", "end": "2024-06-03T07:30:00Z"}}`
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []parsed
+		wantErr error
+	}{
+		{
+			name:  "toggl array",
+			input: "[\n" + toggl + "\n]",
+			want: []parsed{{
+				line: 2, person: "Ivan Ivanov <ivan@example.com>", project: "Site", task: "Header", start: start, end: end,
+			}},
+		},
+		{
+			name:  "toggl data",
+			input: `{"total_count": 1, "data": [` + toggl + `]}`,
+			want: []parsed{{
+				line: 1, person: "Ivan Ivanov <ivan@example.com>", project: "Site", task: "Header", start: start, end: end,
+			}},
+		},
+		{
+			name:  "clockify timeentries",
+			input: "{\n  \"totals\": [],\n  \"timeentries\": [\n    " + clockify + "\n  ]\n}",
+			want: []parsed{{
+				line: 4, person: "Ivan Ivanov", project: "Site", task: "Design: Header", start: start, end: end,
+			}},
+		},
+		{
+			name: "failed entries",
+			input: "[\n" +
+				`{"user": "Ivan", "start": "2024-06-03T09:00:00+03:00"},` + "\n" +
+				`{"user": "Ivan", "start": "yesterday", "end": "2024-06-03T10:30:00+03:00"},` + "\n" +
+				`{"user": 12},` + "\n" +
+				toggl + "\n]",
+			want: []parsed{
+				{line: 2, failed: true},
+				{line: 3, failed: true},
+				{line: 4, failed: true},
+				{line: 5, person: "Ivan Ivanov <ivan@example.com>", project: "Site", task: "Header", start: start, end: end},
+			},
+		},
+		{
+			name:    "not an export",
+			input:   `{"entries": []}`,
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "data is not an array",
+			input:   `{"data": {}}`,
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "truncated",
+			input:   "[" + toggl,
+			wantErr: ErrInvalidFile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := parse(strings.NewReader(tt.input), FormatJSON, time.UTC, false)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parse() error = %v, want %v", err, tt.wantErr)
+			}
+			checkEntries(t, entries, tt.want)
+		})
+	}
+}
+
+func TestParseUnsupportedFormat(t *testing.T) {
+	if _, err := parse(strings.NewReader(""), "xlsx", time.UTC, false); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("parse() error = %v, want %v", err, ErrUnsupportedFormat)
+	}
+}
@@ -0,0 +1,152 @@
+package worklogimport
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+)
+
+// Rules decide which users and projects the entries of an export belong to
+type Rules struct {
+	// People maps people of the export, by email or name, to user IDs. It is checked first.
+	People map[string]int32 `json:"people"`
+	// MatchNames maps the other people to the only user with their name, e.g. "Ivan Ivanov" is a user
+	// named Ivan with surname Ivanov, or the other way around, with or without the patronymic
+	MatchNames bool `json:"match_names"`
+	// Projects maps projects of the export, by name, to project IDs. Other projects are matched by name.
+	Projects map[string]int32 `json:"projects"`
+	// IgnoreUnknownProjects imports the entries of projects that are not found without a project
+	// instead of failing them
+	IgnoreUnknownProjects bool `json:"ignore_unknown_projects"`
+}
+
+// mapper resolves the people and projects of entries by the rules
+type mapper struct {
+	matchNames     bool
+	ignoreProjects bool
+	people         map[string]int32
+	projectRules   map[string]int32
+	// users and projectIDs are the IDs that exist
+	users      map[int32]bool
+	projectIDs map[int32]bool
+	// names and projects are the IDs by normalized name
+	names    map[string][]int32
+	projects map[string][]int32
+}
+
+// newMapper indexes the users and projects that entries are mapped to. Keys of the rules are matched
+// ignoring case and repeated spaces.
+func newMapper(rules Rules, users []models.User, projects []models.Project) *mapper {
+	m := &mapper{
+		matchNames:     rules.MatchNames,
+		ignoreProjects: rules.IgnoreUnknownProjects,
+		people:         make(map[string]int32, len(rules.People)),
+		projectRules:   make(map[string]int32, len(rules.Projects)),
+		users:          make(map[int32]bool, len(users)),
+		projectIDs:     make(map[int32]bool, len(projects)),
+		names:          make(map[string][]int32),
+		projects:       make(map[string][]int32, len(projects)),
+	}
+	for key, id := range rules.People {
+		m.people[normalize(key)] = id
+	}
+	for key, id := range rules.Projects {
+		m.projectRules[normalize(key)] = id
+	}
+
+	for _, user := range users {
+		m.users[user.ID] = true
+
+		p := user.People
+		if p.Name == "" || p.Surname == "" { // erased users can't be matched
+			continue
+		}
+		for _, name := range []string{
+			p.Name + " " + p.Surname,
+			p.Surname + " " + p.Name,
+			p.Name + " " + p.Patronymic + " " + p.Surname,
+			p.Surname + " " + p.Name + " " + p.Patronymic,
+		} {
+			key := normalize(name)
+			if ids := m.names[key]; len(ids) == 0 || ids[len(ids)-1] != user.ID {
+				m.names[key] = append(ids, user.ID)
+			}
+		}
+	}
+
+	for _, project := range projects {
+		key := normalize(project.Name)
+		m.projects[key] = append(m.projects[key], project.ID)
+		m.projectIDs[project.ID] = true
+	}
+
+	return m
+}
+
+// user returns the ID of the user who tracked the entry
+func (m *mapper) user(e entry) (int32, error) {
+	for _, key := range []string{e.email, e.name} {
+		if key == "" {
+			continue
+		}
+		if id, ok := m.people[normalize(key)]; ok {
+			if !m.users[id] {
+				return 0, fmt.Errorf("%q is mapped to user %d, which is not found", key, id)
+			}
+			return id, nil
+		}
+	}
+
+	if !m.matchNames || e.name == "" {
+		return 0, fmt.Errorf("%q is not mapped to a user", e.person())
+	}
+	switch ids := m.names[normalize(e.name)]; len(ids) {
+	case 0:
+		return 0, fmt.Errorf("no user is named %q", e.name)
+	case 1:
+		return ids[0], nil
+	default:
+		return 0, fmt.Errorf("%q matches several users: %s", e.name, joinIDs(ids))
+	}
+}
+
+// project returns the ID of the project of the entry, zero if it has none
+func (m *mapper) project(e entry) (int32, error) {
+	if e.project == "" {
+		return 0, nil
+	}
+
+	if id, ok := m.projectRules[normalize(e.project)]; ok {
+		if !m.projectIDs[id] {
+			return 0, fmt.Errorf("project %q is mapped to project %d, which is not found", e.project, id)
+		}
+		return id, nil
+	}
+
+	switch ids := m.projects[normalize(e.project)]; len(ids) {
+	case 0:
+		if m.ignoreProjects {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("no project is named %q", e.project)
+	case 1:
+		return ids[0], nil
+	default:
+		return 0, fmt.Errorf("project %q matches several projects: %s", e.project, joinIDs(ids))
+	}
+}
+
+// normalize lowercases s and collapses its spaces
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// joinIDs lists IDs separated by commas
+func joinIDs(ids []int32) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = fmt.Sprint(id)
+	}
+	return strings.Join(s, ", ")
+}
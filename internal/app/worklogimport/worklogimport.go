@@ -0,0 +1,311 @@
+// Package worklogimport creates finished worklogs from the detailed reports exported from Toggl and Clockify,
+// mapping the people and projects of an export to users and projects by rules.
+package worklogimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported format")
+	ErrInvalidFile       = errors.New("invalid file")
+
+	errStillRunning = errors.New("the entry is still running")
+)
+
+// Store is where the users and projects are looked up and the worklogs are created
+type Store interface {
+	Users(ctx context.Context, filter models.FilterBy, settings models.Pagination) ([]models.User, models.Page, error)
+	Projects(ctx context.Context, clientID int32) ([]models.Project, error)
+	Worklogs(ctx context.Context, userID int32, filter models.WorklogFilter, settings models.Pagination) ([]models.Worklog, models.Page, error)
+	CreateWorklog(ctx context.Context, worklog models.Worklog) (int32, error)
+}
+
+type Options struct {
+	// Format is csv or json, Toggl and Clockify exports are told apart by their columns
+	Format string
+	// Location is the time zone of the dates and times of CSV exports, which have no offsets. UTC if nil.
+	Location *time.Location
+	// DayFirst reads slashed dates of CSV exports like 02/01/2024 as the 2nd of January
+	DayFirst bool
+	// DryRun checks the export and reports what would be created without writing anything
+	DryRun bool
+	Rules  Rules
+}
+
+type Importer struct {
+	log   *slog.Logger
+	store Store
+}
+
+func New(log *slog.Logger, store Store) *Importer {
+	return &Importer{
+		log:   log.With(slog.String("component", "worklogimport")),
+		store: store,
+	}
+}
+
+// pending is a worklog of an entry that is mapped and checked so far
+type pending struct {
+	line    int
+	worklog models.Worklog
+}
+
+// Import creates a finished worklog with the original times for every entry of the export and reports
+// the outcome of every entry. Entries that were imported before or are repeated in the export are duplicates,
+// entries that can't be parsed or mapped, or overlap other worklogs of their users, are failed.
+// An error is returned if the export can't be read, nothing is reported then. If ctx is done while worklogs
+// are created, the error is returned with the report of the entries handled so far, the entries that were
+// not created are reported as failed.
+func (i *Importer) Import(ctx context.Context, r io.Reader, opts Options) (models.ImportReport, error) {
+	log := i.log.With(slog.String("format", opts.Format), slog.Bool("dry_run", opts.DryRun))
+
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	entries, err := parse(r, opts.Format, loc, opts.DayFirst)
+	if err != nil {
+		log.Warn("failed to parse export", l.Err(err))
+
+		return models.ImportReport{}, err
+	}
+	log.Info("importing worklogs", slog.Int("entries", len(entries)))
+
+	users, _, err := i.store.Users(ctx, models.FilterBy{}, models.Pagination{})
+	if err != nil {
+		return models.ImportReport{}, fmt.Errorf("failed to get users: %w", err)
+	}
+	projects, err := i.store.Projects(ctx, 0)
+	if err != nil {
+		return models.ImportReport{}, fmt.Errorf("failed to get projects: %w", err)
+	}
+	m := newMapper(opts.Rules, users, projects)
+
+	report := models.ImportReport{DryRun: opts.DryRun}
+
+	now := time.Now()
+	var mapped []pending
+	for _, e := range entries {
+		if e.err == nil && !e.end.After(e.start) {
+			e.err = repo.ErrInvalidRange
+		}
+		if e.err == nil && e.end.After(now) {
+			e.err = errors.New("the entry finishes in the future")
+		}
+
+		var userID, projectID int32
+		if e.err == nil {
+			userID, e.err = m.user(e)
+		}
+		if e.err == nil {
+			projectID, e.err = m.project(e)
+		}
+		if e.err != nil {
+			report.Add(models.ImportRow{Line: e.line, Status: models.ImportFailed, Error: e.err.Error()})
+			continue
+		}
+
+		mapped = append(mapped, pending{line: e.line, worklog: models.Worklog{
+			UserID:     userID,
+			ProjectID:  projectID,
+			Task:       e.task,
+			StartedAt:  e.start,
+			FinishedAt: e.end,
+		}})
+	}
+
+	checked, err := i.checkOverlaps(ctx, mapped, &report)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+
+	for n, p := range checked {
+		if opts.DryRun {
+			report.Add(models.ImportRow{Line: p.line, Status: models.ImportCreated})
+			continue
+		}
+		if err := i.create(ctx, log, p, &report); err != nil {
+			return stopped(log, report, checked[n:], err)
+		}
+	}
+
+	sortRows(&report)
+
+	log.Info("worklogs imported",
+		slog.Int("created", report.Created),
+		slog.Int("duplicates", report.Duplicates),
+		slog.Int("failed", report.Failed),
+	)
+
+	return report, nil
+}
+
+// stopped reports the entries that were not created because the import stopped with err, and returns the report with err
+func stopped(log *slog.Logger, report models.ImportReport, uncreated []pending, err error) (models.ImportReport, error) {
+	for _, p := range uncreated {
+		report.Add(models.ImportRow{Line: p.line, Status: models.ImportFailed, Error: fmt.Sprintf("the import stopped before the worklog was created: %v", err)})
+	}
+	sortRows(&report)
+
+	log.Warn("import stopped",
+		slog.Int("created", report.Created),
+		slog.Int("duplicates", report.Duplicates),
+		slog.Int("failed", report.Failed),
+		l.Err(err),
+	)
+
+	return report, err
+}
+
+// sortRows orders the rows of the report by line
+func sortRows(report *models.ImportReport) {
+	slices.SortFunc(report.Rows, func(a, b models.ImportRow) int {
+		return a.Line - b.Line
+	})
+}
+
+// checkOverlaps reports the worklogs that repeat or overlap existing worklogs of their users or earlier
+// worklogs of the export, and returns the rest in the order of the export.
+// A worklog with the same times and task as an existing one was imported before, so it is a duplicate.
+func (i *Importer) checkOverlaps(ctx context.Context, mapped []pending, report *models.ImportReport) ([]pending, error) {
+	var userIDs []int32
+	byUser := make(map[int32][]pending)
+	for _, p := range mapped {
+		if _, ok := byUser[p.worklog.UserID]; !ok {
+			userIDs = append(userIDs, p.worklog.UserID)
+		}
+		byUser[p.worklog.UserID] = append(byUser[p.worklog.UserID], p)
+	}
+
+	var checked []pending
+	for _, userID := range userIDs {
+		worklogs := byUser[userID]
+
+		from, to := worklogs[0].worklog.StartedAt, worklogs[0].worklog.FinishedAt
+		for _, p := range worklogs[1:] {
+			if p.worklog.StartedAt.Before(from) {
+				from = p.worklog.StartedAt
+			}
+			if p.worklog.FinishedAt.After(to) {
+				to = p.worklog.FinishedAt
+			}
+		}
+		// open worklogs started before the range count as overlapping it too
+		existing, _, err := i.store.Worklogs(ctx, userID, models.WorklogFilter{From: from, To: to}, models.Pagination{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get worklogs of user %d: %w", userID, err)
+		}
+
+		var kept []pending
+	next:
+		for _, p := range worklogs {
+			var overlapping []int32
+			for _, w := range existing {
+				if same(p.worklog, w) {
+					report.Add(models.ImportRow{
+						Line:   p.line,
+						Status: models.ImportDuplicate,
+						Error:  fmt.Sprintf("the entry was imported before as worklog %d", w.ID),
+					})
+					continue next
+				}
+				if overlaps(p.worklog, w) {
+					overlapping = append(overlapping, w.ID)
+				}
+			}
+			if len(overlapping) > 0 {
+				report.Add(models.ImportRow{
+					Line:   p.line,
+					Status: models.ImportFailed,
+					Error:  fmt.Sprintf("the entry overlaps existing worklogs of user %d: %s", userID, joinIDs(overlapping)),
+				})
+				continue
+			}
+
+			for _, k := range kept {
+				switch {
+				case same(p.worklog, k.worklog):
+					report.Add(models.ImportRow{
+						Line:   p.line,
+						Status: models.ImportDuplicate,
+						Error:  fmt.Sprintf("the entry is repeated from line %d", k.line),
+					})
+					continue next
+				case overlaps(p.worklog, k.worklog):
+					report.Add(models.ImportRow{
+						Line:   p.line,
+						Status: models.ImportFailed,
+						Error:  fmt.Sprintf("the entry overlaps the entry on line %d", k.line),
+					})
+					continue next
+				}
+			}
+			kept = append(kept, p)
+		}
+
+		checked = append(checked, kept...)
+	}
+
+	slices.SortFunc(checked, func(a, b pending) int {
+		return a.line - b.line
+	})
+
+	return checked, nil
+}
+
+// same tells whether two worklogs have the same times and task
+func same(a, b models.Worklog) bool {
+	return a.Task == b.Task && a.StartedAt.Equal(b.StartedAt) && a.FinishedAt.Equal(b.FinishedAt)
+}
+
+// overlaps tells whether two worklogs intersect, open worklogs last forever
+func overlaps(a, b models.Worklog) bool {
+	return (a.FinishedAt.IsZero() || b.StartedAt.Before(a.FinishedAt)) &&
+		(b.FinishedAt.IsZero() || a.StartedAt.Before(b.FinishedAt))
+}
+
+// create creates the worklog and adds its outcome to the report.
+// A worklog that can't be created is reported as failed, only ctx being done is returned.
+func (i *Importer) create(ctx context.Context, log *slog.Logger, p pending, report *models.ImportReport) error {
+	id, err := i.store.CreateWorklog(ctx, p.worklog)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// the checks raced with changes of the user's worklogs, or of the user and project
+		var overlapErr *repo.OverlapError
+		row := models.ImportRow{Line: p.line, Status: models.ImportFailed}
+		switch {
+		case errors.As(err, &overlapErr):
+			ids := make([]int32, len(overlapErr.Conflicts))
+			for n, w := range overlapErr.Conflicts {
+				ids[n] = w.ID
+			}
+			row.Error = fmt.Sprintf("the entry overlaps existing worklogs of user %d: %s", p.worklog.UserID, joinIDs(ids))
+		case errors.Is(err, repo.ErrUserNotFound) || errors.Is(err, repo.ErrProjectNotFound) || errors.Is(err, repo.ErrInvalidRange):
+			row.Error = err.Error()
+		default:
+			log.Error("failed to create worklog", slog.Int("line", p.line), l.Err(err))
+			row.Error = "failed to save the worklog"
+		}
+		report.Add(row)
+
+		return nil
+	}
+
+	report.Add(models.ImportRow{Line: p.line, Status: models.ImportCreated, ID: id})
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package worklogimport
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+)
+
+var day = time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+
+// worklog is a worklog of the task between the hours of day, open if to is zero
+func worklog(id int32, task string, from, to int) models.Worklog {
+	w := models.Worklog{ID: id, UserID: 1, Task: task, StartedAt: day.Add(time.Duration(from) * time.Hour)}
+	if to != 0 {
+		w.FinishedAt = day.Add(time.Duration(to) * time.Hour)
+	}
+	return w
+}
+
+func TestSameAndOverlaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     models.Worklog
+		same     bool
+		overlaps bool
+	}{
+		{"equal", worklog(0, "a", 9, 10), worklog(0, "a", 9, 10), true, true},
+		{"other task", worklog(0, "a", 9, 10), worklog(0, "b", 9, 10), false, true},
+		{"other end", worklog(0, "a", 9, 10), worklog(0, "a", 9, 11), false, true},
+		{"inside", worklog(0, "a", 9, 12), worklog(0, "b", 10, 11), false, true},
+		{"partly", worklog(0, "a", 9, 11), worklog(0, "b", 10, 12), false, true},
+		{"touching", worklog(0, "a", 9, 10), worklog(0, "b", 10, 11), false, false},
+		{"apart", worklog(0, "a", 9, 10), worklog(0, "b", 11, 12), false, false},
+		{"open before", worklog(0, "a", 8, 0), worklog(0, "b", 10, 11), false, true},
+		{"open after", worklog(0, "a", 12, 0), worklog(0, "b", 10, 11), false, false},
+		{"both open", worklog(0, "a", 8, 0), worklog(0, "b", 12, 0), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := same(tt.a, tt.b); got != tt.same {
+				t.Errorf("same() = %v, want %v", got, tt.same)
+			}
+			if got := overlaps(tt.a, tt.b); got != tt.overlaps {
+				t.Errorf("overlaps() = %v, want %v", got, tt.overlaps)
+			}
+			if got := overlaps(tt.b, tt.a); got != tt.overlaps {
+				t.Errorf("overlaps() reversed = %v, want %v", got, tt.overlaps)
+			}
+		})
+	}
+}
+
+// fakeStore has the worklogs of user 1
+type fakeStore struct {
+	Store
+	worklogs []models.Worklog
+}
+
+func (s fakeStore) Worklogs(ctx context.Context, userID int32, filter models.WorklogFilter, settings models.Pagination) ([]models.Worklog, models.Page, error) {
+	if userID != 1 {
+		return nil, models.Page{}, nil
+	}
+	return s.worklogs, models.Page{}, nil
+}
+
+func TestCheckOverlaps(t *testing.T) {
+	store := fakeStore{worklogs: []models.Worklog{
+		worklog(10, "imported", 9, 10),
+		worklog(11, "meeting", 14, 15),
+	}}
+	importer := &Importer{store: store}
+
+	other := worklog(0, "other user", 9, 10)
+	other.UserID = 2
+	mapped := []pending{
+		{line: 2, worklog: worklog(0, "imported", 9, 10)},
+		{line: 3, worklog: worklog(0, "review", 14, 16)},
+		{line: 4, worklog: worklog(0, "design", 11, 12)},
+		{line: 5, worklog: worklog(0, "design", 11, 12)},
+		{line: 6, worklog: worklog(0, "calls", 11, 13)},
+		{line: 7, worklog: worklog(0, "calls", 12, 13)},
+		{line: 8, worklog: other},
+	}
+
+	var report models.ImportReport
+	checked, err := importer.checkOverlaps(context.Background(), mapped, &report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []int
+	for _, p := range checked {
+		lines = append(lines, p.line)
+	}
+	if want := []int{4, 7, 8}; !slices.Equal(lines, want) {
+		t.Errorf("checkOverlaps() kept lines %v, want %v", lines, want)
+	}
+
+	want := map[int]string{
+		2: models.ImportDuplicate,
+		3: models.ImportFailed,
+		5: models.ImportDuplicate,
+		6: models.ImportFailed,
+	}
+	if len(report.Rows) != len(want) {
+		t.Fatalf("checkOverlaps() reported %d rows, want %d", len(report.Rows), len(want))
+	}
+	for _, row := range report.Rows {
+		if row.Status != want[row.Line] {
+			t.Errorf("line %d = %s (%s), want %s", row.Line, row.Status, row.Error, want[row.Line])
+		}
+	}
+}
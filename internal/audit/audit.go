@@ -0,0 +1,27 @@
+// Package audit carries who makes a change down to the repo, which records it in the audit log.
+package audit
+
+import "context"
+
+// System actors make changes that no request asked for
+const (
+	ActorSystem   = "system"
+	ActorReaper   = "system:reaper"
+	ActorPurger   = "system:purger"
+	ActorEnricher = "system:enricher"
+)
+
+type ctxKey struct{}
+
+// WithActor returns a copy of ctx in which changes are attributed to actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, actor)
+}
+
+// Actor returns who changes are attributed to in ctx, ActorSystem if nobody is set
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(ctxKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return ActorSystem
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	"github.com/kuromii5/time-tracker/internal/utils"
+	"github.com/kuromii5/time-tracker/pkg/jwt"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+// KeyPrefix starts every API key, so keys are told apart from JWTs and recognized by secret scanners
+const KeyPrefix = "tt_"
+
+// prefixLen is how much of a key is stored in clear text
+const prefixLen = len(KeyPrefix) + 8
+
+var (
+	ErrNoCredentials      = errors.New("missing credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+type Store interface {
+	UseAPIKey(ctx context.Context, keyHash string) (models.APIKey, error)
+	UserAccess(ctx context.Context, userID int32) (role string, managerID int32, err error)
+}
+
+// Claims are the private JWT claims the tracker understands
+type Claims struct {
+	UserID int32  `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+type Authenticator struct {
+	log      *slog.Logger
+	store    Store
+	verifier *jwt.Verifier
+}
+
+// New creates an authenticator. JWTs are rejected if verifier is nil.
+func New(log *slog.Logger, store Store, verifier *jwt.Verifier) *Authenticator {
+	return &Authenticator{log: log, store: store, verifier: verifier}
+}
+
+// NewAPIKey generates a key and returns it together with its stored prefix and hash
+func NewAPIKey() (key, prefix, hash string, err error) {
+	token, err := utils.NewToken()
+	if err != nil {
+		return "", "", "", err
+	}
+	key = KeyPrefix + token
+
+	return key, key[:prefixLen], utils.HashToken(key), nil
+}
+
+// Authenticate finds the principal of the request. API keys are taken from the
+// X-API-Key header or a bearer token starting with KeyPrefix, any other bearer token is a JWT.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return a.authenticateKey(r.Context(), key)
+	}
+
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, ErrNoCredentials
+	}
+	if strings.HasPrefix(token, KeyPrefix) {
+		return a.authenticateKey(r.Context(), token)
+	}
+
+	return a.authenticateJWT(r.Context(), token)
+}
+
+func (a *Authenticator) authenticateKey(ctx context.Context, key string) (*Principal, error) {
+	apiKey, err := a.store.UseAPIKey(ctx, utils.HashToken(key))
+	if err != nil {
+		if errors.Is(err, repo.ErrAPIKeyNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("%s: %w", "auth.authenticateKey", err)
+	}
+
+	p := &Principal{
+		Subject: strconv.Itoa(int(apiKey.ID)),
+		Method:  MethodAPIKey,
+		KeyID:   apiKey.ID,
+		UserID:  apiKey.UserID,
+	}
+	if err := a.resolveRole(ctx, p); err != nil {
+		return nil, err
+	}
+	// admin keys are issued by admins, so they act as admins whoever they are bound to
+	if apiKey.Admin {
+		p.Role = models.RoleAdmin
+	}
+
+	return p, nil
+}
+
+// roleRank orders the roles by what they may do
+var roleRank = map[string]int{
+	models.RoleEmployee: 1,
+	models.RoleManager:  2,
+	models.RoleAdmin:    3,
+}
+
+// resolveRole gives principals bound to a user the user's role. A role the principal already has,
+// e.g. from a JWT claim, may only narrow it. Deleted users are not found, so their credentials stop working.
+func (a *Authenticator) resolveRole(ctx context.Context, p *Principal) error {
+	if p.UserID == 0 {
+		return nil
+	}
+
+	role, _, err := a.store.UserAccess(ctx, p.UserID)
+	if err != nil {
+		if errors.Is(err, repo.ErrUserNotFound) {
+			return ErrInvalidCredentials
+		}
+		return fmt.Errorf("%s: %w", "auth.resolveRole", err)
+	}
+	if p.Role == "" || roleRank[p.Role] > roleRank[role] {
+		p.Role = role
+	}
+
+	return nil
+}
+
+func (a *Authenticator) authenticateJWT(ctx context.Context, token string) (*Principal, error) {
+	if a.verifier == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	var claims Claims
+	registered, err := a.verifier.Verify(token, &claims)
+	if err != nil {
+		a.log.Debug("jwt rejected", l.Err(err))
+
+		return nil, ErrInvalidCredentials
+	}
+	if registered.Subject == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	// subjects that are user IDs bind the token to the user
+	userID := claims.UserID
+	if userID == 0 {
+		if id, err := strconv.Atoi(registered.Subject); err == nil {
+			userID = int32(id)
+		}
+	}
+
+	switch claims.Role {
+	case "", models.RoleAdmin, models.RoleManager, models.RoleEmployee:
+	default:
+		return nil, ErrInvalidCredentials
+	}
+
+	p := &Principal{
+		Subject: registered.Subject,
+		Method:  MethodJWT,
+		UserID:  userID,
+		Role:    claims.Role,
+	}
+	if err := a.resolveRole(ctx, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
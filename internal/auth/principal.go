@@ -0,0 +1,83 @@
+// Package auth authenticates requests with API keys and JWT bearer tokens.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+)
+
+// Authentication methods
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// Principal is who a request is made by
+type Principal struct {
+	// Subject identifies the principal in logs and the audit log, the API key ID or the JWT subject
+	Subject string
+	Method  string
+	// KeyID is the API key used, zero for JWT
+	KeyID int32
+	// UserID is the user the principal acts as, zero if it is not bound to a user
+	UserID int32
+	// Role is one of the models roles, empty for service keys with no role
+	Role string
+}
+
+// IsAdmin reports whether the principal has the admin role
+func (p *Principal) IsAdmin() bool {
+	return p.Role == models.RoleAdmin
+}
+
+// String identifies the principal across methods, e.g. "api_key:3"
+func (p *Principal) String() string {
+	return fmt.Sprintf("%s:%s", p.Method, p.Subject)
+}
+
+// LogValue lets principals be logged with slog.Any
+func (p *Principal) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("subject", p.Subject),
+		slog.String("method", p.Method),
+		slog.Int("user_id", int(p.UserID)),
+		slog.String("role", p.Role),
+	)
+}
+
+type ctxKey int
+
+const (
+	principalKey ctxKey = iota
+	slotKey
+)
+
+// slot lets middlewares that run before authentication see the principal afterwards
+type slot struct {
+	principal *Principal
+}
+
+// NewContext returns a copy of ctx carrying the principal
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	if s, ok := ctx.Value(slotKey).(*slot); ok {
+		s.principal = p
+	}
+
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// FromContext returns the principal of the request, or nil if it is not authenticated
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey).(*Principal)
+	return p
+}
+
+// Track returns a context in which the principal is remembered once it is authenticated
+// further down the chain, and a function returning it
+func Track(ctx context.Context) (context.Context, func() *Principal) {
+	s := &slot{}
+	return context.WithValue(ctx, slotKey, s), func() *Principal { return s.principal }
+}
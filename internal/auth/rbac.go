@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+)
+
+// ErrForbidden is wrapped by every permission denial
+var ErrForbidden = errors.New("forbidden")
+
+// Directory looks up what permission checks depend on
+type Directory interface {
+	UserAccess(ctx context.Context, userID int32) (role string, managerID int32, err error)
+	WorklogOwner(ctx context.Context, worklogID int32) (int32, error)
+}
+
+// Guard checks whether the principal of a request may do something.
+//
+// Admins may do everything. Managers may read the users and worklogs of their team
+// and correct their team's worklogs, and manage clients and projects. Employees may only track
+// their own time. Only admins may delete users, edit passport data or change roles.
+type Guard struct {
+	dir Directory
+}
+
+func NewGuard(dir Directory) *Guard {
+	return &Guard{dir: dir}
+}
+
+func deny(reason string) error {
+	return fmt.Errorf("%w: %s", ErrForbidden, reason)
+}
+
+func principal(ctx context.Context) (*Principal, error) {
+	p := FromContext(ctx)
+	if p == nil {
+		return nil, deny("request is not authenticated")
+	}
+	return p, nil
+}
+
+// Admin allows admins only
+func (g *Guard) Admin(ctx context.Context) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if !p.IsAdmin() {
+		return deny("admin access required")
+	}
+	return nil
+}
+
+// ManageProjects allows creating, changing and deleting clients and projects to admins and managers
+func (g *Guard) ManageProjects(ctx context.Context) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if !p.IsAdmin() && p.Role != models.RoleManager {
+		return deny("only admins and managers may manage clients and projects")
+	}
+	return nil
+}
+
+// ListUsers allows admins to list all users and managers to list their team.
+// It returns the ID of the manager the listing is limited to, zero for no limit.
+func (g *Guard) ListUsers(ctx context.Context) (teamOf int32, err error) {
+	p, err := principal(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case p.IsAdmin():
+		return 0, nil
+	case p.Role == models.RoleManager && p.UserID != 0:
+		return p.UserID, nil
+	default:
+		return 0, deny("only admins and managers may list users")
+	}
+}
+
+// ReadUser allows reading the user's profile and worklogs to the user, their manager and admins
+func (g *Guard) ReadUser(ctx context.Context, userID int32) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if p.IsAdmin() || (p.UserID != 0 && p.UserID == userID) {
+		return nil
+	}
+
+	return g.managerOf(ctx, p, userID, "you may only access your own or your team's data")
+}
+
+// UpdateUser allows the user, their manager and admins to change the user's settings.
+// Passport data, roles and teams can only be changed by admins.
+func (g *Guard) UpdateUser(ctx context.Context, user models.User) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if p.IsAdmin() {
+		return nil
+	}
+
+	if user.Passport.Serie != "" || user.Passport.Number != "" {
+		return deny("only admins may edit passport data")
+	}
+	if user.Role != "" || user.ManagerID != 0 || user.ClearManager {
+		return deny("only admins may change roles and teams")
+	}
+
+	return g.ReadUser(ctx, user.ID)
+}
+
+// TrackTime allows starting a worklog for the user to the user and admins
+func (g *Guard) TrackTime(ctx context.Context, userID int32) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if p.IsAdmin() || (p.UserID != 0 && p.UserID == userID) {
+		return nil
+	}
+
+	return deny("you may only track your own time")
+}
+
+// TrackWorklog allows finishing, pausing and resuming the worklog to its owner and admins
+func (g *Guard) TrackWorklog(ctx context.Context, worklogID int32) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if p.IsAdmin() {
+		return nil
+	}
+
+	ownerID, err := g.dir.WorklogOwner(ctx, worklogID)
+	if err != nil {
+		return err
+	}
+
+	return g.TrackTime(ctx, ownerID)
+}
+
+// EditWorklogs allows creating and correcting the user's worklogs by hand
+// to the user's manager, managers for themselves, and admins
+func (g *Guard) EditWorklogs(ctx context.Context, userID int32) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if p.IsAdmin() {
+		return nil
+	}
+	if p.Role != models.RoleManager {
+		return deny("only managers and admins may edit worklogs")
+	}
+	if p.UserID != 0 && p.UserID == userID {
+		return nil
+	}
+
+	return g.managerOf(ctx, p, userID, "managers may only edit their team's worklogs")
+}
+
+// EditWorklog is EditWorklogs for the owner of the worklog
+func (g *Guard) EditWorklog(ctx context.Context, worklogID int32) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if p.IsAdmin() {
+		return nil
+	}
+
+	ownerID, err := g.dir.WorklogOwner(ctx, worklogID)
+	if err != nil {
+		return err
+	}
+
+	return g.EditWorklogs(ctx, ownerID)
+}
+
+// managerOf allows managers of the user and denies everyone else with reason
+func (g *Guard) managerOf(ctx context.Context, p *Principal, userID int32, reason string) error {
+	if p.Role != models.RoleManager || p.UserID == 0 {
+		return deny(reason)
+	}
+
+	_, managerID, err := g.dir.UserAccess(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if managerID != p.UserID {
+		return deny(reason)
+	}
+
+	return nil
+}
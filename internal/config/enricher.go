@@ -0,0 +1,16 @@
+package config
+
+import "github.com/kuromii5/time-tracker/internal/app/enricher"
+
+// Enricher returns the configuration of the background enrichment of users
+func (c *Config) Enricher() enricher.Config {
+	return enricher.Config{
+		Workers:         c.EnrichmentWorkers,
+		PollInterval:    c.EnrichmentPollInterval,
+		Lease:           c.EnrichmentLease,
+		MaxAttempts:     c.EnrichmentMaxAttempts,
+		Backoff:         c.EnrichmentBackoff,
+		MaxBackoff:      c.EnrichmentMaxBackoff,
+		CallbackTimeout: c.EnrichmentCallbackTimeout,
+	}
+}
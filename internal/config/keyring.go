@@ -0,0 +1,27 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/kuromii5/time-tracker/pkg/fieldcrypt"
+)
+
+// PassportKeyring creates the keyring passports are encrypted with
+func (c *Config) PassportKeyring() (*fieldcrypt.Keyring, error) {
+	if c.PassportKeys == "" || c.PassportIndexKey == "" {
+		return nil, errors.New("PASSPORT_KEYS and PASSPORT_INDEX_KEY are required")
+	}
+
+	keys, err := fieldcrypt.ParseKeys(c.PassportKeys)
+	if err != nil {
+		return nil, fmt.Errorf("PASSPORT_KEYS: %w", err)
+	}
+	indexKey, err := base64.StdEncoding.DecodeString(c.PassportIndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("PASSPORT_INDEX_KEY: %w", err)
+	}
+
+	return fieldcrypt.NewKeyring(keys, c.PassportPrimaryKey, indexKey)
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"time"
 
@@ -16,7 +17,60 @@ type Config struct {
 	RequestTimeout time.Duration `env:"REQ_TIMEOUT"`
 	IdleTimeout    time.Duration `env:"IDLE_TIMEOUT"`
 
-	ExternalAPIPort int `env:"EXTERNAL_API_PORT"`
+	// People info API is asked who owns a passport when a user is created.
+	// Failed requests are retried with exponential backoff, and after BREAKER_THRESHOLD
+	// failures in a row the API is not called for BREAKER_COOLDOWN.
+	PeopleAPIURL              string        `env:"PEOPLE_API_URL" env-default:"http://localhost:8081"`
+	PeopleAPITimeout          time.Duration `env:"PEOPLE_API_TIMEOUT" env-default:"2s"`
+	PeopleAPIRetries          int           `env:"PEOPLE_API_RETRIES" env-default:"2"`
+	PeopleAPIBackoff          time.Duration `env:"PEOPLE_API_BACKOFF" env-default:"200ms"`
+	PeopleAPIMaxBackoff       time.Duration `env:"PEOPLE_API_MAX_BACKOFF" env-default:"2s"`
+	PeopleAPIBreakerThreshold int           `env:"PEOPLE_API_BREAKER_THRESHOLD" env-default:"5"`
+	PeopleAPIBreakerCooldown  time.Duration `env:"PEOPLE_API_BREAKER_COOLDOWN" env-default:"30s"`
+
+	// People info cache keeps answers of the API in the process ("memory"), in the database
+	// shared by all instances ("postgres") or nowhere ("off"). Unknown passports are kept for the negative TTL.
+	PeopleCache            string        `env:"PEOPLE_CACHE" env-default:"memory"`
+	PeopleCacheTTL         time.Duration `env:"PEOPLE_CACHE_TTL" env-default:"24h"`
+	PeopleCacheNegativeTTL time.Duration `env:"PEOPLE_CACHE_NEGATIVE_TTL" env-default:"10m"`
+
+	// Enricher fetches people info for new users in the background. Failed jobs are retried with
+	// exponential backoff and dead-lettered after ENRICHMENT_MAX_ATTEMPTS attempts.
+	EnrichmentWorkers         int           `env:"ENRICHMENT_WORKERS" env-default:"2"`
+	EnrichmentPollInterval    time.Duration `env:"ENRICHMENT_POLL_INTERVAL" env-default:"1s"`
+	EnrichmentLease           time.Duration `env:"ENRICHMENT_LEASE" env-default:"1m"`
+	EnrichmentMaxAttempts     int           `env:"ENRICHMENT_MAX_ATTEMPTS" env-default:"10"`
+	EnrichmentBackoff         time.Duration `env:"ENRICHMENT_BACKOFF" env-default:"10s"`
+	EnrichmentMaxBackoff      time.Duration `env:"ENRICHMENT_MAX_BACKOFF" env-default:"1h"`
+	EnrichmentCallbackTimeout time.Duration `env:"ENRICHMENT_CALLBACK_TIMEOUT" env-default:"5s"`
+
+	// Bulk imports of users look up people info with IMPORT_CONCURRENCY requests at a time
+	// and insert IMPORT_BATCH_SIZE users per transaction. IMPORT_TIMEOUT replaces REQ_TIMEOUT for them.
+	ImportConcurrency int           `env:"IMPORT_CONCURRENCY" env-default:"8"`
+	ImportBatchSize   int           `env:"IMPORT_BATCH_SIZE" env-default:"500"`
+	ImportMaxRows     int           `env:"IMPORT_MAX_ROWS" env-default:"10000"`
+	ImportTimeout     time.Duration `env:"IMPORT_TIMEOUT" env-default:"5m"`
+
+	// Reaper stops worklogs that were forgotten running
+	ReaperInterval    time.Duration `env:"REAPER_INTERVAL" env-default:"5m"`
+	ReaperMaxDuration time.Duration `env:"REAPER_MAX_DURATION" env-default:"12h"`
+
+	// Purger deletes for good the users that were soft-deleted longer than the retention period ago
+	PurgerInterval  time.Duration `env:"PURGER_INTERVAL" env-default:"1h"`
+	PurgerRetention time.Duration `env:"PURGER_RETENTION" env-default:"720h"`
+
+	// JWT bearer tokens are accepted if a secret (HS256) or a public key (RS256) is set
+	AuthJWTSecret        string `env:"AUTH_JWT_SECRET"`
+	AuthJWTPublicKeyFile string `env:"AUTH_JWT_PUBLIC_KEY_FILE"`
+	AuthJWTIssuer        string `env:"AUTH_JWT_ISSUER"`
+	AuthJWTAudience      string `env:"AUTH_JWT_AUDIENCE"`
+
+	// Passports are encrypted with the primary of the keys, listed as "<id>:<base64 key>,...".
+	// The others are kept to decrypt passports until they are re-encrypted after a rotation.
+	// The index key makes passports searchable and must never change.
+	PassportKeys       string `env:"PASSPORT_KEYS"`
+	PassportPrimaryKey string `env:"PASSPORT_PRIMARY_KEY" env-default:"1"`
+	PassportIndexKey   string `env:"PASSPORT_INDEX_KEY"`
 }
 
 func MustLoad() *Config {
@@ -29,6 +83,42 @@ func MustLoad() *Config {
 	if err != nil {
 		log.Fatalf("Configuration loading error: %v", err)
 	}
+	if err := cfg.validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	return &cfg
 }
+
+// validate rejects settings the people info client and the background jobs can't run with
+func (c *Config) validate() error {
+	if c.PeopleAPITimeout <= 0 {
+		return errors.New("PEOPLE_API_TIMEOUT should be positive")
+	}
+	if c.PeopleAPIRetries < 0 {
+		return errors.New("PEOPLE_API_RETRIES should not be negative")
+	}
+	if c.PeopleAPIBackoff < 0 {
+		return errors.New("PEOPLE_API_BACKOFF should not be negative")
+	}
+	if c.PeopleAPIMaxBackoff < 0 {
+		return errors.New("PEOPLE_API_MAX_BACKOFF should not be negative")
+	}
+	if c.ReaperInterval <= 0 {
+		return errors.New("REAPER_INTERVAL should be positive")
+	}
+	if c.ReaperMaxDuration <= 0 {
+		return errors.New("REAPER_MAX_DURATION should be positive")
+	}
+	if c.EnrichmentWorkers <= 0 {
+		return errors.New("ENRICHMENT_WORKERS should be positive")
+	}
+	if c.PurgerInterval <= 0 {
+		return errors.New("PURGER_INTERVAL should be positive")
+	}
+	if c.PurgerRetention <= 0 {
+		return errors.New("PURGER_RETENTION should be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package config
+
+import "github.com/kuromii5/time-tracker/internal/peopleinfo"
+
+// PeopleInfo returns the configuration of the people info API client
+func (c *Config) PeopleInfo() peopleinfo.Config {
+	return peopleinfo.Config{
+		BaseURL:          c.PeopleAPIURL,
+		Timeout:          c.PeopleAPITimeout,
+		Retries:          c.PeopleAPIRetries,
+		Backoff:          c.PeopleAPIBackoff,
+		MaxBackoff:       c.PeopleAPIMaxBackoff,
+		BreakerThreshold: c.PeopleAPIBreakerThreshold,
+		BreakerCooldown:  c.PeopleAPIBreakerCooldown,
+	}
+}
@@ -0,0 +1,12 @@
+package config
+
+import "github.com/kuromii5/time-tracker/internal/app/userimport"
+
+// UserImport returns the configuration of bulk imports of users
+func (c *Config) UserImport() userimport.Config {
+	return userimport.Config{
+		Concurrency: c.ImportConcurrency,
+		BatchSize:   c.ImportBatchSize,
+		MaxRows:     c.ImportMaxRows,
+	}
+}
@@ -0,0 +1,113 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/auth"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type APIKeyCreator interface {
+	CreateAPIKey(ctx context.Context, key models.APIKey, keyHash string) (int32, error)
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string `json:"name" example:"ci"`
+	UserID int32  `json:"user_id"`
+	Admin  bool   `json:"admin"`
+}
+
+type CreateAPIKeyResponse struct {
+	KeyID  int32  `json:"key_id"`
+	Key    string `json:"key"`
+	Prefix string `json:"prefix"`
+}
+
+// CreateAPIKey handles issuing an API key.
+// @Summary Issue an API key
+// @Description Issue a new API key. The key is shown only once, only its hash is stored.
+// @Description Keys bound to a user act as that user, admin keys can manage keys.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body CreateAPIKeyRequest true "Create API Key Request"
+// @Success 201 {object} CreateAPIKeyResponse "Issued key"
+// @Failure 400 {object} httperr.ErrResponse "Invalid request payload"
+// @Failure 404 {object} httperr.ErrResponse "User not found"
+// @Failure 500 {object} httperr.ErrResponse "Failed to issue key"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/api-keys [post]
+func CreateAPIKey(logger *slog.Logger, keyCreator APIKeyCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "CreateAPIKey"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			slog.Any("principal", auth.FromContext(r.Context())),
+		)
+
+		var req CreateAPIKeyRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			if errors.Is(err, io.EOF) {
+				log.Error("request body is empty")
+
+				render.Render(w, r, httperr.ErrInvalidRequest(errors.New("request body is empty")))
+				return
+			}
+			log.Error("failed to decode request body", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+		defer r.Body.Close()
+
+		if req.Name == "" {
+			log.Error("key name is empty")
+
+			render.Render(w, r, httperr.ErrInvalidRequest(errors.New("key name is required")))
+			return
+		}
+
+		key, prefix, hash, err := auth.NewAPIKey()
+		if err != nil {
+			log.Error("failed to generate key", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		keyID, err := keyCreator.CreateAPIKey(r.Context(), models.APIKey{
+			Name:   req.Name,
+			Prefix: prefix,
+			UserID: req.UserID,
+			Admin:  req.Admin,
+		}, hash)
+		if err != nil {
+			if errors.Is(err, repo.ErrUserNotFound) {
+				log.Warn("user not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+				return
+			}
+			log.Error("failed to create key", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("issued api key", slog.Int("key_id", int(keyID)), slog.Bool("admin", req.Admin))
+
+		resp := CreateAPIKeyResponse{KeyID: keyID, Key: key, Prefix: prefix}
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, resp)
+	}
+}
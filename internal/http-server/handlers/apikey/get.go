@@ -0,0 +1,63 @@
+package apikey
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/models"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type APIKeysGetter interface {
+	APIKeys(ctx context.Context) ([]models.APIKey, error)
+}
+
+type APIKeysResponse struct {
+	Keys []models.APIKey `json:"keys"`
+}
+
+// Render is used by chi/render to render the response.
+func (kr APIKeysResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// APIKeys handles listing API keys.
+// @Summary Get a list of API keys
+// @Description Retrieve all issued API keys, including revoked ones. Keys themselves are never returned.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Success 200 {object} APIKeysResponse "Successfully retrieved keys"
+// @Failure 500 {object} httperr.ErrResponse "Failed to get keys"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/api-keys [get]
+func APIKeys(logger *slog.Logger, keysGetter APIKeysGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "APIKeys"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		keys, err := keysGetter.APIKeys(r.Context())
+		if err != nil {
+			log.Error("failed to get api keys", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("fetched api keys", slog.Int("count", len(keys)))
+
+		resp := APIKeysResponse{Keys: keys}
+		if err := render.Render(w, r, resp); err != nil {
+			log.Error("failed to render response", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+		}
+	}
+}
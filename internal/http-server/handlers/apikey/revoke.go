@@ -0,0 +1,71 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/auth"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type APIKeyRevoker interface {
+	RevokeAPIKey(ctx context.Context, id int32) error
+}
+
+// RevokeAPIKey handles revoking an API key.
+// @Summary Revoke an API key
+// @Description Revoke an API key by ID. It stops working immediately and is kept in the list.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} httperr.ErrResponse "Invalid key ID"
+// @Failure 404 {object} httperr.ErrResponse "Active key not found"
+// @Failure 500 {object} httperr.ErrResponse "Failed to revoke key"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/api-keys/{id} [delete]
+func RevokeAPIKey(logger *slog.Logger, keyRevoker APIKeyRevoker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "RevokeAPIKey"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			slog.Any("principal", auth.FromContext(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		keyID, err := strconv.Atoi(idStr)
+		if err != nil {
+			log.Error("invalid key ID", slog.String("key_id", idStr), l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(errors.New("invalid key ID")))
+			return
+		}
+
+		if err := keyRevoker.RevokeAPIKey(r.Context(), int32(keyID)); err != nil {
+			if errors.Is(err, repo.ErrAPIKeyNotFound) {
+				log.Warn("api key not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+				return
+			}
+			log.Error("failed to revoke api key", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("revoked api key", slog.Int("key_id", keyID))
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,130 @@
+package auditlog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/utils"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type AuditEventsGetter interface {
+	AuditEvents(ctx context.Context, filter models.AuditFilter, settings models.Pagination) ([]models.AuditEvent, error)
+}
+
+type AuditEventsResponse struct {
+	Events []models.AuditEvent `json:"events"`
+}
+
+// Render is used by chi/render to render the response.
+func (ar AuditEventsResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+var entities = map[string]bool{
+	models.EntityUser:    true,
+	models.EntityWorklog: true,
+	models.EntityClient:  true,
+	models.EntityProject: true,
+	models.EntityAPIKey:  true,
+}
+
+// AuditEvents handles querying the audit log.
+// @Summary Get audit events
+// @Description Retrieve recorded changes, newest first. Actors are "api_key:<id>" or "jwt:<subject>" for requests and "system:..." for background jobs.
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param entity query string false "Entity" Enums(user, worklog, client, project, api_key)
+// @Param entity_id query int false "Entity ID"
+// @Param actor query string false "Actor"
+// @Param from query string false "Occurred at or after (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "Occurred before (RFC3339 or YYYY-MM-DD)"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {object} AuditEventsResponse "Successfully retrieved audit events"
+// @Failure 400 {object} httperr.ErrResponse "Invalid request"
+// @Failure 500 {object} httperr.ErrResponse "Failed to get audit events"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/audit-events [get]
+func AuditEvents(logger *slog.Logger, eventsGetter AuditEventsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "AuditEvents"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		from, err := utils.ParseQueryParamTimeIn(r, "from", time.UTC)
+		if err != nil {
+			log.Error("invalid date range", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+		to, err := utils.ParseQueryParamTimeIn(r, "to", time.UTC)
+		if err != nil {
+			log.Error("invalid date range", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		entityID, err := utils.ParseQueryParamID(r, "entity_id")
+		if err != nil {
+			log.Error("invalid entity ID", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		filter := models.AuditFilter{
+			Entity:   r.URL.Query().Get("entity"),
+			EntityID: entityID,
+			Actor:    r.URL.Query().Get("actor"),
+			From:     from,
+			To:       to,
+		}
+		if filter.Entity != "" && !entities[filter.Entity] {
+			err := fmt.Errorf("unknown entity: %q", filter.Entity)
+			log.Error("invalid entity", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		pagination := models.Pagination{
+			Limit:  utils.ParseQueryParamInt(r, "limit"),
+			Offset: utils.ParseQueryParamInt(r, "offset"),
+		}
+
+		log.Debug("received request",
+			slog.Any("filter", filter),
+			slog.Any("pagination", pagination),
+		)
+
+		events, err := eventsGetter.AuditEvents(r.Context(), filter, pagination)
+		if err != nil {
+			log.Error("failed to get audit events", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("fetched audit events", slog.Int("count", len(events)))
+
+		resp := AuditEventsResponse{Events: events}
+		if err := render.Render(w, r, resp); err != nil {
+			log.Error("failed to render response", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+		}
+	}
+}
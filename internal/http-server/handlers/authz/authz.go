@@ -0,0 +1,32 @@
+// Package authz renders the results of permission checks made by handlers.
+package authz
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/auth"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+// RenderErr renders a failed permission check. Checks of missing users and worklogs fail with not found.
+func RenderErr(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, auth.ErrForbidden):
+		log.Warn("permission denied", slog.Any("principal", auth.FromContext(r.Context())), l.Err(err))
+
+		render.Render(w, r, httperr.ErrForbidden(err))
+	case errors.Is(err, repo.ErrUserNotFound), errors.Is(err, repo.ErrWorklogNotFound):
+		log.Warn("permission check target not found", l.Err(err))
+
+		render.Render(w, r, httperr.ErrNotFound(err))
+	default:
+		log.Error("failed to check permissions", l.Err(err))
+
+		render.Render(w, r, httperr.ErrInternal(err))
+	}
+}
@@ -0,0 +1,8 @@
+package calendar
+
+import "context"
+
+// Authorizer checks the permissions of the request principal, see auth.Guard
+type Authorizer interface {
+	ReadUser(ctx context.Context, userID int32) error
+}
@@ -0,0 +1,181 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	"github.com/kuromii5/time-tracker/internal/utils"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	"github.com/kuromii5/time-tracker/pkg/ical"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type WorklogsGetter interface {
+	Worklogs(ctx context.Context, userID int32, filter models.WorklogFilter, settings models.Pagination) ([]models.Worklog, models.Page, error)
+}
+
+type TokenResolver interface {
+	UserByCalendarToken(ctx context.Context, tokenHash string) (int32, error)
+}
+
+// defaultRange is how far back the calendar goes if no range is given
+const defaultRange = 90 * 24 * time.Hour
+
+// UserCalendar handles exporting a user's worklogs as an iCalendar file.
+// @Summary Get worklogs as iCalendar
+// @Description Get worklogs of a user as an .ics file. Every worklog is an event, running worklogs are tentative and end now.
+// @Description Time format should be YYYY-MM-DDTHH:MM:SSZ (ISO 8601). The range defaults to the last 90 days.
+// @Tags calendar
+// @Produce text/calendar
+// @Param userID path int true "User ID"
+// @Param from query string false "Range start"
+// @Param to query string false "Range end"
+// @Success 200 {file} file "iCalendar file"
+// @Failure 400 {object} httperr.ErrResponse "Invalid user ID or range"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 500 {object} httperr.ErrResponse "Failed to get worklogs"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{userID}/worklogs.ics [get]
+func UserCalendar(logger *slog.Logger, worklogsGetter WorklogsGetter, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "UserCalendar"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+		if err != nil {
+			log.Error("invalid user ID", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		if err := authorizer.ReadUser(r.Context(), int32(userID)); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		writeCalendar(w, r, log, worklogsGetter, int32(userID))
+	}
+}
+
+// Feed handles the subscribable calendar feed.
+// @Summary Subscribable calendar feed
+// @Description Calendar feed of a user's worklogs for calendar apps, protected by the token issued for the user.
+// @Description Accepts the same range parameters as the .ics export.
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "Calendar token"
+// @Param from query string false "Range start"
+// @Param to query string false "Range end"
+// @Success 200 {file} file "iCalendar file"
+// @Failure 400 {object} httperr.ErrResponse "Invalid range"
+// @Failure 404 {object} httperr.ErrResponse "Unknown token"
+// @Failure 500 {object} httperr.ErrResponse "Failed to get worklogs"
+// @Router /calendar/{token}.ics [get]
+func Feed(logger *slog.Logger, tokenResolver TokenResolver, worklogsGetter WorklogsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "CalendarFeed"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, err := tokenResolver.UserByCalendarToken(r.Context(), utils.HashToken(chi.URLParam(r, "token")))
+		if err != nil {
+			if errors.Is(err, repo.ErrUserNotFound) {
+				log.Warn("unknown calendar token")
+
+				render.Render(w, r, httperr.ErrNotFound(errors.New("calendar not found")))
+				return
+			}
+			log.Error("failed to resolve calendar token", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		writeCalendar(w, r, log, worklogsGetter, userID)
+	}
+}
+
+func writeCalendar(w http.ResponseWriter, r *http.Request, log *slog.Logger, worklogsGetter WorklogsGetter, userID int32) {
+	now := time.Now()
+	from, err := utils.ParseQueryParamTimeIn(r, "from", time.UTC)
+	if err != nil {
+		log.Error("invalid date range", l.Err(err))
+
+		render.Render(w, r, httperr.ErrInvalidRequest(err))
+		return
+	}
+	if from.IsZero() {
+		from = now.Add(-defaultRange)
+	}
+	to, err := utils.ParseQueryParamTimeIn(r, "to", time.UTC)
+	if err != nil {
+		log.Error("invalid date range", l.Err(err))
+
+		render.Render(w, r, httperr.ErrInvalidRequest(err))
+		return
+	}
+	if to.IsZero() {
+		to = now
+	}
+	if !to.After(from) {
+		err := errors.New("to should be after from")
+		log.Error("invalid date range", l.Err(err))
+
+		render.Render(w, r, httperr.ErrInvalidRequest(err))
+		return
+	}
+
+	worklogs, _, err := worklogsGetter.Worklogs(r.Context(), userID, models.WorklogFilter{From: from, To: to}, models.Pagination{})
+	if err != nil {
+		log.Error("failed to get worklogs", l.Err(err))
+
+		render.Render(w, r, httperr.ErrInternal(err))
+		return
+	}
+
+	cal := ical.Calendar{
+		ProdID: "-//time-tracker//worklogs//EN",
+		Name:   fmt.Sprintf("Worklogs of user %d", userID),
+		Events: make([]ical.Event, 0, len(worklogs)),
+	}
+	for _, wl := range worklogs {
+		event := ical.Event{
+			UID:         fmt.Sprintf("worklog-%d@time-tracker", wl.ID),
+			Summary:     wl.Task,
+			Description: fmt.Sprintf("Duration: %s\nPaused: %s", utils.FormatDuration(wl.Duration), utils.FormatDuration(wl.Paused)),
+			Start:       wl.StartedAt,
+			End:         wl.FinishedAt,
+			Status:      ical.StatusConfirmed,
+		}
+		if wl.FinishedAt.IsZero() {
+			event.End = now
+			event.Status = ical.StatusTentative
+		}
+		cal.Events = append(cal.Events, event)
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="worklogs_%d.ics"`, userID))
+	if _, err := cal.WriteTo(w); err != nil {
+		log.Error("failed to write calendar", l.Err(err))
+		return
+	}
+
+	log.Info("calendar sent", slog.Int("user_id", int(userID)), slog.Int("events", len(cal.Events)))
+}
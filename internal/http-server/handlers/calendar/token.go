@@ -0,0 +1,96 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	"github.com/kuromii5/time-tracker/internal/utils"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type TokenSetter interface {
+	SetCalendarToken(ctx context.Context, userID int32, tokenHash string) error
+}
+
+type CreateTokenResponse struct {
+	Token   string `json:"token"`
+	FeedURL string `json:"feed_url"`
+}
+
+// CreateToken handles issuing a calendar feed token.
+// @Summary Issue a calendar feed token
+// @Description Issue a new secret token for the user's subscribable calendar feed. The previous token stops working.
+// @Description The token is shown only once.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param userID path int true "User ID"
+// @Success 201 {object} CreateTokenResponse "Token and feed URL"
+// @Failure 400 {object} httperr.ErrResponse "Invalid user ID"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "User not found"
+// @Failure 500 {object} httperr.ErrResponse "Failed to issue token"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{userID}/calendar-token [post]
+func CreateToken(logger *slog.Logger, tokenSetter TokenSetter, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "CreateCalendarToken"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+		if err != nil {
+			log.Error("invalid user ID", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		if err := authorizer.ReadUser(r.Context(), int32(userID)); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		token, err := utils.NewToken()
+		if err != nil {
+			log.Error("failed to generate token", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		if err := tokenSetter.SetCalendarToken(r.Context(), int32(userID), utils.HashToken(token)); err != nil {
+			if errors.Is(err, repo.ErrUserNotFound) {
+				log.Warn("user not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+				return
+			}
+			log.Error("failed to set calendar token", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("calendar token issued", slog.Int("user_id", userID))
+
+		resp := CreateTokenResponse{
+			Token:   token,
+			FeedURL: fmt.Sprintf("/calendar/%s.ics", token),
+		}
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, resp)
+	}
+}
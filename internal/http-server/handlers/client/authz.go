@@ -0,0 +1,8 @@
+package client
+
+import "context"
+
+// Authorizer checks the permissions of the request principal, see auth.Guard
+type Authorizer interface {
+	ManageProjects(ctx context.Context) error
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type ClientCreator interface {
+	CreateClient(ctx context.Context, client models.Client) (int32, error)
+}
+
+type CreateClientRequest struct {
+	Name string `json:"name"`
+}
+
+type CreateClientResponse struct {
+	ClientID int32 `json:"client_id"`
+}
+
+// CreateClient handles the creation of a new client.
+// @Summary Create a new client
+// @Description Create a new client, whose projects worklogs can be grouped by
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Param request body CreateClientRequest true "Create Client Request"
+// @Success 201 {object} CreateClientResponse "Successfully created client"
+// @Failure 400 {object} httperr.ErrResponse "Invalid request payload"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 409 {object} httperr.ErrResponse "Client already exists"
+// @Failure 500 {object} httperr.ErrResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /clients [post]
+func CreateClient(logger *slog.Logger, clientCreator ClientCreator, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "CreateClient"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		if err := authorizer.ManageProjects(r.Context()); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		var req CreateClientRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			if errors.Is(err, io.EOF) {
+				log.Error("request body is empty")
+
+				render.Render(w, r, httperr.ErrInvalidRequest(errors.New("request body is empty")))
+				return
+			}
+			log.Error("failed to decode request body", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+		defer r.Body.Close()
+
+		if req.Name == "" {
+			log.Error("client name is empty")
+
+			render.Render(w, r, httperr.ErrInvalidRequest(errors.New("client name is required")))
+			return
+		}
+
+		clientID, err := clientCreator.CreateClient(r.Context(), models.Client{Name: req.Name})
+		if err != nil {
+			if errors.Is(err, repo.ErrClientDuplicate) {
+				log.Warn("client already exists", l.Err(err))
+
+				render.Render(w, r, httperr.ErrConflict(err))
+				return
+			}
+			log.Error("failed to create client", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("created client", slog.Int("client_id", int(clientID)))
+
+		resp := CreateClientResponse{ClientID: clientID}
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, resp)
+	}
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type ClientDeleter interface {
+	DeleteClient(ctx context.Context, id int32) error
+}
+
+// DeleteClient handles the deletion of a client.
+// @Summary Delete a client
+// @Description Delete a client by ID. Its projects are kept without a client
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Param id path int true "Client ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} httperr.ErrResponse "Invalid client ID"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "Client not found"
+// @Failure 500 {object} httperr.ErrResponse "Failed to delete client"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /clients/{id} [delete]
+func DeleteClient(logger *slog.Logger, clientDeleter ClientDeleter, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "DeleteClient"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		if err := authorizer.ManageProjects(r.Context()); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		idStr := chi.URLParam(r, "id")
+		clientID, err := strconv.Atoi(idStr)
+		if err != nil {
+			log.Error("invalid client ID", slog.String("client_id", idStr), l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(errors.New("invalid client ID")))
+			return
+		}
+
+		if err := clientDeleter.DeleteClient(r.Context(), int32(clientID)); err != nil {
+			if errors.Is(err, repo.ErrClientNotFound) {
+				log.Warn("client not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+				return
+			}
+			log.Error("failed to delete client", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("deleted client", slog.Int("client_id", clientID))
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/models"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type ClientsGetter interface {
+	Clients(ctx context.Context) ([]models.Client, error)
+}
+
+type ClientsResponse struct {
+	Clients []models.Client `json:"clients"`
+}
+
+// Render is used by chi/render to render the response.
+func (cr ClientsResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// Clients handles retrieving a list of clients.
+// @Summary Get a list of clients
+// @Description Retrieve all clients sorted by name
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Success 200 {object} ClientsResponse "Successfully retrieved clients"
+// @Failure 500 {object} httperr.ErrResponse "Failed to get clients"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /clients [get]
+func Clients(logger *slog.Logger, clientsGetter ClientsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "Clients"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		clients, err := clientsGetter.Clients(r.Context())
+		if err != nil {
+			log.Error("failed to get clients", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("fetched clients", slog.Int("count", len(clients)))
+
+		resp := ClientsResponse{Clients: clients}
+		if err := render.Render(w, r, resp); err != nil {
+			log.Error("failed to render response", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+		}
+	}
+}
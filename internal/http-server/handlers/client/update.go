@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type ClientUpdater interface {
+	UpdateClient(ctx context.Context, client models.Client) error
+}
+
+type UpdateClientRequest struct {
+	Name string `json:"name"`
+}
+
+// UpdateClient handles updating an existing client.
+// @Summary Update an existing client
+// @Description Rename a client
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Param id path int true "Client ID"
+// @Param request body UpdateClientRequest true "Update Client Request"
+// @Success 204 "Successfully updated client"
+// @Failure 400 {object} httperr.ErrResponse "Invalid request payload"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "Client not found"
+// @Failure 409 {object} httperr.ErrResponse "Client already exists"
+// @Failure 500 {object} httperr.ErrResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /clients/{id} [patch]
+func UpdateClient(logger *slog.Logger, clientUpdater ClientUpdater, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "UpdateClient"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		if err := authorizer.ManageProjects(r.Context()); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		var req UpdateClientRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		if req.Name == "" {
+			log.Error("client name is empty")
+
+			render.Render(w, r, httperr.ErrInvalidRequest(errors.New("client name is required")))
+			return
+		}
+
+		idStr := chi.URLParam(r, "id")
+		clientID, err := strconv.Atoi(idStr)
+		if err != nil {
+			log.Error("invalid client ID", slog.String("client_id", idStr), l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(errors.New("invalid client ID")))
+			return
+		}
+
+		client := models.Client{ID: int32(clientID), Name: req.Name}
+		if err := clientUpdater.UpdateClient(r.Context(), client); err != nil {
+			switch {
+			case errors.Is(err, repo.ErrClientNotFound):
+				log.Warn("client not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+			case errors.Is(err, repo.ErrClientDuplicate):
+				log.Warn("client already exists", l.Err(err))
+
+				render.Render(w, r, httperr.ErrConflict(err))
+			default:
+				log.Error("failed to update client", l.Err(err))
+
+				render.Render(w, r, httperr.ErrInternal(err))
+			}
+			return
+		}
+
+		log.Info("updated client", slog.Int("client_id", clientID))
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
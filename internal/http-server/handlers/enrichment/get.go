@@ -0,0 +1,90 @@
+package enrichment
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/utils"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type JobsGetter interface {
+	EnrichmentJobs(ctx context.Context, status string, settings models.Pagination) ([]models.EnrichmentJob, error)
+}
+
+type JobsResponse struct {
+	Jobs []models.EnrichmentJob `json:"jobs"`
+}
+
+// Render is used by chi/render to render the response.
+func (jr JobsResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+var statuses = map[string]bool{
+	models.JobQueued:  true,
+	models.JobRunning: true,
+	models.JobDone:    true,
+	models.JobDead:    true,
+}
+
+// Jobs handles listing enrichment jobs.
+// @Summary Get enrichment jobs
+// @Description Retrieve the jobs that fetch people info for new users, newest first. Dead jobs ran out of attempts or failed for good and wait to be retried.
+// @Tags enrichment
+// @Accept json
+// @Produce json
+// @Param status query string false "Status" Enums(queued, running, done, dead)
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {object} JobsResponse "Successfully retrieved jobs"
+// @Failure 400 {object} httperr.ErrResponse "Invalid request"
+// @Failure 500 {object} httperr.ErrResponse "Failed to get jobs"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/enrichment-jobs [get]
+func Jobs(logger *slog.Logger, jobsGetter JobsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "EnrichmentJobs"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		status := r.URL.Query().Get("status")
+		if status != "" && !statuses[status] {
+			err := fmt.Errorf("unknown status: %q", status)
+			log.Error("invalid status", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		pagination := models.Pagination{
+			Limit:  utils.ParseQueryParamInt(r, "limit"),
+			Offset: utils.ParseQueryParamInt(r, "offset"),
+		}
+
+		jobs, err := jobsGetter.EnrichmentJobs(r.Context(), status, pagination)
+		if err != nil {
+			log.Error("failed to get enrichment jobs", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("fetched enrichment jobs", slog.Int("count", len(jobs)))
+
+		resp := JobsResponse{Jobs: jobs}
+		if err := render.Render(w, r, resp); err != nil {
+			log.Error("failed to render response", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type JobRetrier interface {
+	RetryEnrichmentJob(ctx context.Context, id int64) (models.EnrichmentJob, error)
+}
+
+// RetryJob handles retrying a dead enrichment job.
+// @Summary Retry a dead enrichment job
+// @Description Queue a dead-lettered job again with fresh attempts. Its user is put back to pending_enrichment.
+// @Tags enrichment
+// @Accept json
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 200 {object} models.EnrichmentJob "Requeued job"
+// @Failure 400 {object} httperr.ErrResponse "Invalid job ID"
+// @Failure 404 {object} httperr.ErrResponse "Dead job not found"
+// @Failure 500 {object} httperr.ErrResponse "Failed to retry job"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/enrichment-jobs/{id}/retry [post]
+func RetryJob(logger *slog.Logger, jobRetrier JobRetrier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "RetryEnrichmentJob"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		jobID, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			log.Error("invalid job ID", slog.String("job_id", idStr), l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(errors.New("invalid job ID")))
+			return
+		}
+
+		job, err := jobRetrier.RetryEnrichmentJob(r.Context(), jobID)
+		if err != nil {
+			if errors.Is(err, repo.ErrEnrichmentJobNotFound) {
+				log.Warn("dead enrichment job not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+				return
+			}
+			log.Error("failed to retry enrichment job", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("requeued enrichment job", slog.Int64("job_id", jobID))
+
+		render.JSON(w, r, job)
+	}
+}
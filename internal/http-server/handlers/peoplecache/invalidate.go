@@ -0,0 +1,72 @@
+package peoplecache
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/utils"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type CacheInvalidator interface {
+	Invalidate(ctx context.Context, passport models.Passport) (int, error)
+	Clear(ctx context.Context) (int, error)
+}
+
+type InvalidateResponse struct {
+	Invalidated int `json:"invalidated"`
+}
+
+// Invalidate handles removing cached answers of the people info API.
+// @Summary Invalidate cached people info
+// @Description Remove the cached answer for a passport, or all cached answers if no passport is given. The next lookup asks the people info API again.
+// @Tags people-info
+// @Accept json
+// @Produce json
+// @Param passportNumber query string false "Passport serie and number, e.g. 1234 567890"
+// @Success 200 {object} InvalidateResponse "Number of removed entries"
+// @Failure 400 {object} httperr.ErrResponse "Invalid passport"
+// @Failure 500 {object} httperr.ErrResponse "Failed to invalidate cache"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/people-info-cache [delete]
+func Invalidate(logger *slog.Logger, invalidator CacheInvalidator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "InvalidatePeopleInfo"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var (
+			count int
+			err   error
+		)
+		if r.URL.Query().Has("passportNumber") {
+			passport, perr := utils.ParsePassportData(r.URL.Query().Get("passportNumber"))
+			if perr != nil {
+				log.Error("failed to parse passport data", l.Err(perr))
+
+				render.Render(w, r, httperr.ErrInvalidRequest(perr))
+				return
+			}
+			count, err = invalidator.Invalidate(r.Context(), passport)
+		} else {
+			count, err = invalidator.Clear(r.Context())
+		}
+		if err != nil {
+			log.Error("failed to invalidate people info cache", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("invalidated people info cache", slog.Int("count", count))
+
+		render.JSON(w, r, InvalidateResponse{Invalidated: count})
+	}
+}
@@ -0,0 +1,108 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type ProjectCreator interface {
+	CreateProject(ctx context.Context, project models.Project) (int32, error)
+}
+
+type CreateProjectRequest struct {
+	Name     string `json:"name"`
+	ClientID int32  `json:"client_id"`
+}
+
+type CreateProjectResponse struct {
+	ProjectID int32 `json:"project_id"`
+}
+
+// CreateProject handles the creation of a new project.
+// @Summary Create a new project
+// @Description Create a new project, optionally owned by a client
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param request body CreateProjectRequest true "Create Project Request"
+// @Success 201 {object} CreateProjectResponse "Successfully created project"
+// @Failure 400 {object} httperr.ErrResponse "Invalid request payload"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "Client not found"
+// @Failure 409 {object} httperr.ErrResponse "Project already exists"
+// @Failure 500 {object} httperr.ErrResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /projects [post]
+func CreateProject(logger *slog.Logger, projectCreator ProjectCreator, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "CreateProject"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		if err := authorizer.ManageProjects(r.Context()); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		var req CreateProjectRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			if errors.Is(err, io.EOF) {
+				log.Error("request body is empty")
+
+				render.Render(w, r, httperr.ErrInvalidRequest(errors.New("request body is empty")))
+				return
+			}
+			log.Error("failed to decode request body", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+		defer r.Body.Close()
+
+		if req.Name == "" {
+			log.Error("project name is empty")
+
+			render.Render(w, r, httperr.ErrInvalidRequest(errors.New("project name is required")))
+			return
+		}
+
+		project := models.Project{Name: req.Name, ClientID: req.ClientID}
+		projectID, err := projectCreator.CreateProject(r.Context(), project)
+		if err != nil {
+			switch {
+			case errors.Is(err, repo.ErrClientNotFound):
+				log.Warn("client not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+			case errors.Is(err, repo.ErrProjectDuplicate):
+				log.Warn("project already exists", l.Err(err))
+
+				render.Render(w, r, httperr.ErrConflict(err))
+			default:
+				log.Error("failed to create project", l.Err(err))
+
+				render.Render(w, r, httperr.ErrInternal(err))
+			}
+			return
+		}
+
+		log.Info("created project", slog.Int("project_id", int(projectID)))
+
+		resp := CreateProjectResponse{ProjectID: projectID}
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, resp)
+	}
+}
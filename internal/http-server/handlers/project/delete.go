@@ -0,0 +1,76 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type ProjectDeleter interface {
+	DeleteProject(ctx context.Context, id int32) error
+}
+
+// DeleteProject handles the deletion of a project.
+// @Summary Delete a project
+// @Description Delete a project by ID. Its worklogs are kept without a project
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} httperr.ErrResponse "Invalid project ID"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "Project not found"
+// @Failure 500 {object} httperr.ErrResponse "Failed to delete project"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /projects/{id} [delete]
+func DeleteProject(logger *slog.Logger, projectDeleter ProjectDeleter, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "DeleteProject"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		if err := authorizer.ManageProjects(r.Context()); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		idStr := chi.URLParam(r, "id")
+		projectID, err := strconv.Atoi(idStr)
+		if err != nil {
+			log.Error("invalid project ID", slog.String("project_id", idStr), l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(errors.New("invalid project ID")))
+			return
+		}
+
+		if err := projectDeleter.DeleteProject(r.Context(), int32(projectID)); err != nil {
+			if errors.Is(err, repo.ErrProjectNotFound) {
+				log.Warn("project not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+				return
+			}
+			log.Error("failed to delete project", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("deleted project", slog.Int("project_id", projectID))
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
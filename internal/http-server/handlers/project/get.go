@@ -0,0 +1,74 @@
+package project
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/utils"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type ProjectsGetter interface {
+	Projects(ctx context.Context, clientID int32) ([]models.Project, error)
+}
+
+type ProjectsResponse struct {
+	Projects []models.Project `json:"projects"`
+}
+
+// Render is used by chi/render to render the response.
+func (pr ProjectsResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// Projects handles retrieving a list of projects.
+// @Summary Get a list of projects
+// @Description Retrieve all projects sorted by name, optionally only the projects of one client
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param client_id query int false "Client ID"
+// @Success 200 {object} ProjectsResponse "Successfully retrieved projects"
+// @Failure 400 {object} httperr.ErrResponse "Invalid client ID"
+// @Failure 500 {object} httperr.ErrResponse "Failed to get projects"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /projects [get]
+func Projects(logger *slog.Logger, projectsGetter ProjectsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "Projects"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		clientID, err := utils.ParseQueryParamID(r, "client_id")
+		if err != nil {
+			log.Error("invalid client ID", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		projects, err := projectsGetter.Projects(r.Context(), clientID)
+		if err != nil {
+			log.Error("failed to get projects", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("fetched projects", slog.Int("count", len(projects)))
+
+		resp := ProjectsResponse{Projects: projects}
+		if err := render.Render(w, r, resp); err != nil {
+			log.Error("failed to render response", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+		}
+	}
+}
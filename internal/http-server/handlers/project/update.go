@@ -0,0 +1,98 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type ProjectUpdater interface {
+	UpdateProject(ctx context.Context, project models.Project) error
+}
+
+type UpdateProjectRequest struct {
+	Name     string `json:"name"`
+	ClientID int32  `json:"client_id"`
+}
+
+// UpdateProject handles updating an existing project.
+// @Summary Update an existing project
+// @Description Rename a project or move it to another client. Empty fields are left unchanged
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param request body UpdateProjectRequest true "Update Project Request"
+// @Success 204 "Successfully updated project"
+// @Failure 400 {object} httperr.ErrResponse "Invalid request payload"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "Project or client not found"
+// @Failure 409 {object} httperr.ErrResponse "Project already exists"
+// @Failure 500 {object} httperr.ErrResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /projects/{id} [patch]
+func UpdateProject(logger *slog.Logger, projectUpdater ProjectUpdater, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "UpdateProject"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		if err := authorizer.ManageProjects(r.Context()); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		var req UpdateProjectRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		idStr := chi.URLParam(r, "id")
+		projectID, err := strconv.Atoi(idStr)
+		if err != nil {
+			log.Error("invalid project ID", slog.String("project_id", idStr), l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(errors.New("invalid project ID")))
+			return
+		}
+
+		project := models.Project{ID: int32(projectID), Name: req.Name, ClientID: req.ClientID}
+		if err := projectUpdater.UpdateProject(r.Context(), project); err != nil {
+			switch {
+			case errors.Is(err, repo.ErrProjectNotFound), errors.Is(err, repo.ErrClientNotFound):
+				log.Warn("project or client not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+			case errors.Is(err, repo.ErrProjectDuplicate):
+				log.Warn("project already exists", l.Err(err))
+
+				render.Render(w, r, httperr.ErrConflict(err))
+			default:
+				log.Error("failed to update project", l.Err(err))
+
+				render.Render(w, r, httperr.ErrInternal(err))
+			}
+			return
+		}
+
+		log.Info("updated project", slog.Int("project_id", projectID))
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
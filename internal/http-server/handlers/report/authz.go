@@ -0,0 +1,9 @@
+package report
+
+import "context"
+
+// Authorizer checks the permissions of the request principal, see auth.Guard
+type Authorizer interface {
+	Admin(ctx context.Context) error
+	ReadUser(ctx context.Context, userID int32) error
+}
@@ -0,0 +1,209 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/utils"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type TimeReporter interface {
+	TimeReport(ctx context.Context, filter models.ReportFilter) ([]models.ReportRow, error)
+}
+
+type TimeReportRow struct {
+	UserID   int32  `json:"user_id,omitempty"`
+	Task     string `json:"task,omitempty"`
+	Day      string `json:"day,omitempty"`
+	Week     string `json:"week,omitempty"`
+	Month    string `json:"month,omitempty"`
+	Duration string `json:"duration"`
+	Seconds  int64  `json:"seconds"`
+	Worklogs int    `json:"worklogs"`
+}
+
+type TimeReportResponse struct {
+	From    time.Time       `json:"from"`
+	To      time.Time       `json:"to"`
+	GroupBy []string        `json:"group_by"`
+	Rows    []TimeReportRow `json:"rows"`
+}
+
+// TimeReport handles building aggregated time reports.
+// @Summary Get a time report
+// @Description Sum tracked time within a date range, grouped by any of user, task, day, week (ISO) and month.
+// @Description Paused time is not counted, running worklogs are counted up to now.
+// @Description Time format should be YYYY-MM-DDTHH:MM:SSZ (ISO 8601), or YYYY-MM-DD for midnight in the report time zone (UTC by default).
+// @Description The range defaults to everything up to now.
+// @Description Days, weeks and months are counted in the tz time zone, or in every user's own time zone if tz is not set.
+// @Description Time crossing midnight is split between the days, weeks and months it falls into, a worklog is counted in each of them.
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param group_by query string false "Comma-separated dimensions: user, task, day, week, month"
+// @Param from query string false "Range start (inclusive)"
+// @Param to query string false "Range end (exclusive)"
+// @Param user_id query int false "User ID, required unless the caller is an admin"
+// @Param project_id query int false "Project ID"
+// @Param client_id query int false "Client ID"
+// @Param task query string false "Task"
+// @Param tz query string false "IANA time zone, e.g. Europe/Moscow"
+// @Success 200 {object} TimeReportResponse "Time report"
+// @Failure 400 {object} httperr.ErrResponse "Invalid report parameters"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 500 {object} httperr.ErrResponse "Failed to build report"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /reports/time [get]
+func TimeReport(logger *slog.Logger, timeReporter TimeReporter, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "TimeReport"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		tz, err := utils.ParseQueryParamLocation(r, "tz")
+		if err != nil {
+			log.Error("invalid time zone", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+		loc := time.UTC
+		if tz != nil {
+			loc = tz
+		}
+
+		from, err := utils.ParseQueryParamTimeIn(r, "from", loc)
+		if err != nil {
+			log.Error("invalid date range", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+		to, err := utils.ParseQueryParamTimeIn(r, "to", loc)
+		if err != nil {
+			log.Error("invalid date range", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		userID, err := utils.ParseQueryParamID(r, "user_id")
+		if err != nil {
+			log.Error("invalid filter", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+		projectID, err := utils.ParseQueryParamID(r, "project_id")
+		if err != nil {
+			log.Error("invalid filter", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+		clientID, err := utils.ParseQueryParamID(r, "client_id")
+		if err != nil {
+			log.Error("invalid filter", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		filter := models.ReportFilter{
+			From:      from,
+			To:        to,
+			UserID:    userID,
+			ProjectID: projectID,
+			ClientID:  clientID,
+			Task:      r.URL.Query().Get("task"),
+		}
+		if tz != nil {
+			filter.Timezone = tz.String()
+		}
+		if filter.To.IsZero() {
+			filter.To = time.Now()
+		}
+		if !filter.To.After(filter.From) {
+			err := errors.New("to should be after from")
+			log.Error("invalid date range", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		// reading a user's time is checked per user, everyone's time is only for admins
+		if filter.UserID != 0 {
+			err = authorizer.ReadUser(r.Context(), filter.UserID)
+		} else {
+			err = authorizer.Admin(r.Context())
+		}
+		if err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		seen := make(map[string]bool)
+		if groupBy := r.URL.Query().Get("group_by"); groupBy != "" {
+			for _, dim := range strings.Split(groupBy, ",") {
+				dim = strings.TrimSpace(dim)
+				if _, ok := utils.ReportDimensions[dim]; !ok {
+					err := fmt.Errorf("unknown group_by dimension: %q", dim)
+					log.Error("invalid group_by", l.Err(err))
+
+					render.Render(w, r, httperr.ErrInvalidRequest(err))
+					return
+				}
+				if !seen[dim] {
+					seen[dim] = true
+					filter.GroupBy = append(filter.GroupBy, dim)
+				}
+			}
+		}
+
+		log.Debug("received request", slog.Any("filter", filter))
+
+		report, err := timeReporter.TimeReport(r.Context(), filter)
+		if err != nil {
+			log.Error("failed to build time report", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		resp := TimeReportResponse{
+			From:    filter.From,
+			To:      filter.To,
+			GroupBy: filter.GroupBy,
+			Rows:    make([]TimeReportRow, 0, len(report)),
+		}
+		for _, row := range report {
+			resp.Rows = append(resp.Rows, TimeReportRow{
+				UserID:   row.UserID,
+				Task:     row.Task,
+				Day:      row.Day,
+				Week:     row.Week,
+				Month:    row.Month,
+				Duration: utils.FormatDuration(row.Duration),
+				Seconds:  int64(row.Duration.Seconds()),
+				Worklogs: row.Worklogs,
+			})
+		}
+
+		log.Info("time report built successfully", slog.Int("rows", len(report)))
+
+		render.JSON(w, r, resp)
+	}
+}
@@ -0,0 +1,15 @@
+package user
+
+import (
+	"context"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+)
+
+// Authorizer checks the permissions of the request principal, see auth.Guard
+type Authorizer interface {
+	Admin(ctx context.Context) error
+	ListUsers(ctx context.Context) (teamOf int32, err error)
+	ReadUser(ctx context.Context, userID int32) error
+	UpdateUser(ctx context.Context, user models.User) error
+}
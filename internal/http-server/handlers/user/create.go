@@ -5,51 +5,64 @@ package user
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
 	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
 	"github.com/kuromii5/time-tracker/internal/utils"
 	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
 	l "github.com/kuromii5/time-tracker/pkg/logger"
 )
 
 type UserCreator interface {
-	CreateUser(ctx context.Context, user models.User) (int32, error)
+	CreateUserForEnrichment(ctx context.Context, passport models.Passport, callbackURL string) (int32, error)
 }
 
 type CreateUserRequest struct {
 	PassportNumber string `json:"passportNumber"`
+	// CallbackURL is notified with a POST of models.EnrichmentNotification once the user is enriched or enrichment failed
+	CallbackURL string `json:"callback_url,omitempty"`
 }
 type CreateUserResponse struct {
-	UserID int32 `json:"user_id"`
+	UserID           int32  `json:"user_id"`
+	EnrichmentStatus string `json:"enrichment_status"`
 }
 
 // CreateUser handles the creation of a new user.
 // @Summary Create a new user
-// @Description Create a new user with provided passport number and fetch additional data from an external API
+// @Description Create a new user with provided passport number. The user is created in the pending_enrichment status and the name and address are fetched from an external API in the background. Poll the enrichment status at the Location, or pass a callback_url on a public host to be notified.
 // @Tags users
 // @Accept json
 // @Produce json
-// @Param extAPIPort query int true "External API Port" default(8081)
 // @Param request body CreateUserRequest true "Create User Request"
-// @Success 201 {object} CreateUserResponse "Successfully created user"
+// @Success 201 {object} CreateUserResponse "Successfully created user, pending enrichment"
 // @Failure 400 {object} httperr.ErrResponse "Invalid request payload"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 409 {object} httperr.ErrResponse "User with such passport already exists"
 // @Failure 500 {object} httperr.ErrResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /users [post]
-func CreateUser(logger *slog.Logger, userCreator UserCreator, extAPIPort int) http.HandlerFunc {
+func CreateUser(logger *slog.Logger, userCreator UserCreator, authorizer Authorizer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log := logger.With(
 			slog.String("handler", "CreateUser"),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
+		if err := authorizer.Admin(r.Context()); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
 		var req CreateUserRequest
 		if err := render.DecodeJSON(r.Body, &req); err != nil {
 			if errors.Is(err, io.EOF) {
@@ -65,8 +78,6 @@ func CreateUser(logger *slog.Logger, userCreator UserCreator, extAPIPort int) ht
 		}
 		defer r.Body.Close()
 
-		log.Debug("passport data", slog.String("passportNumber", req.PassportNumber))
-
 		passport, err := utils.ParsePassportData(req.PassportNumber)
 		if err != nil {
 			log.Error("failed to parse passport data", l.Err(err))
@@ -75,24 +86,23 @@ func CreateUser(logger *slog.Logger, userCreator UserCreator, extAPIPort int) ht
 			return
 		}
 
-		// Fetch people info from external API
-		people, err := fetchPeopleInfo(passport.Serie, passport.Number, extAPIPort)
-		if err != nil {
-			log.Error("failed to fetch people info", l.Err(err))
+		if req.CallbackURL != "" {
+			if err := validateCallbackURL(r.Context(), req.CallbackURL); err != nil {
+				log.Error("invalid callback url", l.Err(err))
 
-			render.Render(w, r, httperr.ErrInternal(err))
-			return
+				render.Render(w, r, httperr.ErrInvalidRequest(err))
+				return
+			}
 		}
 
-		// Now you have `people` containing the data retrieved from the external API
-		log.Debug("fetched people info", slog.Any("people", people))
-
-		user := models.User{
-			People:   people,
-			Passport: passport,
-		}
-		userId, err := userCreator.CreateUser(r.Context(), user)
+		userId, err := userCreator.CreateUserForEnrichment(r.Context(), passport, req.CallbackURL)
 		if err != nil {
+			if errors.Is(err, repo.ErrPassportDuplicate) {
+				log.Warn("user with such passport already exists", l.Err(err))
+
+				render.Render(w, r, httperr.ErrConflict(err))
+				return
+			}
 			log.Error("failed to create user", l.Err(err))
 
 			render.Render(w, r, httperr.ErrInternal(err))
@@ -101,30 +111,25 @@ func CreateUser(logger *slog.Logger, userCreator UserCreator, extAPIPort int) ht
 
 		log.Info("created user", slog.Int("user_id", int(userId)))
 
-		resp := CreateUserResponse{UserID: userId}
+		resp := CreateUserResponse{UserID: userId, EnrichmentStatus: models.EnrichmentPending}
+		w.Header().Set("Location", fmt.Sprintf("/users/%d/enrichment", userId))
 		render.Status(r, http.StatusCreated)
 		render.JSON(w, r, resp)
 	}
 }
 
-// Making call to external API to fetch data for user who matches the given passport data
-func fetchPeopleInfo(passportSerie, passportNumber string, extAPIPort int) (models.People, error) {
-	url := fmt.Sprintf("http://localhost:%d/info?passportSerie=%s&passportNumber=%s", extAPIPort, passportSerie, passportNumber)
-
-	resp, err := http.Get(url)
+// validateCallbackURL accepts absolute http and https URLs of public hosts, so that callbacks
+// can't be pointed at the tracker's own network. The enricher checks the address again when it connects.
+func validateCallbackURL(ctx context.Context, callbackURL string) error {
+	u, err := url.Parse(callbackURL)
 	if err != nil {
-		return models.People{}, fmt.Errorf("failed to fetch people info: %v", err)
+		return fmt.Errorf("invalid callback_url: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return models.People{}, fmt.Errorf("unexpected status code: %v", resp.Status)
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("callback_url should be an absolute http or https url")
 	}
-
-	var people models.People
-	if err := json.NewDecoder(resp.Body).Decode(&people); err != nil {
-		return models.People{}, fmt.Errorf("failed to decode response body: %v", err)
+	if err := utils.CheckPublicHost(ctx, u.Hostname()); err != nil {
+		return fmt.Errorf("invalid callback_url: %w", err)
 	}
-
-	return people, nil
+	return nil
 }
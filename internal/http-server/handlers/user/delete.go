@@ -13,6 +13,8 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/repo"
 	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
 	l "github.com/kuromii5/time-tracker/pkg/logger"
 )
@@ -23,16 +25,20 @@ type UserDeleter interface {
 
 // DeleteUser handles the deletion of a user.
 // @Summary Delete a user
-// @Description Delete a user by ID
+// @Description Soft-delete a user by ID. The user and their worklogs are kept until they are restored or the retention period runs out.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 204 "No Content"
 // @Failure 400 {object} httperr.ErrResponse "Invalid user ID"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "User not found"
 // @Failure 500 {object} httperr.ErrResponse "Failed to delete user"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /users/{id} [delete]
-func DeleteUser(logger *slog.Logger, userDeleter UserDeleter) http.HandlerFunc {
+func DeleteUser(logger *slog.Logger, userDeleter UserDeleter, authorizer Authorizer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log := logger.With(
 			slog.String("handler", "DeleteUser"),
@@ -49,8 +55,19 @@ func DeleteUser(logger *slog.Logger, userDeleter UserDeleter) http.HandlerFunc {
 			return
 		}
 
+		if err := authorizer.Admin(r.Context()); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
 		// Delete user from the database
 		if err := userDeleter.DeleteUser(r.Context(), int32(userId)); err != nil {
+			if errors.Is(err, repo.ErrUserNotFound) {
+				log.Warn("user not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+				return
+			}
 			log.Error("failed to delete user", l.Err(err))
 
 			render.Render(w, r, httperr.ErrInternal(err))
@@ -0,0 +1,76 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type EnrichmentGetter interface {
+	Enrichment(ctx context.Context, userID int32) (models.Enrichment, error)
+}
+
+// Enrichment handles polling the enrichment status of a user.
+// @Summary Get enrichment status of a user
+// @Description Get whether the user's name and address were fetched yet: pending_enrichment, enriched or enrichment_failed, with the latest enrichment job.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.Enrichment "Enrichment status"
+// @Failure 400 {object} httperr.ErrResponse "Invalid user ID"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "User not found"
+// @Failure 500 {object} httperr.ErrResponse "Failed to get enrichment status"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/enrichment [get]
+func Enrichment(logger *slog.Logger, enrichmentGetter EnrichmentGetter, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "Enrichment"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		userId, err := strconv.Atoi(idStr)
+		if err != nil {
+			log.Error("invalid user ID", slog.String("user_id", idStr), l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(errors.New("invalid user ID")))
+			return
+		}
+
+		if err := authorizer.ReadUser(r.Context(), int32(userId)); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		enrichment, err := enrichmentGetter.Enrichment(r.Context(), int32(userId))
+		if err != nil {
+			if errors.Is(err, repo.ErrUserNotFound) {
+				log.Warn("user not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+				return
+			}
+			log.Error("failed to get enrichment status", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		render.JSON(w, r, enrichment)
+	}
+}
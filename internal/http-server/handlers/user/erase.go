@@ -0,0 +1,95 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type UserEraser interface {
+	EraseUser(ctx context.Context, id int32) (models.Passport, error)
+}
+
+type PeopleInfoInvalidator interface {
+	Invalidate(ctx context.Context, passport models.Passport) (int, error)
+}
+
+// EraseUser handles erasing the personal data of a user.
+// @Summary Erase personal data of a user
+// @Description Anonymize a user by ID: the passport, name and address are cleared, also from the audit log, and the user's API keys are revoked. Worklogs are kept for aggregate reports. Erasure can't be undone and is recorded in the audit log.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} httperr.ErrResponse "Invalid user ID"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "User not found"
+// @Failure 409 {object} httperr.ErrResponse "User was already erased"
+// @Failure 500 {object} httperr.ErrResponse "Failed to erase user"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/erase [post]
+func EraseUser(logger *slog.Logger, userEraser UserEraser, peopleInfo PeopleInfoInvalidator, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "EraseUser"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		userId, err := strconv.Atoi(idStr)
+		if err != nil {
+			log.Error("invalid user ID", slog.String("user_id", idStr), l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(errors.New("invalid user ID")))
+			return
+		}
+
+		if err := authorizer.Admin(r.Context()); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		passport, err := userEraser.EraseUser(r.Context(), int32(userId))
+		if err != nil {
+			switch {
+			case errors.Is(err, repo.ErrUserNotFound):
+				log.Warn("user not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+			case errors.Is(err, repo.ErrUserErased):
+				log.Warn("user was already erased", l.Err(err))
+
+				render.Render(w, r, httperr.ErrConflict(err))
+			default:
+				log.Error("failed to erase user", l.Err(err))
+
+				render.Render(w, r, httperr.ErrInternal(err))
+			}
+			return
+		}
+
+		// the people info cached for the passport is personal data too
+		if passport != (models.Passport{}) {
+			if _, err := peopleInfo.Invalidate(r.Context(), passport); err != nil {
+				log.Error("failed to invalidate people info", l.Err(err))
+			}
+		}
+
+		log.Info("erased user", slog.Int("user_id", userId))
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
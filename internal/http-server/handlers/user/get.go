@@ -5,11 +5,14 @@ package user
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
 	"github.com/kuromii5/time-tracker/internal/models"
 	"github.com/kuromii5/time-tracker/internal/utils"
 	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
@@ -17,11 +20,12 @@ import (
 )
 
 type UsersGetter interface {
-	Users(ctx context.Context, filter models.FilterBy, settings models.Pagination) ([]models.User, error)
+	Users(ctx context.Context, filter models.FilterBy, settings models.Pagination) ([]models.User, models.Page, error)
 }
 
 type UsersResponse struct {
 	Users []models.User `json:"users"`
+	models.Page
 }
 
 // Render is used by chi/render to render the response.
@@ -31,10 +35,12 @@ func (ur UsersResponse) Render(w http.ResponseWriter, r *http.Request) error {
 
 // Users handles retrieving a list of users.
 // @Summary Get a list of users
-// @Description Retrieve a list of users with optional filtering and pagination
+// @Description Retrieve a list of users with optional filtering and pagination. Managers only see themselves and their team. Soft-deleted users are hidden unless include_deleted is set.
+// @Description Pages are either skipped by offset, or followed by passing next_cursor or prev_cursor of a page as cursor, which does not skip or repeat users that are created or deleted meanwhile.
 // @Tags users
 // @Accept json
 // @Produce json
+// @Param q query string false "Search in name, surname, patronymic and address, results are ranked by similarity"
 // @Param name query string false "Name"
 // @Param surname query string false "Surname"
 // @Param patronymic query string false "Patronymic"
@@ -43,35 +49,64 @@ func (ur UsersResponse) Render(w http.ResponseWriter, r *http.Request) error {
 // @Param number query string false "Passport Number"
 // @Param created_after query string false "Created After (timestamp)"
 // @Param created_before query string false "Created Before (timestamp)"
+// @Param include_deleted query bool false "Include soft-deleted users"
 // @Param limit query int false "Limit"
-// @Param offset query int false "Offset"
+// @Param offset query int false "Offset, ignored with a cursor"
+// @Param sort query string false "Comma-separated keys to sort by, descending if prefixed with -, e.g. -created_at,surname. Keys: id, created_at, updated_at, name, surname, patronymic, address"
+// @Param cursor query string false "Cursor of the next or previous page"
+// @Param with_total query bool false "Count all users matching the filter"
 // @Success 200 {object} UsersResponse "Successfully retrieved users"
+// @Failure 400 {object} httperr.ErrResponse "Invalid creation range, cursor or sort"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
 // @Failure 500 {object} httperr.ErrResponse "Failed to get users"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /users [get]
-func Users(logger *slog.Logger, usersGetter UsersGetter) http.HandlerFunc {
+func Users(logger *slog.Logger, usersGetter UsersGetter, authorizer Authorizer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log := logger.With(
 			slog.String("handler", "Users"),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
+		teamOf, err := authorizer.ListUsers(r.Context())
+		if err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		createdAfter, err := utils.ParseQueryParamTimeIn(r, "created_after", time.UTC)
+		if err != nil {
+			log.Error("invalid creation range", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+		createdBefore, err := utils.ParseQueryParamTimeIn(r, "created_before", time.UTC)
+		if err != nil {
+			log.Error("invalid creation range", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
 		// Parse query parameters for filtering
 		filter := models.FilterBy{
+			Query:          r.URL.Query().Get("q"),
 			Name:           r.URL.Query().Get("name"),
 			Surname:        r.URL.Query().Get("surname"),
 			Patronymic:     r.URL.Query().Get("patronymic"),
 			Address:        r.URL.Query().Get("address"),
 			PassportSerie:  r.URL.Query().Get("serie"),
 			PassportNumber: r.URL.Query().Get("number"),
-			CreatedAfter:   utils.ParseQueryParamTime(r, "created_after"),
-			CreatedBefore:  utils.ParseQueryParamTime(r, "created_before"),
+			CreatedAfter:   createdAfter,
+			CreatedBefore:  createdBefore,
+			IncludeDeleted: r.URL.Query().Get("include_deleted") == "true",
+			TeamOf:         teamOf,
 		}
 
 		// Parse query parameters for pagination
-		pagination := models.Pagination{
-			Limit:  utils.ParseQueryParamInt(r, "limit"),
-			Offset: utils.ParseQueryParamInt(r, "offset"),
-		}
+		pagination := utils.ParseQueryParamPagination(r)
 
 		log.Debug("received request",
 			slog.Any("filter", filter),
@@ -79,10 +114,14 @@ func Users(logger *slog.Logger, usersGetter UsersGetter) http.HandlerFunc {
 		)
 
 		// Get users from the database
-		users, err := usersGetter.Users(r.Context(), filter, pagination)
+		users, page, err := usersGetter.Users(r.Context(), filter, pagination)
 		if err != nil {
 			log.Error("failed to get users", l.Err(err))
 
+			if errors.Is(err, utils.ErrInvalidCursor) || errors.Is(err, utils.ErrInvalidSort) {
+				render.Render(w, r, httperr.ErrInvalidRequest(err))
+				return
+			}
 			render.Render(w, r, httperr.ErrInternal(err))
 			return
 		}
@@ -90,7 +129,7 @@ func Users(logger *slog.Logger, usersGetter UsersGetter) http.HandlerFunc {
 		log.Info("fetched users", slog.Int("count", len(users)))
 
 		// Write response
-		resp := UsersResponse{Users: users}
+		resp := UsersResponse{Users: users, Page: page}
 		if err := render.Render(w, r, resp); err != nil {
 			log.Error("failed to render response", l.Err(err))
 
@@ -0,0 +1,101 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/app/userimport"
+	"github.com/kuromii5/time-tracker/internal/models"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+// maxImportSize is the most bytes an imported file may have
+const maxImportSize = 32 << 20
+
+type UsersImporter interface {
+	Import(ctx context.Context, r io.Reader, format string) (models.ImportReport, error)
+}
+
+// ImportUsers handles creating users in bulk.
+// @Summary Import users
+// @Description Create users from a CSV file with a header, e.g. "passportNumber,name,surname,patronymic,address", or from NDJSON with an object like the request of POST /users per line.
+// @Description Only passportNumber is required. Missing names and addresses are looked up in the people info API, users are created enriched right away.
+// @Description Every row is reported: created with the user ID, duplicate if the passport is taken or repeated in the file, or failed with the reason.
+// @Description Users are saved in batches. If the import times out, the users saved so far are kept and reported with status 503.
+// @Tags users
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "File format, by default taken from the Content-Type" Enums(csv, ndjson)
+// @Success 200 {object} models.ImportReport "Outcome of every row"
+// @Failure 400 {object} httperr.ErrResponse "Unsupported format, invalid or too large file"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 500 {object} httperr.ErrResponse "Failed to import users"
+// @Failure 503 {object} models.ImportReport "The import timed out, outcome of every row with the rows not saved failed"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/users/import [post]
+func ImportUsers(logger *slog.Logger, importer UsersImporter, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "ImportUsers"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = userimport.FormatOf(r.Header.Get("Content-Type"))
+		}
+
+		// imports take longer than other requests, looking up people info for hundreds of rows
+		rc := http.NewResponseController(w)
+		deadline := time.Now().Add(timeout)
+		if err := rc.SetReadDeadline(deadline); err != nil {
+			log.Warn("failed to extend read deadline", l.Err(err))
+		}
+		if err := rc.SetWriteDeadline(deadline); err != nil {
+			log.Warn("failed to extend write deadline", l.Err(err))
+		}
+		ctx, cancel := context.WithDeadline(r.Context(), deadline)
+		defer cancel()
+
+		body := http.MaxBytesReader(w, r.Body, maxImportSize)
+		defer body.Close()
+
+		report, err := importer.Import(ctx, body, format)
+		if err != nil {
+			log.Error("failed to import users", l.Err(err))
+
+			var tooLarge *http.MaxBytesError
+			switch {
+			case errors.As(err, &tooLarge):
+				render.Render(w, r, httperr.ErrInvalidRequest(fmt.Errorf("the file is larger than %d bytes", tooLarge.Limit)))
+			case errors.Is(err, userimport.ErrUnsupportedFormat) || errors.Is(err, userimport.ErrInvalidFile) || errors.Is(err, userimport.ErrTooManyRows):
+				render.Render(w, r, httperr.ErrInvalidRequest(err))
+			case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled):
+				// batches saved before the import stopped are kept, the report tells which
+				render.Status(r, http.StatusServiceUnavailable)
+				render.JSON(w, r, report)
+			default:
+				render.Render(w, r, httperr.ErrInternal(err))
+			}
+			return
+		}
+
+		log.Info("imported users",
+			slog.Int("created", report.Created),
+			slog.Int("duplicates", report.Duplicates),
+			slog.Int("failed", report.Failed),
+		)
+
+		render.JSON(w, r, report)
+	}
+}
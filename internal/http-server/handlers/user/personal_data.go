@@ -0,0 +1,93 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type PersonalDataGetter interface {
+	PersonalData(ctx context.Context, userID int32) (models.PersonalData, error)
+}
+
+type PersonalDataResponse struct {
+	models.PersonalData
+}
+
+// Render is used by chi/render to render the response.
+func (pr PersonalDataResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// PersonalData handles exporting everything held about a user.
+// @Summary Export personal data of a user
+// @Description Download the user's profile, worklogs, API keys and profile history as a JSON archive. Every export is recorded in the audit log.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} PersonalDataResponse "Personal data archive"
+// @Failure 400 {object} httperr.ErrResponse "Invalid user ID"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "User not found"
+// @Failure 500 {object} httperr.ErrResponse "Failed to export personal data"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/personal-data [get]
+func PersonalData(logger *slog.Logger, dataGetter PersonalDataGetter, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "PersonalData"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		userId, err := strconv.Atoi(idStr)
+		if err != nil {
+			log.Error("invalid user ID", slog.String("user_id", idStr), l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(errors.New("invalid user ID")))
+			return
+		}
+
+		if err := authorizer.ReadUser(r.Context(), int32(userId)); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		data, err := dataGetter.PersonalData(r.Context(), int32(userId))
+		if err != nil {
+			if errors.Is(err, repo.ErrUserNotFound) {
+				log.Warn("user not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+				return
+			}
+			log.Error("failed to export personal data", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("exported personal data", slog.Int("user_id", userId))
+
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="personal-data-%d.json"`, userId))
+		if err := render.Render(w, r, PersonalDataResponse{data}); err != nil {
+			log.Error("failed to render response", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type UserRestorer interface {
+	RestoreUser(ctx context.Context, id int32) error
+}
+
+// RestoreUser handles restoring a soft-deleted user.
+// @Summary Restore a user
+// @Description Restore a soft-deleted user by ID, before the retention period runs out
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} httperr.ErrResponse "Invalid user ID"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "Deleted user not found"
+// @Failure 500 {object} httperr.ErrResponse "Failed to restore user"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/restore [post]
+func RestoreUser(logger *slog.Logger, userRestorer UserRestorer, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "RestoreUser"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		userId, err := strconv.Atoi(idStr)
+		if err != nil {
+			log.Error("invalid user ID", slog.String("user_id", idStr), l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(errors.New("invalid user ID")))
+			return
+		}
+
+		if err := authorizer.Admin(r.Context()); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		if err := userRestorer.RestoreUser(r.Context(), int32(userId)); err != nil {
+			if errors.Is(err, repo.ErrUserNotFound) {
+				log.Warn("deleted user not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+				return
+			}
+			log.Error("failed to restore user", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("restored user", slog.Int("user_id", int(userId)))
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -5,14 +5,19 @@ package user
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
 	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
 	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
 	l "github.com/kuromii5/time-tracker/pkg/logger"
 )
@@ -32,11 +37,21 @@ type UpdateUserRequest struct {
 		Patronymic string `json:"patronymic"`
 		Address    string `json:"address"`
 	} `json:"people"`
+	WorklogPolicy string `json:"worklog_policy" enums:"reject,switch"`
+	EndOfDay      string `json:"end_of_day" example:"18:00"`
+	// ClearEndOfDay unsets the end of day, so forgotten worklogs are only stopped after the maximum duration
+	ClearEndOfDay bool   `json:"clear_end_of_day"`
+	Timezone      string `json:"timezone" example:"Europe/Moscow"`
+	Role          string `json:"role" enums:"admin,manager,employee"`
+	ManagerID     int32  `json:"manager_id"`
+	// ClearManager takes the user out of their manager's team
+	ClearManager bool `json:"clear_manager"`
 }
 
 // UpdateUser handles updating an existing user.
 // @Summary Update an existing user
-// @Description Update a user's details using the provided information
+// @Description Update a user's details using the provided information.
+// @Description Users and their managers may change settings, only admins may edit passport data, roles and teams.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -44,9 +59,14 @@ type UpdateUserRequest struct {
 // @Param request body UpdateUserRequest true "Update User Request"
 // @Success 204 "Successfully updated user"
 // @Failure 400 {object} httperr.ErrResponse "Invalid request payload"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "User not found"
+// @Failure 409 {object} httperr.ErrResponse "User with such passport already exists"
 // @Failure 500 {object} httperr.ErrResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /users/{id} [patch]
-func UpdateUser(logger *slog.Logger, userUpdater UserUpdater) http.HandlerFunc {
+func UpdateUser(logger *slog.Logger, userUpdater UserUpdater, authorizer Authorizer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log := logger.With(
 			slog.String("handler", "UpdateUser"),
@@ -72,6 +92,59 @@ func UpdateUser(logger *slog.Logger, userUpdater UserUpdater) http.HandlerFunc {
 			return
 		}
 
+		if req.WorklogPolicy != "" && req.WorklogPolicy != models.PolicyReject && req.WorklogPolicy != models.PolicySwitch {
+			err := fmt.Errorf("worklog policy should be %q or %q", models.PolicyReject, models.PolicySwitch)
+			log.Error("invalid worklog policy", slog.String("worklog_policy", req.WorklogPolicy), l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		if req.EndOfDay != "" && req.ClearEndOfDay {
+			err := errors.New("end of day can't be set and cleared at once")
+			log.Error("invalid end of day", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+		if req.EndOfDay != "" {
+			if _, err := time.Parse("15:04", req.EndOfDay); err != nil {
+				err := errors.New("end of day should be in the format HH:MM")
+				log.Error("invalid end of day", slog.String("end_of_day", req.EndOfDay), l.Err(err))
+
+				render.Render(w, r, httperr.ErrInvalidRequest(err))
+				return
+			}
+		}
+
+		if req.Timezone != "" {
+			if _, err := time.LoadLocation(req.Timezone); err != nil {
+				err := fmt.Errorf("unknown time zone: %q", req.Timezone)
+				log.Error("invalid time zone", slog.String("timezone", req.Timezone), l.Err(err))
+
+				render.Render(w, r, httperr.ErrInvalidRequest(err))
+				return
+			}
+		}
+
+		if req.ManagerID != 0 && req.ClearManager {
+			err := errors.New("manager can't be set and cleared at once")
+			log.Error("invalid manager", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		switch req.Role {
+		case "", models.RoleAdmin, models.RoleManager, models.RoleEmployee:
+		default:
+			err := fmt.Errorf("role should be %q, %q or %q", models.RoleAdmin, models.RoleManager, models.RoleEmployee)
+			log.Error("invalid role", slog.String("role", req.Role), l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
 		// Prepare the user object for update
 		user := models.User{
 			ID: int32(userId),
@@ -85,10 +158,34 @@ func UpdateUser(logger *slog.Logger, userUpdater UserUpdater) http.HandlerFunc {
 				Patronymic: req.People.Patronymic,
 				Address:    req.People.Address,
 			},
+			WorklogPolicy: req.WorklogPolicy,
+			EndOfDay:      req.EndOfDay,
+			ClearEndOfDay: req.ClearEndOfDay,
+			Timezone:      req.Timezone,
+			Role:          req.Role,
+			ManagerID:     req.ManagerID,
+			ClearManager:  req.ClearManager,
+		}
+
+		if err := authorizer.UpdateUser(r.Context(), user); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
 		}
 
 		// Update user in the database
 		if err := userUpdater.UpdateUser(r.Context(), user); err != nil {
+			if errors.Is(err, repo.ErrUserNotFound) {
+				log.Warn("user not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+				return
+			}
+			if errors.Is(err, repo.ErrPassportDuplicate) {
+				log.Warn("user with such passport already exists", l.Err(err))
+
+				render.Render(w, r, httperr.ErrConflict(err))
+				return
+			}
 			log.Error("failed to update user", l.Err(err))
 
 			render.Render(w, r, httperr.ErrInternal(err))
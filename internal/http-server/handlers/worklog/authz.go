@@ -0,0 +1,13 @@
+package worklog
+
+import "context"
+
+// Authorizer checks the permissions of the request principal, see auth.Guard
+type Authorizer interface {
+	Admin(ctx context.Context) error
+	ReadUser(ctx context.Context, userID int32) error
+	TrackTime(ctx context.Context, userID int32) error
+	TrackWorklog(ctx context.Context, worklogID int32) error
+	EditWorklogs(ctx context.Context, userID int32) error
+	EditWorklog(ctx context.Context, worklogID int32) error
+}
@@ -0,0 +1,36 @@
+package worklog
+
+import (
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+)
+
+// ConflictingWorklog is a worklog listed in the details of an overlap conflict
+type ConflictingWorklog struct {
+	ID        int32  `json:"id"`
+	Task      string `json:"task"`
+	StartTime string `json:"start_time"`
+	// EndTime is only set for finished worklogs
+	EndTime string `json:"end_time,omitempty"`
+}
+
+// errOverlap renders an overlap as a conflict listing the conflicting worklogs
+func errOverlap(err *repo.OverlapError) render.Renderer {
+	conflicts := make([]ConflictingWorklog, 0, len(err.Conflicts))
+	for _, wl := range err.Conflicts {
+		conflict := ConflictingWorklog{
+			ID:        wl.ID,
+			Task:      wl.Task,
+			StartTime: formatTime(wl.StartedAt, time.UTC),
+		}
+		if !wl.FinishedAt.IsZero() {
+			conflict.EndTime = formatTime(wl.FinishedAt, time.UTC)
+		}
+		conflicts = append(conflicts, conflict)
+	}
+
+	return httperr.ErrConflictDetails(err, conflicts)
+}
@@ -0,0 +1,150 @@
+package worklog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type WorklogCreator interface {
+	CreateWorklog(ctx context.Context, worklog models.Worklog) (int32, error)
+}
+
+type CreateWorklogRequest struct {
+	UserID    int32     `json:"user_id"`
+	Task      string    `json:"task"`
+	ProjectID int32     `json:"project_id,omitempty"`
+	StartTime time.Time `json:"start_time" description:"Start time in the format YYYY-MM-DDTHH:MM:SSZ (ISO 8601)"`
+	EndTime   time.Time `json:"end_time" description:"End time in the format YYYY-MM-DDTHH:MM:SSZ (ISO 8601)"`
+}
+
+type CreateWorklogResponse struct {
+	WorklogID int32 `json:"worklog_id"`
+}
+
+// @Summary Create a worklog manually
+// @Description Record a finished worklog with explicit start and end time, e.g. for a forgotten timer.
+// @Description The worklog must not overlap with other worklogs of the same user.
+// @Tags worklogs
+// @Accept json
+// @Produce json
+// @Param request body CreateWorklogRequest true "Create Worklog Request"
+// @Success 201 {object} CreateWorklogResponse "Successfully created worklog"
+// @Failure 400 {object} httperr.ErrResponse "Invalid request payload"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "User or project not found"
+// @Failure 409 {object} httperr.ErrResponse "Worklog overlaps with other worklogs, they are listed in details"
+// @Failure 500 {object} httperr.ErrResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /worklogs [post]
+func CreateWorklog(logger *slog.Logger, worklogCreator WorklogCreator, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "CreateWorklog"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req CreateWorklogRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			if errors.Is(err, io.EOF) {
+				log.Error("request body is empty")
+
+				render.Render(w, r, httperr.ErrInvalidRequest(errors.New("request body is empty")))
+				return
+			}
+			log.Error("failed to decode request body", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+		defer r.Body.Close()
+
+		if err := validateManualWorklog(req.Task, req.StartTime, req.EndTime, true); err != nil {
+			log.Error("invalid worklog", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		if err := authorizer.EditWorklogs(r.Context(), req.UserID); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		worklog := models.Worklog{
+			UserID:     req.UserID,
+			ProjectID:  req.ProjectID,
+			Task:       req.Task,
+			StartedAt:  req.StartTime,
+			FinishedAt: req.EndTime,
+		}
+		worklogID, err := worklogCreator.CreateWorklog(r.Context(), worklog)
+		if err != nil {
+			renderManualErr(w, r, log, err)
+			return
+		}
+
+		log.Info("worklog created successfully", slog.Int("worklog_id", int(worklogID)))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, CreateWorklogResponse{WorklogID: worklogID})
+	}
+}
+
+// validateManualWorklog checks the fields of a manually recorded worklog.
+// Unless required is set, zero fields are allowed and mean "unchanged".
+func validateManualWorklog(task string, start, end time.Time, required bool) error {
+	if required {
+		if task == "" {
+			return errors.New("task is required")
+		}
+		if start.IsZero() || end.IsZero() {
+			return errors.New("start_time and end_time are required")
+		}
+	}
+
+	now := time.Now()
+	if start.After(now) || end.After(now) {
+		return errors.New("worklogs can't be recorded in the future")
+	}
+	if !start.IsZero() && !end.IsZero() && !end.After(start) {
+		return repo.ErrInvalidRange
+	}
+
+	return nil
+}
+
+// renderManualErr renders errors of manual worklog changes
+func renderManualErr(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	var overlapErr *repo.OverlapError
+	switch {
+	case errors.As(err, &overlapErr):
+		log.Warn("worklog overlaps with other worklogs", l.Err(err))
+
+		render.Render(w, r, errOverlap(overlapErr))
+	case errors.Is(err, repo.ErrInvalidRange):
+		log.Warn("invalid time range", l.Err(err))
+
+		render.Render(w, r, httperr.ErrInvalidRequest(err))
+	case errors.Is(err, repo.ErrUserNotFound), errors.Is(err, repo.ErrProjectNotFound), errors.Is(err, repo.ErrWorklogNotFound):
+		log.Warn("worklog, user or project not found", l.Err(err))
+
+		render.Render(w, r, httperr.ErrNotFound(err))
+	default:
+		log.Error("failed to save worklog", l.Err(err))
+
+		render.Render(w, r, httperr.ErrInternal(err))
+	}
+}
@@ -0,0 +1,74 @@
+package worklog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type WorklogDeleter interface {
+	DeleteWorklog(ctx context.Context, worklogID int32) error
+}
+
+// @Summary Delete a worklog
+// @Description Delete a worklog with all its segments
+// @Tags worklogs
+// @Accept json
+// @Produce json
+// @Param id path int true "Worklog ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} httperr.ErrResponse "Invalid worklog ID"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "Worklog not found or its owner is deleted"
+// @Failure 500 {object} httperr.ErrResponse "Failed to delete worklog"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /worklogs/{id} [delete]
+func DeleteWorklog(logger *slog.Logger, worklogDeleter WorklogDeleter, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "DeleteWorklog"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		worklogID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Error("failed to parse worklog ID", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		if err := authorizer.EditWorklog(r.Context(), int32(worklogID)); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		if err := worklogDeleter.DeleteWorklog(r.Context(), int32(worklogID)); err != nil {
+			if errors.Is(err, repo.ErrWorklogNotFound) || errors.Is(err, repo.ErrUserNotFound) {
+				log.Warn("worklog or its owner not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+				return
+			}
+			log.Error("failed to delete worklog", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInternal(err))
+			return
+		}
+
+		log.Info("worklog deleted successfully", slog.Int("worklog_id", worklogID))
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
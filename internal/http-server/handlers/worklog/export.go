@@ -0,0 +1,371 @@
+package worklog
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/utils"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+	"github.com/kuromii5/time-tracker/pkg/xlsx"
+)
+
+type WorklogsExporter interface {
+	ExportWorklogs(ctx context.Context, filter models.ExportFilter, fn func(models.WorklogExportRow) error) error
+}
+
+const (
+	formatCSV  = "csv"
+	formatXLSX = "xlsx"
+)
+
+// flushEvery is how many rows are buffered before they are sent to the client
+const flushEvery = 500
+
+// exportColumn is a column of the export together with its cell value
+type exportColumn struct {
+	name  string
+	value func(row models.WorklogExportRow) any
+}
+
+func hours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
+
+func optionalTime(t time.Time) any {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// exportColumns are all columns available for export, in their default order
+var exportColumns = []exportColumn{
+	{"id", func(row models.WorklogExportRow) any { return row.ID }},
+	{"user_id", func(row models.WorklogExportRow) any { return row.UserID }},
+	{"name", func(row models.WorklogExportRow) any { return row.Name }},
+	{"surname", func(row models.WorklogExportRow) any { return row.Surname }},
+	{"patronymic", func(row models.WorklogExportRow) any { return row.Patronymic }},
+	{"client", func(row models.WorklogExportRow) any { return row.Client }},
+	{"project", func(row models.WorklogExportRow) any { return row.Project }},
+	{"task", func(row models.WorklogExportRow) any { return row.Task }},
+	{"status", func(row models.WorklogExportRow) any { return row.Status }},
+	{"start_time", func(row models.WorklogExportRow) any { return optionalTime(row.StartedAt) }},
+	{"end_time", func(row models.WorklogExportRow) any { return optionalTime(row.FinishedAt) }},
+	{"duration_hours", func(row models.WorklogExportRow) any { return hours(row.Duration) }},
+	{"paused_hours", func(row models.WorklogExportRow) any { return hours(row.Paused) }},
+}
+
+// parseExportColumns picks the requested columns in the requested order, or all of them
+func parseExportColumns(param string) ([]exportColumn, error) {
+	if param == "" {
+		return exportColumns, nil
+	}
+
+	var columns []exportColumn
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+
+		found := false
+		for _, col := range exportColumns {
+			if col.name == name {
+				columns = append(columns, col)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column: %q", name)
+		}
+	}
+
+	return columns, nil
+}
+
+// exportFormat picks the format from the format parameter or from the Accept header. CSV is the default.
+func exportFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case formatCSV, formatXLSX:
+		return format, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported format: %q", format)
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), xlsx.ContentType) {
+		return formatXLSX, nil
+	}
+	return formatCSV, nil
+}
+
+// rowWriter is implemented by both csv and xlsx exports
+type rowWriter interface {
+	WriteRow(cells []any) error
+	Flush() error
+	Close() error
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// WriteRow writes the cells as text. Text cells that spreadsheets would take for a formula,
+// like a task named "=HYPERLINK(...)", are prefixed with a quote to keep them text.
+func (c csvWriter) WriteRow(cells []any) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		if text, ok := cell.(string); ok {
+			record[i] = escapeFormula(text)
+			continue
+		}
+		record[i] = fmt.Sprint(cell)
+	}
+	return c.w.Write(record)
+}
+
+// escapeFormula prefixes text starting like a formula with a quote
+func escapeFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+func (c csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c csvWriter) Close() error {
+	return c.Flush()
+}
+
+// @Summary Export worklogs
+// @Description Export worklogs started within a date range as CSV or XLSX. The file is streamed row by row.
+// @Description The format is taken from the format parameter, then from the Accept header, and defaults to CSV.
+// @Description Times are written in the tz time zone, or in each worklog owner's time zone if tz is not set.
+// @Description Available columns: id, user_id, name, surname, patronymic, client, project, task, status, start_time, end_time, duration_hours, paused_hours.
+// @Description In CSV, text starting with =, +, -, @, a tab or a carriage return is prefixed with a quote, so spreadsheets don't run it as a formula.
+// @Tags worklogs
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param from query string false "Range start (inclusive), YYYY-MM-DDTHH:MM:SSZ or YYYY-MM-DD"
+// @Param to query string false "Range end (exclusive), YYYY-MM-DDTHH:MM:SSZ or YYYY-MM-DD"
+// @Param user_id query int false "User ID, required unless the caller is an admin"
+// @Param project_id query int false "Project ID"
+// @Param client_id query int false "Client ID"
+// @Param columns query string false "Comma-separated columns, all by default"
+// @Param format query string false "csv or xlsx"
+// @Param tz query string false "IANA time zone, e.g. Europe/Moscow"
+// @Success 200 {file} file "Worklogs export"
+// @Failure 400 {object} httperr.ErrResponse "Invalid export parameters"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 500 {object} httperr.ErrResponse "Failed to export worklogs"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /worklogs/export [get]
+func ExportWorklogs(logger *slog.Logger, worklogsExporter WorklogsExporter, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "ExportWorklogs"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		tz, err := utils.ParseQueryParamLocation(r, "tz")
+		if err != nil {
+			log.Error("invalid time zone", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+		rangeLoc := time.UTC
+		if tz != nil {
+			rangeLoc = tz
+		}
+
+		from, err := utils.ParseQueryParamTimeIn(r, "from", rangeLoc)
+		if err != nil {
+			log.Error("invalid date range", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+		to, err := utils.ParseQueryParamTimeIn(r, "to", rangeLoc)
+		if err != nil {
+			log.Error("invalid date range", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		userID, err := utils.ParseQueryParamID(r, "user_id")
+		if err != nil {
+			log.Error("invalid filter", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+		projectID, err := utils.ParseQueryParamID(r, "project_id")
+		if err != nil {
+			log.Error("invalid filter", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+		clientID, err := utils.ParseQueryParamID(r, "client_id")
+		if err != nil {
+			log.Error("invalid filter", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		filter := models.ExportFilter{
+			From:      from,
+			To:        to,
+			UserID:    userID,
+			ProjectID: projectID,
+			ClientID:  clientID,
+		}
+		if filter.To.IsZero() {
+			filter.To = time.Now()
+		}
+		if !filter.To.After(filter.From) {
+			err := errors.New("to should be after from")
+			log.Error("invalid date range", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		// reading a user's time is checked per user, everyone's time is only for admins
+		if filter.UserID != 0 {
+			err = authorizer.ReadUser(r.Context(), filter.UserID)
+		} else {
+			err = authorizer.Admin(r.Context())
+		}
+		if err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		columns, err := parseExportColumns(r.URL.Query().Get("columns"))
+		if err != nil {
+			log.Error("invalid columns", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		format, err := exportFormat(r)
+		if err != nil {
+			log.Error("invalid format", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		log.Debug("received request", slog.Any("filter", filter), slog.String("format", format))
+
+		// Big exports take longer than the server write timeout
+		rc := http.NewResponseController(w)
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			log.Warn("failed to disable write deadline", l.Err(err))
+		}
+
+		filename := fmt.Sprintf("worklogs_%s_%s.%s", filter.From.Format("20060102"), filter.To.Format("20060102"), format)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+		var out rowWriter
+		switch format {
+		case formatXLSX:
+			w.Header().Set("Content-Type", xlsx.ContentType)
+			out, err = xlsx.NewWriter(w, "Worklogs")
+		default:
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			out = csvWriter{w: csv.NewWriter(w)}
+		}
+		if err != nil {
+			log.Error("failed to start export", l.Err(err))
+			return
+		}
+
+		header := make([]any, len(columns))
+		for i, col := range columns {
+			header[i] = col.name
+		}
+		if err := out.WriteRow(header); err != nil {
+			log.Error("failed to write header", l.Err(err))
+			return
+		}
+
+		// Times are written in the tz time zone or in the owner's time zone
+		locations := make(map[string]*time.Location)
+		rowLocation := func(row models.WorklogExportRow) *time.Location {
+			if tz != nil {
+				return tz
+			}
+			loc, ok := locations[row.Timezone]
+			if !ok {
+				var err error
+				if loc, err = time.LoadLocation(row.Timezone); err != nil {
+					log.Warn("unknown user time zone", slog.String("timezone", row.Timezone), l.Err(err))
+					loc = time.UTC
+				}
+				locations[row.Timezone] = loc
+			}
+			return loc
+		}
+
+		// The response is already being sent, so failures below can only be logged
+		count := 0
+		err = worklogsExporter.ExportWorklogs(r.Context(), filter, func(row models.WorklogExportRow) error {
+			loc := rowLocation(row)
+			row.StartedAt = row.StartedAt.In(loc)
+			if !row.FinishedAt.IsZero() {
+				row.FinishedAt = row.FinishedAt.In(loc)
+			}
+
+			cells := make([]any, len(columns))
+			for i, col := range columns {
+				cells[i] = col.value(row)
+			}
+			if err := out.WriteRow(cells); err != nil {
+				return err
+			}
+
+			count++
+			if count%flushEvery == 0 {
+				if err := out.Flush(); err != nil {
+					return err
+				}
+				if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			log.Error("failed to export worklogs", l.Err(err))
+			return
+		}
+
+		if err := out.Close(); err != nil {
+			log.Error("failed to finish export", l.Err(err))
+			return
+		}
+
+		log.Info("worklogs exported successfully", slog.Int("count", count), slog.String("format", format))
+	}
+}
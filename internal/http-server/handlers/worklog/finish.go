@@ -2,6 +2,7 @@ package worklog
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
 	"github.com/kuromii5/time-tracker/internal/repo"
 	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
 	l "github.com/kuromii5/time-tracker/pkg/logger"
@@ -26,9 +28,14 @@ type WorklogFinisher interface {
 // @Param id path int true "Worklog ID"
 // @Success 204 "No Content"
 // @Failure 400 {object} httperr.ErrResponse "Invalid worklog ID"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "Worklog not found or its owner is deleted"
+// @Failure 409 {object} httperr.ErrResponse "Worklog is already finished"
 // @Failure 500 {object} httperr.ErrResponse "Failed to finish worklog"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /worklogs/finish/{id} [patch]
-func FinishWorklog(logger *slog.Logger, worklogFinisher WorklogFinisher) http.HandlerFunc {
+func FinishWorklog(logger *slog.Logger, worklogFinisher WorklogFinisher, authorizer Authorizer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log := logger.With(
 			slog.String("handler", "FinishWorklog"),
@@ -44,6 +51,11 @@ func FinishWorklog(logger *slog.Logger, worklogFinisher WorklogFinisher) http.Ha
 			return
 		}
 
+		if err := authorizer.TrackWorklog(r.Context(), int32(worklogID)); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
 		err = worklogFinisher.FinishWorklog(r.Context(), int32(worklogID))
 		if err != nil {
 			if err == repo.ErrAlreadyDone {
@@ -52,6 +64,12 @@ func FinishWorklog(logger *slog.Logger, worklogFinisher WorklogFinisher) http.Ha
 				render.Render(w, r, httperr.ErrConflict(err))
 				return
 			}
+			if errors.Is(err, repo.ErrWorklogNotFound) || errors.Is(err, repo.ErrUserNotFound) {
+				log.Warn("worklog or its owner not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+				return
+			}
 			log.Error("failed to finish worklog", l.Err(err))
 
 			render.Render(w, r, httperr.ErrInternal(err))
@@ -2,7 +2,7 @@ package worklog
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -12,50 +12,78 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
 	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	"github.com/kuromii5/time-tracker/internal/utils"
 	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
 	l "github.com/kuromii5/time-tracker/pkg/logger"
 )
 
 type WorklogsGetter interface {
-	Worklogs(ctx context.Context, userID int32, startDate, endDate time.Time) ([]models.Worklog, error)
-}
-
-type WorklogsRequest struct {
-	StartDate time.Time `json:"start_date" description:"Start date in the format YYYY-MM-DDTHH:MM:SSZ (ISO 8601)"`
-	EndDate   time.Time `json:"end_date" description:"End date in the format YYYY-MM-DDTHH:MM:SSZ (ISO 8601)"`
+	Worklogs(ctx context.Context, userID int32, filter models.WorklogFilter, settings models.Pagination) ([]models.Worklog, models.Page, error)
+	UserTimezone(ctx context.Context, userID int32) (string, error)
 }
 
 type WorklogResponse struct {
 	ID        int32  `json:"id"`
 	UserID    int32  `json:"user_id"`
+	ProjectID int32  `json:"project_id,omitempty"`
 	Task      string `json:"task"`
+	Status    string `json:"status"`
 	StartTime string `json:"start_time"`
-	EndTime   string `json:"end_time"`
-	Duration  string `json:"duration"`
+	// EndTime is only set for finished worklogs
+	EndTime string `json:"end_time,omitempty"`
+	// Elapsed is the time since an unfinished worklog started
+	Elapsed  string `json:"elapsed,omitempty"`
+	Duration string `json:"duration"`
+	Paused   string `json:"paused"`
+	// AutoStopped is set if the worklog was forgotten and stopped automatically
+	AutoStopped bool `json:"auto_stopped"`
 }
 
-func formatDuration(duration time.Duration) string {
-	hours := int(duration.Hours())
-	minutes := int(duration.Minutes()) % 60
-	return fmt.Sprintf("%dh %dm", hours, minutes)
+type WorklogsResponse struct {
+	Worklogs []WorklogResponse `json:"worklogs"`
+	models.Page
 }
-func formatTime(t time.Time) string {
-	return t.Format("2006-01-02, 15:04:05")
+
+// formatTime formats t in loc, with the UTC offset so that the time is unambiguous
+func formatTime(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("2006-01-02, 15:04:05 -07:00")
 }
 
 // @Summary Get worklogs for a user
-// @Description Get worklogs for a user within a specified date range. Time format should be YYYY-MM-DDTHH:MM:SSZ (ISO 8601).
+// @Description Get worklogs for a user overlapping the from-to range, running worklogs last until now. Times are YYYY-MM-DDTHH:MM:SSZ (ISO 8601) or YYYY-MM-DD dates, meaning midnight in the time zone the worklogs are shown in.
+// @Description Duration is the active time of a worklog, paused is the time it spent on pause. Unfinished worklogs have no end time, but the time elapsed since they started.
+// @Description Times are shown in the tz time zone, or in the user's own time zone if tz is not set.
+// @Description Pages are either skipped by offset, or followed by passing next_cursor or prev_cursor of a page as cursor. The duration of running worklogs grows, so cursors sorted by duration may skip or repeat them.
 // @Tags worklogs
 // @Accept json
 // @Produce json
 // @Param userID path int true "User ID"
-// @Param request body WorklogsRequest true "Worklogs Request"
-// @Success 200 {array} WorklogResponse "List of worklogs"
-// @Failure 400 {object} httperr.ErrResponse "Invalid request payload or user ID"
+// @Param from query string false "Start of the range"
+// @Param to query string false "End of the range"
+// @Param status query string false "Worklog status" Enums(running, paused, finished)
+// @Param task query string false "Search in the task, case-insensitively"
+// @Param min_duration query string false "Least active time, e.g. 1h30m"
+// @Param project_id query int false "Project ID"
+// @Param client_id query int false "Client ID"
+// @Param auto_stopped query bool false "Only worklogs stopped automatically, for review"
+// @Param tz query string false "IANA time zone, e.g. Europe/Moscow"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset, ignored with a cursor"
+// @Param sort query string false "Comma-separated keys to sort by, descending if prefixed with -, e.g. -start_time,task, by default -start_time. Keys: id, project_id, task, start_time, end_time, duration"
+// @Param cursor query string false "Cursor of the next or previous page"
+// @Param with_total query bool false "Count all worklogs matching the filter"
+// @Success 200 {object} WorklogsResponse "Page of worklogs"
+// @Failure 400 {object} httperr.ErrResponse "Invalid user ID, filter, cursor or sort"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "User not found"
 // @Failure 500 {object} httperr.ErrResponse "Failed to get worklogs"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /users/{userID}/worklogs [get]
-func Worklogs(logger *slog.Logger, worklogsGetter WorklogsGetter) http.HandlerFunc {
+func Worklogs(logger *slog.Logger, worklogsGetter WorklogsGetter, authorizer Authorizer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log := logger.With(
 			slog.String("handler", "Worklogs"),
@@ -70,48 +98,80 @@ func Worklogs(logger *slog.Logger, worklogsGetter WorklogsGetter) http.HandlerFu
 			return
 		}
 
-		var req WorklogsRequest
-		err = json.NewDecoder(r.Body).Decode(&req)
+		if err := authorizer.ReadUser(r.Context(), int32(userID)); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		loc, err := utils.ParseQueryParamLocation(r, "tz")
 		if err != nil {
-			log.Error("failed to decode request body", l.Err(err))
+			log.Error("invalid time zone", l.Err(err))
 
 			render.Render(w, r, httperr.ErrInvalidRequest(err))
 			return
 		}
+		if loc == nil {
+			loc, err = userLocation(r.Context(), worklogsGetter, int32(userID))
+			if err != nil {
+				log.Error("failed to get user time zone", l.Err(err))
+
+				if errors.Is(err, repo.ErrUserNotFound) {
+					render.Render(w, r, httperr.ErrNotFound(err))
+					return
+				}
+				render.Render(w, r, httperr.ErrInternal(err))
+				return
+			}
+		}
+
+		filter, err := parseWorklogFilter(r, loc)
+		if err != nil {
+			log.Error("invalid filter", l.Err(err))
 
-		if req.EndDate.Before(req.StartDate) {
-			err := fmt.Errorf("end date should be after start date")
-			log.Error("invalid date range", l.Err(err))
 			render.Render(w, r, httperr.ErrInvalidRequest(err))
 			return
 		}
+		log.Debug("filter", slog.Any("filter", filter))
 
-		log.Debug("start_date", slog.Time("start_date", req.StartDate))
-		log.Debug("end_date", slog.Time("end_date", req.EndDate))
+		pagination := utils.ParseQueryParamPagination(r)
+		log.Debug("pagination", slog.Any("pagination", pagination))
 
-		worklogs, err := worklogsGetter.Worklogs(r.Context(), int32(userID), req.StartDate, req.EndDate)
+		worklogs, page, err := worklogsGetter.Worklogs(r.Context(), int32(userID), filter, pagination)
 		if err != nil {
 			log.Error("failed to get worklogs", l.Err(err))
 
+			if errors.Is(err, utils.ErrInvalidCursor) || errors.Is(err, utils.ErrInvalidSort) {
+				render.Render(w, r, httperr.ErrInvalidRequest(err))
+				return
+			}
 			render.Render(w, r, httperr.ErrInternal(err))
 			return
 		}
 
-		var resp []WorklogResponse
+		now := time.Now()
+		resp := WorklogsResponse{Page: page}
 		for _, wl := range worklogs {
-			duration := formatDuration(wl.FinishedAt.Sub(wl.StartedAt))
-			startTime := formatTime(wl.StartedAt)
-			endTime := formatTime(wl.FinishedAt)
+			duration := utils.FormatDuration(wl.Duration)
+			paused := utils.FormatDuration(wl.Paused)
+			startTime := formatTime(wl.StartedAt, loc)
 
 			wr := WorklogResponse{
-				ID:        wl.ID,
-				UserID:    wl.UserID,
-				Task:      wl.Task,
-				StartTime: startTime,
-				EndTime:   endTime,
-				Duration:  duration,
+				ID:          wl.ID,
+				UserID:      wl.UserID,
+				ProjectID:   wl.ProjectID,
+				Task:        wl.Task,
+				Status:      wl.Status,
+				StartTime:   startTime,
+				Duration:    duration,
+				Paused:      paused,
+				AutoStopped: wl.AutoStopped,
 			}
-			resp = append(resp, wr)
+			if wl.Status == models.WorklogFinished {
+				wr.EndTime = formatTime(wl.FinishedAt, loc)
+			} else {
+				wr.Elapsed = utils.FormatDuration(now.Sub(wl.StartedAt))
+			}
+			resp.Worklogs = append(resp.Worklogs, wr)
 		}
 
 		render.JSON(w, r, resp)
@@ -119,3 +179,65 @@ func Worklogs(logger *slog.Logger, worklogsGetter WorklogsGetter) http.HandlerFu
 		log.Info("worklogs retrieved successfully", slog.Int("count", len(worklogs)))
 	}
 }
+
+// parseWorklogFilter parses the filter query parameters, dates of the range are midnight in loc
+func parseWorklogFilter(r *http.Request, loc *time.Location) (models.WorklogFilter, error) {
+	from, err := utils.ParseQueryParamTimeIn(r, "from", loc)
+	if err != nil {
+		return models.WorklogFilter{}, err
+	}
+	to, err := utils.ParseQueryParamTimeIn(r, "to", loc)
+	if err != nil {
+		return models.WorklogFilter{}, err
+	}
+
+	projectID, err := utils.ParseQueryParamID(r, "project_id")
+	if err != nil {
+		return models.WorklogFilter{}, err
+	}
+	clientID, err := utils.ParseQueryParamID(r, "client_id")
+	if err != nil {
+		return models.WorklogFilter{}, err
+	}
+
+	filter := models.WorklogFilter{
+		From:        from,
+		To:          to,
+		ProjectID:   projectID,
+		ClientID:    clientID,
+		AutoStopped: r.URL.Query().Get("auto_stopped") == "true",
+		Status:      r.URL.Query().Get("status"),
+		Task:        r.URL.Query().Get("task"),
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.To.After(filter.From) {
+		return models.WorklogFilter{}, errors.New("to should be after from")
+	}
+
+	switch filter.Status {
+	case "", models.WorklogRunning, models.WorklogPaused, models.WorklogFinished:
+	default:
+		return models.WorklogFilter{}, fmt.Errorf("unknown status %q, should be %s, %s or %s",
+			filter.Status, models.WorklogRunning, models.WorklogPaused, models.WorklogFinished)
+	}
+
+	if value := r.URL.Query().Get("min_duration"); value != "" {
+		minDuration, err := time.ParseDuration(value)
+		if err != nil || minDuration < 0 {
+			return models.WorklogFilter{}, fmt.Errorf("invalid min_duration %q, expected a duration such as 1h30m", value)
+		}
+		filter.MinDuration = minDuration
+	}
+
+	return filter, nil
+}
+
+// userLocation loads the time zone of the user
+func userLocation(ctx context.Context, worklogsGetter WorklogsGetter, userID int32) (*time.Location, error) {
+	name, err := worklogsGetter.UserTimezone(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return time.LoadLocation(name)
+}
@@ -0,0 +1,81 @@
+package worklog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type WorklogPauser interface {
+	PauseWorklog(ctx context.Context, worklogID int32) error
+}
+
+// @Summary Pause a worklog
+// @Description Pause a running worklog. Paused time is not counted in the worklog duration
+// @Tags worklogs
+// @Accept json
+// @Produce json
+// @Param id path int true "Worklog ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} httperr.ErrResponse "Invalid worklog ID"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "Worklog not found or its owner is deleted"
+// @Failure 409 {object} httperr.ErrResponse "Worklog is already paused or finished"
+// @Failure 500 {object} httperr.ErrResponse "Failed to pause worklog"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /worklogs/{id}/pause [post]
+func PauseWorklog(logger *slog.Logger, worklogPauser WorklogPauser, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "PauseWorklog"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		worklogID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Error("failed to parse worklog ID", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		if err := authorizer.TrackWorklog(r.Context(), int32(worklogID)); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		err = worklogPauser.PauseWorklog(r.Context(), int32(worklogID))
+		if err != nil {
+			switch {
+			case errors.Is(err, repo.ErrWorklogNotFound), errors.Is(err, repo.ErrUserNotFound):
+				log.Warn("worklog or its owner not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+			case errors.Is(err, repo.ErrAlreadyDone), errors.Is(err, repo.ErrAlreadyPaused):
+				log.Warn("worklog can't be paused", l.Err(err))
+
+				render.Render(w, r, httperr.ErrConflict(err))
+			default:
+				log.Error("failed to pause worklog", l.Err(err))
+
+				render.Render(w, r, httperr.ErrInternal(err))
+			}
+			return
+		}
+
+		log.Info("worklog paused successfully", slog.Int("worklog_id", worklogID))
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,81 @@
+package worklog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/repo"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type WorklogResumer interface {
+	ResumeWorklog(ctx context.Context, worklogID int32) error
+}
+
+// @Summary Resume a worklog
+// @Description Resume a paused worklog by opening a new active segment
+// @Tags worklogs
+// @Accept json
+// @Produce json
+// @Param id path int true "Worklog ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} httperr.ErrResponse "Invalid worklog ID"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "Worklog not found or its owner is deleted"
+// @Failure 409 {object} httperr.ErrResponse "Worklog is not paused or already finished"
+// @Failure 500 {object} httperr.ErrResponse "Failed to resume worklog"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /worklogs/{id}/resume [post]
+func ResumeWorklog(logger *slog.Logger, worklogResumer WorklogResumer, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "ResumeWorklog"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		worklogID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Error("failed to parse worklog ID", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		if err := authorizer.TrackWorklog(r.Context(), int32(worklogID)); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		err = worklogResumer.ResumeWorklog(r.Context(), int32(worklogID))
+		if err != nil {
+			switch {
+			case errors.Is(err, repo.ErrWorklogNotFound), errors.Is(err, repo.ErrUserNotFound):
+				log.Warn("worklog or its owner not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+			case errors.Is(err, repo.ErrAlreadyDone), errors.Is(err, repo.ErrNotPaused):
+				log.Warn("worklog can't be resumed", l.Err(err))
+
+				render.Render(w, r, httperr.ErrConflict(err))
+			default:
+				log.Error("failed to resume worklog", l.Err(err))
+
+				render.Render(w, r, httperr.ErrInternal(err))
+			}
+			return
+		}
+
+		log.Info("worklog resumed successfully", slog.Int("worklog_id", worklogID))
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
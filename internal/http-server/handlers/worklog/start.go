@@ -9,34 +9,46 @@ import (
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/repo"
 	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
 	l "github.com/kuromii5/time-tracker/pkg/logger"
 )
 
 type WorklogStarter interface {
-	StartWorklog(ctx context.Context, task string, userID int32) (int32, error)
+	StartWorklog(ctx context.Context, task string, userID, projectID int32) (worklogID, stoppedID int32, err error)
 }
 
 type StartWorklogRequest struct {
-	Task   string `json:"task"`
-	UserID int32  `json:"user_id"`
+	Task      string `json:"task"`
+	UserID    int32  `json:"user_id"`
+	ProjectID int32  `json:"project_id,omitempty"`
 }
 
 type StartWorklogResponse struct {
 	WorklogID int32 `json:"worklog_id"`
+	// StoppedWorklogID is the worklog that was finished to start this one, if any
+	StoppedWorklogID *int32 `json:"stopped_worklog_id"`
 }
 
 // @Summary Start a worklog
-// @Description Start a new worklog for a specified user with a given task
+// @Description Start a new worklog for a specified user with a given task.
+// @Description A user can have only one open worklog. Depending on the user's worklog policy
+// @Description the open one is either finished automatically or the request is rejected
 // @Tags worklogs
 // @Accept json
 // @Produce json
 // @Param request body StartWorklogRequest true "Start Worklog Request"
 // @Success 201 {object} StartWorklogResponse "Successfully started worklog"
 // @Failure 400 {object} httperr.ErrResponse "Invalid request payload"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "User or project not found"
+// @Failure 409 {object} httperr.ErrResponse "User already has a running worklog"
 // @Failure 500 {object} httperr.ErrResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /worklogs/start [post]
-func StartWorklog(logger *slog.Logger, worklogStarter WorklogStarter) http.HandlerFunc {
+func StartWorklog(logger *slog.Logger, worklogStarter WorklogStarter, authorizer Authorizer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log := logger.With(
 			slog.String("handler", "StartWorklog"),
@@ -58,16 +70,36 @@ func StartWorklog(logger *slog.Logger, worklogStarter WorklogStarter) http.Handl
 		}
 		defer r.Body.Close()
 
+		if err := authorizer.TrackTime(r.Context(), req.UserID); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
 		// create record in DB
-		worklogID, err := worklogStarter.StartWorklog(r.Context(), req.Task, req.UserID)
+		worklogID, stoppedID, err := worklogStarter.StartWorklog(r.Context(), req.Task, req.UserID, req.ProjectID)
 		if err != nil {
-			log.Error("failed to start worklog", l.Err(err))
+			switch {
+			case errors.Is(err, repo.ErrUserNotFound), errors.Is(err, repo.ErrProjectNotFound):
+				log.Warn("user or project not found", l.Err(err))
+
+				render.Render(w, r, httperr.ErrNotFound(err))
+			case errors.Is(err, repo.ErrWorklogRunning):
+				log.Warn("user already has a running worklog", l.Err(err))
 
-			render.Render(w, r, httperr.ErrInternal(err))
+				render.Render(w, r, httperr.ErrConflict(err))
+			default:
+				log.Error("failed to start worklog", l.Err(err))
+
+				render.Render(w, r, httperr.ErrInternal(err))
+			}
 			return
 		}
 
 		resp := StartWorklogResponse{WorklogID: worklogID}
+		if stoppedID != 0 {
+			resp.StoppedWorklogID = &stoppedID
+			log.Info("running worklog was finished", slog.Int("stopped_id", int(stoppedID)))
+		}
 		log.Info("worklog started successfully", slog.Int("worklog_id", int(worklogID)))
 
 		render.Status(r, http.StatusCreated)
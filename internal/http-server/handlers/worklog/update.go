@@ -0,0 +1,111 @@
+package worklog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/http-server/handlers/authz"
+	"github.com/kuromii5/time-tracker/internal/models"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+type WorklogUpdater interface {
+	UpdateWorklog(ctx context.Context, worklog models.Worklog) error
+}
+
+type UpdateWorklogRequest struct {
+	Task      string `json:"task"`
+	ProjectID int32  `json:"project_id"`
+	// ClearProject takes the worklog out of its project
+	ClearProject bool      `json:"clear_project"`
+	StartTime    time.Time `json:"start_time" description:"Start time in the format YYYY-MM-DDTHH:MM:SSZ (ISO 8601)"`
+	EndTime      time.Time `json:"end_time" description:"End time in the format YYYY-MM-DDTHH:MM:SSZ (ISO 8601)"`
+}
+
+// @Summary Update a worklog
+// @Description Fix the task, project or time range of a worklog. Omitted fields are left unchanged, clear_project removes the project.
+// @Description Setting the end time of a running worklog finishes it. Pauses inside the new range are kept.
+// @Description The worklog must not overlap with other worklogs of the same user.
+// @Tags worklogs
+// @Accept json
+// @Produce json
+// @Param id path int true "Worklog ID"
+// @Param request body UpdateWorklogRequest true "Update Worklog Request"
+// @Success 204 "No Content"
+// @Failure 400 {object} httperr.ErrResponse "Invalid request payload"
+// @Failure 403 {object} httperr.ErrResponse "Forbidden"
+// @Failure 404 {object} httperr.ErrResponse "Worklog or project not found"
+// @Failure 409 {object} httperr.ErrResponse "Worklog overlaps with other worklogs, they are listed in details"
+// @Failure 500 {object} httperr.ErrResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /worklogs/{id} [patch]
+func UpdateWorklog(logger *slog.Logger, worklogUpdater WorklogUpdater, authorizer Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.With(
+			slog.String("handler", "UpdateWorklog"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req UpdateWorklogRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		worklogID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Error("failed to parse worklog ID", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		if err := validateManualWorklog(req.Task, req.StartTime, req.EndTime, false); err != nil {
+			log.Error("invalid worklog", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		if req.ProjectID != 0 && req.ClearProject {
+			err := errors.New("project can't be set and cleared at once")
+			log.Error("invalid project", l.Err(err))
+
+			render.Render(w, r, httperr.ErrInvalidRequest(err))
+			return
+		}
+
+		if err := authorizer.EditWorklog(r.Context(), int32(worklogID)); err != nil {
+			authz.RenderErr(w, r, log, err)
+			return
+		}
+
+		worklog := models.Worklog{
+			ID:           int32(worklogID),
+			ProjectID:    req.ProjectID,
+			ClearProject: req.ClearProject,
+			Task:         req.Task,
+			StartedAt:    req.StartTime,
+			FinishedAt:   req.EndTime,
+		}
+		if err := worklogUpdater.UpdateWorklog(r.Context(), worklog); err != nil {
+			renderManualErr(w, r, log, err)
+			return
+		}
+
+		log.Info("worklog updated successfully", slog.Int("worklog_id", worklogID))
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,64 @@
+package mwauth
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/kuromii5/time-tracker/internal/audit"
+	"github.com/kuromii5/time-tracker/internal/auth"
+	httperr "github.com/kuromii5/time-tracker/pkg/http-errors"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+var errAdminOnly = errors.New("admin access required")
+
+// New rejects unauthenticated requests and puts the principal of the others into the request context
+func New(log *slog.Logger, authenticator *auth.Authenticator) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log.Info("authentication is enabled")
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log := log.With(
+				slog.String("middleware", "auth"),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+			)
+
+			principal, err := authenticator.Authenticate(r)
+			if err != nil {
+				if errors.Is(err, auth.ErrNoCredentials) || errors.Is(err, auth.ErrInvalidCredentials) {
+					log.Warn("request is not authenticated", l.Err(err))
+
+					w.Header().Set("WWW-Authenticate", `Bearer realm="time-tracker"`)
+					render.Render(w, r, httperr.ErrUnauthorized(err))
+					return
+				}
+				log.Error("failed to authenticate request", l.Err(err))
+
+				render.Render(w, r, httperr.ErrInternal(err))
+				return
+			}
+
+			log.Debug("request authenticated", slog.Any("principal", principal))
+
+			ctx := auth.NewContext(r.Context(), principal)
+			ctx = audit.WithActor(ctx, principal.String())
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireAdmin rejects requests of principals that are not admins
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := auth.FromContext(r.Context()); p == nil || !p.IsAdmin() {
+			render.Render(w, r, httperr.ErrForbidden(errAdminOnly))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
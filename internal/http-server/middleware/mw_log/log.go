@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/kuromii5/time-tracker/internal/auth"
 )
 
 func New(log *slog.Logger) func(next http.Handler) http.Handler {
@@ -25,7 +26,12 @@ func New(log *slog.Logger) func(next http.Handler) http.Handler {
 				slog.String("request_id", middleware.GetReqID(r.Context())),
 			)
 
-			next.ServeHTTP(rw, r)
+			// the principal is only known after authentication further down the chain
+			ctx, principal := auth.Track(r.Context())
+			next.ServeHTTP(rw, r.WithContext(ctx))
+			if p := principal(); p != nil {
+				entry = entry.With(slog.String("principal", p.String()))
+			}
 
 			entry.Info("request has been processed",
 				slog.Int("status", rw.Status()),
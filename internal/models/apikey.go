@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// APIKey is an issued API key. The key itself is shown only once and never stored.
+type APIKey struct {
+	ID        int32     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Name      string    `json:"name"`
+	// Prefix is the beginning of the key, to recognize it in lists
+	Prefix string `json:"prefix"`
+	// UserID is the user the key acts as, zero for service keys
+	UserID     int32      `json:"user_id,omitempty"`
+	Admin      bool       `json:"admin"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
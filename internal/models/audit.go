@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audited entities
+const (
+	EntityUser    = "user"
+	EntityWorklog = "worklog"
+	EntityClient  = "client"
+	EntityProject = "project"
+	EntityAPIKey  = "api_key"
+)
+
+// Audited actions
+const (
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionDelete   = "delete"
+	ActionRestore  = "restore"
+	ActionPurge    = "purge"
+	ActionExport   = "export"
+	ActionErase    = "erase"
+	ActionStart    = "start"
+	ActionFinish   = "finish"
+	ActionPause    = "pause"
+	ActionResume   = "resume"
+	ActionAutoStop = "auto_stop"
+	ActionRevoke   = "revoke"
+	ActionEnrich   = "enrich"
+)
+
+// AuditEvent is a recorded change of an entity. Before is null for creations, After for deletions.
+type AuditEvent struct {
+	ID         int64           `json:"id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	Entity     string          `json:"entity"`
+	EntityID   int32           `json:"entity_id"`
+	Before     json.RawMessage `json:"before" swaggertype:"object"`
+	After      json.RawMessage `json:"after" swaggertype:"object"`
+	RequestID  string          `json:"request_id,omitempty"`
+}
+
+// AuditFilter represents the criteria of an audit log query. Zero values mean no filtering.
+type AuditFilter struct {
+	Entity   string    `json:"entity"`
+	EntityID int32     `json:"entity_id"`
+	Actor    string    `json:"actor"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+}
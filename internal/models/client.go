@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type Client struct {
+	ID        int32     `json:"id"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+	Name      string    `json:"name"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Enrichment statuses of users, whose people info is fetched in the background
+const (
+	EnrichmentPending = "pending_enrichment" // waiting for people info
+	EnrichmentDone    = "enriched"           // people info was filled in
+	EnrichmentFailed  = "enrichment_failed"  // people info could not be fetched, the job was dead-lettered
+)
+
+// Statuses of enrichment jobs
+const (
+	JobQueued  = "queued"  // waiting for its run_at
+	JobRunning = "running" // taken by a worker until locked_until
+	JobDone    = "done"
+	JobDead    = "dead" // ran out of attempts or failed for good, kept until retried
+)
+
+// EnrichmentJob fetches people info for a user
+type EnrichmentJob struct {
+	ID       int64  `json:"id"`
+	UserID   int32  `json:"user_id"`
+	Status   string `json:"status"`
+	Attempts int    `json:"attempts"`
+	// RunAt is when the job is run next, for queued jobs
+	RunAt       time.Time  `json:"run_at"`
+	LastError   string     `json:"last_error,omitempty"`
+	CallbackURL string     `json:"callback_url,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+
+	// Passport is what people info is looked up by, it is only filled in for workers
+	Passport Passport `json:"-"`
+}
+
+// Enrichment is the enrichment status of a user together with the user's latest job
+type Enrichment struct {
+	UserID int32          `json:"user_id"`
+	Status string         `json:"status"`
+	Job    *EnrichmentJob `json:"job,omitempty"`
+}
+
+// EnrichmentNotification is posted to the callback URL of a job when it completes or is dead-lettered
+type EnrichmentNotification struct {
+	UserID int32  `json:"user_id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
@@ -0,0 +1,41 @@
+package models
+
+// Outcomes of the rows of an import
+const (
+	ImportCreated   = "created"
+	ImportDuplicate = "duplicate" // already there, or repeated in the file
+	ImportFailed    = "failed"
+)
+
+// ImportRow is the outcome of a row of an imported file
+type ImportRow struct {
+	// Line is where the row starts in the file, counting from 1
+	Line   int    `json:"line"`
+	Status string `json:"status"`
+	// ID of what the row created
+	ID    int32  `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// ImportReport is the outcome of an import row by row
+type ImportReport struct {
+	// DryRun is set when nothing was written, Created counts the rows that would be created then
+	DryRun     bool        `json:"dry_run,omitempty"`
+	Created    int         `json:"created"`
+	Duplicates int         `json:"duplicates"`
+	Failed     int         `json:"failed"`
+	Rows       []ImportRow `json:"rows"`
+}
+
+// Add adds the outcome of a row and counts it
+func (r *ImportReport) Add(row ImportRow) {
+	switch row.Status {
+	case ImportCreated:
+		r.Created++
+	case ImportDuplicate:
+		r.Duplicates++
+	case ImportFailed:
+		r.Failed++
+	}
+	r.Rows = append(r.Rows, row)
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// PersonalData is everything held about a user, as exported on the user's request
+type PersonalData struct {
+	ExportedAt time.Time `json:"exported_at"`
+	User       User      `json:"user"`
+	// CalendarFeed tells whether a calendar feed token was issued, the token itself is not stored
+	CalendarFeed bool      `json:"calendar_feed"`
+	Worklogs     []Worklog `json:"worklogs"`
+	APIKeys      []APIKey  `json:"api_keys"`
+	// History is the audit log of changes of the user's profile
+	History []AuditEvent `json:"history"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type Project struct {
+	ID        int32     `json:"id"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+	ClientID  int32     `json:"client_id,omitempty"`
+	Name      string    `json:"name"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Report dimensions worklog time can be grouped by
+const (
+	GroupByUser  = "user"
+	GroupByTask  = "task"
+	GroupByDay   = "day"
+	GroupByWeek  = "week"
+	GroupByMonth = "month"
+)
+
+// ReportFilter represents the range, grouping and filtering criteria of a time report.
+// Zero values mean no filtering.
+type ReportFilter struct {
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	GroupBy   []string  `json:"group_by"`
+	UserID    int32     `json:"user_id"`
+	ProjectID int32     `json:"project_id"`
+	ClientID  int32     `json:"client_id"`
+	Task      string    `json:"task"`
+	// Timezone is the IANA zone days, weeks and months are counted in.
+	// If empty, every worklog is bucketed in its owner's time zone.
+	Timezone string `json:"timezone"`
+}
+
+// ReportRow is the time tracked for one combination of the grouped dimensions.
+// Only the dimensions the report is grouped by are set.
+type ReportRow struct {
+	UserID   int32         `json:"user_id,omitempty"`
+	Task     string        `json:"task,omitempty"`
+	Day      string        `json:"day,omitempty"`   // YYYY-MM-DD
+	Week     string        `json:"week,omitempty"`  // ISO week, YYYY-Www
+	Month    string        `json:"month,omitempty"` // YYYY-MM
+	Duration time.Duration `json:"duration"`
+	Worklogs int           `json:"worklogs"`
+}
@@ -1,20 +1,79 @@
 package models
 
-import "time"
+import (
+	"log/slog"
+	"time"
+)
+
+// Worklog policies decide what happens when a user starts a worklog
+// while another one is still open
+const (
+	PolicyReject = "reject" // refuse to start a new worklog
+	PolicySwitch = "switch" // finish the open worklog and start a new one
+)
+
+// Roles decide what a user may do
+const (
+	RoleAdmin    = "admin"    // may do everything
+	RoleManager  = "manager"  // may read and correct the worklogs of their team
+	RoleEmployee = "employee" // may only track their own time
+)
 
 type User struct {
-	ID        int32     `json:"id"`
-	CreatedAt time.Time `json:"-"`
-	UpdatedAt time.Time `json:"-"`
-	Passport  Passport  `json:"passport"`
-	People    People    `json:"people"`
+	ID            int32     `json:"id"`
+	CreatedAt     time.Time `json:"-"`
+	UpdatedAt     time.Time `json:"-"`
+	Passport      Passport  `json:"passport"`
+	People        People    `json:"people"`
+	WorklogPolicy string    `json:"worklog_policy"`
+	// EndOfDay is the time of day (HH:MM) after which the user's forgotten worklogs are stopped
+	EndOfDay string `json:"end_of_day,omitempty"`
+	// ClearEndOfDay unsets the end of day on update, as an empty EndOfDay leaves it unchanged
+	ClearEndOfDay bool `json:"-"`
+	// Timezone is an IANA time zone name, e.g. "Europe/Moscow". Days in reports and
+	// the end of day are counted in this zone.
+	Timezone string `json:"timezone"`
+	Role     string `json:"role"`
+	// ManagerID is the manager whose team the user is in, zero if none
+	ManagerID int32 `json:"manager_id,omitempty"`
+	// ClearManager takes the user out of their manager's team on update, as a zero ManagerID leaves it unchanged
+	ClearManager bool `json:"-"`
+	// DeletedAt is set for soft-deleted users until they are restored or purged
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// ErasedAt is set for users whose personal data was erased
+	ErasedAt *time.Time `json:"erased_at,omitempty"`
+	// EnrichmentStatus tells whether the people info was fetched yet, see EnrichmentPending
+	EnrichmentStatus string `json:"enrichment_status"`
 }
 
+// LogValue keeps passports out of logs, see Passport.LogValue
+func (u User) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("id", int(u.ID)),
+		slog.Any("passport", u.Passport),
+		slog.String("worklog_policy", u.WorklogPolicy),
+		slog.String("end_of_day", u.EndOfDay),
+		slog.String("timezone", u.Timezone),
+		slog.String("role", u.Role),
+		slog.Int("manager_id", int(u.ManagerID)),
+		slog.String("enrichment_status", u.EnrichmentStatus),
+	)
+}
+
+// Passport is stored encrypted, see repo
 type Passport struct {
 	Serie  string `json:"serie"`
 	Number string `json:"number"`
 }
 
+// LogValue redacts passports, only whether they are set is logged
+func (p Passport) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Bool("serie_set", p.Serie != ""),
+		slog.Bool("number_set", p.Number != ""),
+	)
+}
+
 type People struct {
 	Name       string `json:"name"`
 	Surname    string `json:"surname"`
@@ -25,6 +84,8 @@ type People struct {
 // FilterBy represents filtering criteria for users.
 // swagger:model
 type FilterBy struct {
+	// Query is searched for in the names and address, case-insensitively
+	Query          string    `json:"q"`
 	Name           string    `json:"name"`
 	Surname        string    `json:"surname"`
 	Patronymic     string    `json:"patronymic"`
@@ -33,9 +94,43 @@ type FilterBy struct {
 	CreatedBefore  time.Time `json:"created_before"`
 	PassportSerie  string    `json:"passport_serie"`
 	PassportNumber string    `json:"passport_number"`
+	// IncludeDeleted lists soft-deleted users as well
+	IncludeDeleted bool `json:"include_deleted"`
+	// TeamOf limits users to the manager and their team
+	TeamOf int32 `json:"-"`
+}
+
+// LogValue redacts the passport criteria
+func (f FilterBy) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("q", f.Query),
+		slog.String("name", f.Name),
+		slog.String("surname", f.Surname),
+		slog.String("patronymic", f.Patronymic),
+		slog.String("address", f.Address),
+		slog.Time("created_after", f.CreatedAfter),
+		slog.Time("created_before", f.CreatedBefore),
+		slog.Any("passport", Passport{Serie: f.PassportSerie, Number: f.PassportNumber}),
+		slog.Bool("include_deleted", f.IncludeDeleted),
+		slog.Int("team_of", int(f.TeamOf)),
+	)
 }
 
 type Pagination struct {
 	Limit  int `json:"limit"`
 	Offset int `json:"offset"`
+	// Cursor continues a listing from the next or previous cursor of a page, offset is ignored then
+	Cursor string `json:"cursor"`
+	// WithTotal counts all the rows of the listing
+	WithTotal bool `json:"with_total"`
+	// Sort orders the listing by comma-separated keys, "-" in front of a key reverses it, e.g. "-created_at,surname"
+	Sort string `json:"sort"`
+}
+
+// Page describes where a page of a listing is
+type Page struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	// Total is the number of rows of the listing, if it was asked for
+	Total *int `json:"total,omitempty"`
 }
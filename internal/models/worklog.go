@@ -2,11 +2,64 @@ package models
 
 import "time"
 
+// Worklog statuses
+const (
+	WorklogRunning  = "running"
+	WorklogPaused   = "paused"
+	WorklogFinished = "finished"
+)
+
 type Worklog struct {
 	ID         int32         `json:"id"`
 	UserID     int32         `json:"user_id"`
+	ProjectID  int32         `json:"project_id,omitempty"`
 	Task       string        `json:"task"`
+	Status     string        `json:"status"`
 	StartedAt  time.Time     `json:"start_time"`
 	FinishedAt time.Time     `json:"end_time"`
-	Duration   time.Duration `json:"duration"`
+	Duration   time.Duration `json:"duration" swaggertype:"integer"`
+	Paused     time.Duration `json:"paused" swaggertype:"integer"`
+	// AutoStopped is set when a forgotten worklog was stopped automatically and is waiting for review
+	AutoStopped bool `json:"auto_stopped"`
+	// ClearProject takes the worklog out of its project on update, as a zero ProjectID leaves it unchanged
+	ClearProject bool `json:"-"`
+}
+
+// WorklogFilter represents optional filtering criteria for worklogs.
+// Zero values mean no filtering.
+type WorklogFilter struct {
+	// From and To limit worklogs to those overlapping the range, unfinished worklogs last until now
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	ProjectID   int32     `json:"project_id"`
+	ClientID    int32     `json:"client_id"`
+	AutoStopped bool      `json:"auto_stopped"` // only auto-stopped worklogs
+	// Status is one of the worklog statuses
+	Status string `json:"status"`
+	// Task is searched for in the task, case-insensitively
+	Task string `json:"task"`
+	// MinDuration is the least active time of worklogs
+	MinDuration time.Duration `json:"min_duration"`
+}
+
+// ExportFilter represents the range and filtering criteria of a worklogs export.
+// Zero values mean no filtering.
+type ExportFilter struct {
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	UserID    int32     `json:"user_id"`
+	ProjectID int32     `json:"project_id"`
+	ClientID  int32     `json:"client_id"`
+}
+
+// WorklogExportRow is a worklog together with its owner and project names
+type WorklogExportRow struct {
+	Worklog
+	Name       string `json:"name"`
+	Surname    string `json:"surname"`
+	Patronymic string `json:"patronymic"`
+	Project    string `json:"project"`
+	Client     string `json:"client"`
+	// Timezone is the time zone of the worklog owner
+	Timezone string `json:"timezone"`
 }
@@ -0,0 +1,95 @@
+package peopleinfo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	b := newBreaker(3, time.Minute)
+	b.now = func() time.Time { return now }
+
+	// each step makes a request if it is allowed and records its outcome
+	steps := []struct {
+		name    string
+		advance time.Duration
+		allowed bool
+		ok      bool
+	}{
+		{name: "closed", allowed: true, ok: false},
+		{name: "success resets the failures", allowed: true, ok: true},
+		{name: "first failure", allowed: true, ok: false},
+		{name: "second failure", allowed: true, ok: false},
+		{name: "third failure opens", allowed: true, ok: false},
+		{name: "open", allowed: false},
+		{name: "open within cooldown", advance: 59 * time.Second, allowed: false},
+		{name: "trial after cooldown fails", advance: time.Second, allowed: true, ok: false},
+		{name: "open again", advance: 30 * time.Second, allowed: false},
+		{name: "trial after the new cooldown succeeds", advance: 30 * time.Second, allowed: true, ok: true},
+		{name: "closed again", allowed: true, ok: true},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		allowed := b.allow()
+		if allowed != step.allowed {
+			t.Fatalf("%s: allow() = %v, want %v", step.name, allowed, step.allowed)
+		}
+		if allowed {
+			b.record(step.ok)
+		}
+	}
+}
+
+func TestBreakerSingleTrial(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	b := newBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.allow()
+	b.record(false)
+	now = now.Add(time.Minute)
+
+	if !b.allow() {
+		t.Fatal("allow() = false after the cooldown, want a trial")
+	}
+	if b.allow() {
+		t.Error("allow() = true while the trial is in flight")
+	}
+	b.record(true)
+	if !b.allow() {
+		t.Error("allow() = false after the trial succeeded")
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := newBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		if !b.allow() {
+			t.Fatalf("allow() = false after %d failures, want the breaker disabled", i)
+		}
+		b.record(false)
+	}
+}
+
+func TestBreakerRelease(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	b := newBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.allow()
+	b.record(false)
+	now = now.Add(time.Minute)
+
+	// a trial whose caller gave up lets the next one through, and doesn't close the breaker
+	b.allow()
+	b.release()
+	if !b.allow() {
+		t.Error("allow() = false after the trial was released, want another trial")
+	}
+	b.release()
+	if b.failures != 1 {
+		t.Errorf("failures = %d after releases, want 1", b.failures)
+	}
+}
@@ -0,0 +1,196 @@
+package peopleinfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+// Entry is a cached answer of the API. People is nil if the API doesn't know anybody with the passport.
+type Entry struct {
+	People    *models.People
+	ExpiresAt time.Time
+}
+
+// Store keeps cached answers by passport. Expired entries must not be returned,
+// and are removed for good by SweepPeopleInfoCache.
+type Store interface {
+	CachedPeopleInfo(ctx context.Context, passport models.Passport) (Entry, bool, error)
+	CachePeopleInfo(ctx context.Context, passport models.Passport, entry Entry) error
+	InvalidatePeopleInfo(ctx context.Context, passport models.Passport) (int, error)
+	ClearPeopleInfoCache(ctx context.Context) (int, error)
+	SweepPeopleInfoCache(ctx context.Context) (int, error)
+}
+
+type Fetcher interface {
+	Info(ctx context.Context, passport models.Passport) (models.People, error)
+}
+
+// Cache answers lookups from the store and asks the fetcher only on misses.
+// Unknown passports are cached too, for negativeTTL, so that retries don't hammer the API.
+// Errors of the store are logged and the lookup falls through to the fetcher.
+type Cache struct {
+	log         *slog.Logger
+	fetcher     Fetcher
+	store       Store
+	ttl         time.Duration
+	negativeTTL time.Duration
+}
+
+// NewCache creates a cache in front of the fetcher. A nil store or zero TTL disables caching
+// of found people, zero negativeTTL disables caching of unknown passports.
+func NewCache(log *slog.Logger, fetcher Fetcher, store Store, ttl, negativeTTL time.Duration) *Cache {
+	return &Cache{
+		log:         log.With(slog.String("client", "peopleinfo.cache")),
+		fetcher:     fetcher,
+		store:       store,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+	}
+}
+
+// Info returns what is known about the owner of the passport
+func (c *Cache) Info(ctx context.Context, passport models.Passport) (models.People, error) {
+	if c.store == nil {
+		return c.fetcher.Info(ctx, passport)
+	}
+
+	entry, found, err := c.store.CachedPeopleInfo(ctx, passport)
+	if err != nil {
+		c.log.Error("failed to read cached people info", l.Err(err))
+	}
+	if found {
+		if entry.People == nil {
+			return models.People{}, fmt.Errorf("%w (cached)", ErrNotFound)
+		}
+		return *entry.People, nil
+	}
+
+	people, err := c.fetcher.Info(ctx, passport)
+	switch {
+	case err == nil && c.ttl > 0:
+		c.cache(ctx, passport, Entry{People: &people, ExpiresAt: time.Now().Add(c.ttl)})
+	case errors.Is(err, ErrNotFound) && c.negativeTTL > 0:
+		c.cache(ctx, passport, Entry{ExpiresAt: time.Now().Add(c.negativeTTL)})
+	}
+
+	return people, err
+}
+
+func (c *Cache) cache(ctx context.Context, passport models.Passport, entry Entry) {
+	if err := c.store.CachePeopleInfo(ctx, passport, entry); err != nil {
+		c.log.Error("failed to cache people info", l.Err(err))
+	}
+}
+
+// Invalidate removes the cached answer for the passport and returns the number of removed entries
+func (c *Cache) Invalidate(ctx context.Context, passport models.Passport) (int, error) {
+	if c.store == nil {
+		return 0, nil
+	}
+	return c.store.InvalidatePeopleInfo(ctx, passport)
+}
+
+// Clear removes all cached answers and returns their number
+func (c *Cache) Clear(ctx context.Context) (int, error) {
+	if c.store == nil {
+		return 0, nil
+	}
+	return c.store.ClearPeopleInfoCache(ctx)
+}
+
+// Sweep removes expired answers, which hold personal data nobody needs anymore, and returns their number
+func (c *Cache) Sweep(ctx context.Context) (int, error) {
+	if c.store == nil {
+		return 0, nil
+	}
+	return c.store.SweepPeopleInfoCache(ctx)
+}
+
+// MemoryStore keeps cached answers in the process
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[models.Passport]Entry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[models.Passport]Entry)}
+}
+
+func (s *MemoryStore) CachedPeopleInfo(_ context.Context, passport models.Passport) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[passport]
+	if !ok {
+		return Entry{}, false, nil
+	}
+	if !time.Now().Before(entry.ExpiresAt) {
+		delete(s.entries, passport)
+		return Entry{}, false, nil
+	}
+
+	return entry, true, nil
+}
+
+func (s *MemoryStore) CachePeopleInfo(_ context.Context, passport models.Passport, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[passport] = entry
+
+	// entries are only dropped when looked up, sweep the rest once in a while
+	if len(s.entries)%1024 == 0 {
+		s.sweep()
+	}
+
+	return nil
+}
+
+// sweep removes expired entries, s.mu must be held
+func (s *MemoryStore) sweep() int {
+	now := time.Now()
+	count := 0
+	for p, e := range s.entries {
+		if !now.Before(e.ExpiresAt) {
+			delete(s.entries, p)
+			count++
+		}
+	}
+	return count
+}
+
+func (s *MemoryStore) InvalidatePeopleInfo(_ context.Context, passport models.Passport) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[passport]; !ok {
+		return 0, nil
+	}
+	delete(s.entries, passport)
+
+	return 1, nil
+}
+
+func (s *MemoryStore) ClearPeopleInfoCache(_ context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := len(s.entries)
+	s.entries = make(map[models.Passport]Entry)
+
+	return count, nil
+}
+
+func (s *MemoryStore) SweepPeopleInfoCache(_ context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sweep(), nil
+}
@@ -0,0 +1,180 @@
+// Package peopleinfo is a client of the external API that knows people by their passports.
+package peopleinfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+var (
+	// ErrNotFound means the API doesn't know anybody with the passport
+	ErrNotFound = errors.New("no person with such passport")
+	// ErrRejected means the API refused the request, retrying won't help
+	ErrRejected = errors.New("people info request rejected")
+	// ErrUnavailable means the API could not be reached or failed, possibly for a while
+	ErrUnavailable = errors.New("people info api is unavailable")
+	// ErrCircuitOpen means requests are not sent, because the API failed too many times in a row.
+	// It matches ErrUnavailable with errors.Is.
+	ErrCircuitOpen = fmt.Errorf("%w: circuit breaker is open", ErrUnavailable)
+)
+
+// StatusError is an unexpected status returned by the API.
+// It matches ErrNotFound, ErrRejected or ErrUnavailable with errors.Is, depending on the status.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("people info api responded with %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.retryable():
+		return ErrUnavailable
+	default:
+		return ErrRejected
+	}
+}
+
+func (e *StatusError) retryable() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+// Config holds the address of the API and how hard to try reaching it
+type Config struct {
+	BaseURL string
+	// Timeout limits every attempt
+	Timeout time.Duration
+	// Retries is how many times a failed request is retried, only failures of the API are retried
+	Retries int
+	// Backoff is the delay before the first retry, it doubles with every retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// BreakerThreshold failed requests in a row open the circuit breaker for BreakerCooldown.
+	// Zero threshold disables the breaker.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+type Client struct {
+	log     *slog.Logger
+	cfg     Config
+	baseURL *url.URL
+	http    *http.Client
+	breaker *breaker
+}
+
+func New(log *slog.Logger, cfg Config) (*Client, error) {
+	baseURL, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", "peopleinfo.New", err)
+	}
+	if baseURL.Scheme != "http" && baseURL.Scheme != "https" {
+		return nil, fmt.Errorf("%s: base url %q should be http or https", "peopleinfo.New", cfg.BaseURL)
+	}
+
+	return &Client{
+		log:     log.With(slog.String("client", "peopleinfo")),
+		cfg:     cfg,
+		baseURL: baseURL,
+		http:    &http.Client{Timeout: cfg.Timeout},
+		breaker: newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+	}, nil
+}
+
+// Info returns what the API knows about the owner of the passport
+func (c *Client) Info(ctx context.Context, passport models.Passport) (models.People, error) {
+	if !c.breaker.allow() {
+		return models.People{}, ErrCircuitOpen
+	}
+
+	people, err := c.infoWithRetries(ctx, passport)
+	if ctx.Err() != nil {
+		// the caller gave up, which says nothing about the API
+		c.breaker.release()
+		return models.People{}, ctx.Err()
+	}
+	// only failures of the API count, not answers it didn't like
+	c.breaker.record(err == nil || !errors.Is(err, ErrUnavailable))
+
+	return people, err
+}
+
+func (c *Client) infoWithRetries(ctx context.Context, passport models.Passport) (models.People, error) {
+	backoff := c.cfg.Backoff
+	for attempt := 0; ; attempt++ {
+		people, err := c.info(ctx, passport)
+		if err == nil || !errors.Is(err, ErrUnavailable) || attempt >= c.cfg.Retries {
+			return people, err
+		}
+
+		// full jitter keeps clients that failed together from retrying together
+		delay := rand.N(backoff + 1)
+		c.log.Warn("people info request failed, retrying",
+			slog.Int("attempt", attempt+1),
+			slog.Duration("delay", delay),
+			l.Err(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return models.People{}, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		backoff *= 2
+		if c.cfg.MaxBackoff > 0 && backoff > c.cfg.MaxBackoff {
+			backoff = c.cfg.MaxBackoff
+		}
+	}
+}
+
+func (c *Client) info(ctx context.Context, passport models.Passport) (models.People, error) {
+	u := c.baseURL.JoinPath("info")
+	u.RawQuery = url.Values{
+		"passportSerie":  {passport.Serie},
+		"passportNumber": {passport.Number},
+	}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return models.People{}, fmt.Errorf("%s: %w", "peopleinfo.info", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return models.People{}, ctx.Err()
+		}
+		return models.People{}, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return models.People{}, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var people models.People
+	if err := json.NewDecoder(resp.Body).Decode(&people); err != nil {
+		return models.People{}, fmt.Errorf("%w: failed to decode response body: %w", ErrUnavailable, err)
+	}
+
+	return people, nil
+}
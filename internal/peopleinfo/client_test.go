@@ -0,0 +1,43 @@
+package peopleinfo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+)
+
+func TestInfoCanceledByCaller(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	c, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), Config{
+		BaseURL:          server.URL,
+		Timeout:          time.Minute,
+		Retries:          2,
+		BreakerThreshold: 1,
+		BreakerCooldown:  time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		_, err := c.Info(ctx, models.Passport{Serie: "1234", Number: "567890"})
+		cancel()
+
+		// the second call would fail with ErrCircuitOpen if the first one had counted as a failure of the API
+		if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrUnavailable) {
+			t.Fatalf("call %d: Info() error = %v, want %v", i+1, err, context.DeadlineExceeded)
+		}
+	}
+}
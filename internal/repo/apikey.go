@@ -0,0 +1,177 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kuromii5/time-tracker/internal/models"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+const apiKeyColumns = "id, created_at, name, prefix, COALESCE(user_id, 0), admin, last_used_at, revoked_at"
+
+func scanAPIKey(row pgx.Row) (models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(&key.ID, &key.CreatedAt, &key.Name, &key.Prefix, &key.UserID, &key.Admin, &key.LastUsedAt, &key.RevokedAt)
+	return key, err
+}
+
+// CreateAPIKey stores a new key by its hash
+func (db *DB) CreateAPIKey(ctx context.Context, key models.APIKey, keyHash string) (int32, error) {
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, user_id, admin, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, 0), $5, NOW())
+		RETURNING id
+	`
+	log := db.log.With(slog.String("name", key.Name), slog.String("prefix", key.Prefix))
+	log.Debug("executing query", slog.String("query", query))
+
+	var keyID int32
+	err := db.audited(ctx, models.ActionCreate, models.EntityAPIKey, 0, func(tx pgx.Tx) (int32, error) {
+		err := tx.QueryRow(ctx, query, key.Name, key.Prefix, keyHash, key.UserID, key.Admin).Scan(&keyID)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+				log.Warn("user not found", slog.Int("user_id", int(key.UserID)))
+
+				return 0, ErrUserNotFound
+			}
+			log.Error("failed to execute query", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.CreateAPIKey", err)
+		}
+		return keyID, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Debug("successfully created api key", slog.Int("key_id", int(keyID)))
+
+	return keyID, nil
+}
+
+// APIKeys returns all keys, including revoked ones
+func (db *DB) APIKeys(ctx context.Context) ([]models.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys ORDER BY id"
+
+	log := db.log
+	log.Debug("executing query", slog.String("query", query))
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		log.Error("failed to execute query", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.APIKeys", err)
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			log.Error("failed to scan row", l.Err(err))
+
+			return nil, fmt.Errorf("%s: %w", "repo.APIKeys", err)
+		}
+
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("rows error", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.APIKeys", err)
+	}
+
+	log.Debug("successfully retrieved api keys", slog.Int("count", len(keys)))
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an active key. Revoked keys are kept for the record.
+func (db *DB) RevokeAPIKey(ctx context.Context, id int32) error {
+	query := "UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL"
+
+	log := db.log.With(slog.Int("key_id", int(id)))
+	log.Debug("executing query", slog.String("query", query))
+
+	err := db.audited(ctx, models.ActionRevoke, models.EntityAPIKey, id, func(tx pgx.Tx) (int32, error) {
+		tag, err := tx.Exec(ctx, query, id)
+		if err != nil {
+			log.Error("failed to execute query", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.RevokeAPIKey", err)
+		}
+		if tag.RowsAffected() == 0 {
+			log.Warn("active api key not found")
+
+			return 0, ErrAPIKeyNotFound
+		}
+		return id, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Debug("successfully revoked api key")
+
+	return nil
+}
+
+// UseAPIKey finds an active key by its hash and records that it was used
+func (db *DB) UseAPIKey(ctx context.Context, keyHash string) (models.APIKey, error) {
+	query := `
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE key_hash = $1 AND revoked_at IS NULL
+		RETURNING ` + apiKeyColumns
+
+	log := db.log
+	log.Debug("executing query", slog.String("query", query))
+
+	key, err := scanAPIKey(db.pool.QueryRow(ctx, query, keyHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Warn("active api key not found")
+
+			return models.APIKey{}, ErrAPIKeyNotFound
+		}
+		log.Error("failed to execute query", l.Err(err))
+
+		return models.APIKey{}, fmt.Errorf("%s: %w", "repo.UseAPIKey", err)
+	}
+
+	return key, nil
+}
+
+// revokeUserAPIKeys revokes the active keys bound to the user in tx, auditing every revocation
+func revokeUserAPIKeys(ctx context.Context, tx pgx.Tx, userID int32) error {
+	rows, err := tx.Query(ctx, "SELECT id FROM api_keys WHERE user_id = $1 AND revoked_at IS NULL ORDER BY id FOR UPDATE", userID)
+	if err != nil {
+		return err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		before, err := snapshot(ctx, tx, models.EntityAPIKey, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, "UPDATE api_keys SET revoked_at = NOW() WHERE id = $1", id); err != nil {
+			return err
+		}
+		if err := auditChange(ctx, tx, models.ActionRevoke, models.EntityAPIKey, id, before); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+	"github.com/kuromii5/time-tracker/internal/audit"
+	"github.com/kuromii5/time-tracker/internal/models"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+// auditedEntity describes how to take a snapshot of an entity row for the audit log
+type auditedEntity struct {
+	// lock locks the entity for the rest of the transaction, respecting the order
+	// of locks taken by the changes themselves, and selects whether the entity may change
+	lock string
+	// frozen is returned when the entity exists but may not change
+	frozen error
+	// snapshot selects the row as JSON without its secrets
+	snapshot string
+}
+
+// worklogSnapshot is the JSON of worklog t together with its segments
+const worklogSnapshot = `
+	to_jsonb(t) || jsonb_build_object('segments', COALESCE((
+		SELECT jsonb_agg(jsonb_build_object('started_at', s.started_at, 'finished_at', s.finished_at) ORDER BY s.started_at)
+		FROM worklog_segments s WHERE s.worklog_id = t.id
+	), '[]'::jsonb))`
+
+// userSnapshot is the JSON of user t without the calendar token and personal data,
+// so the audit log keeps no copies of passports, names and addresses
+var userSnapshot = "to_jsonb(t) - 'calendar_token_hash' - '{" + strings.Join(personalDataFields, ",") + "}'::text[]"
+
+var auditedEntities = map[string]auditedEntity{
+	models.EntityUser: {
+		lock:     "SELECT TRUE FROM users WHERE id = $1 FOR UPDATE",
+		snapshot: "SELECT " + userSnapshot + " FROM users t WHERE id = $1",
+	},
+	models.EntityWorklog: {
+		// worklog changes lock the owner first, and worklogs of soft-deleted users can't change, see lockUser
+		lock:     "SELECT u.deleted_at IS NULL FROM users u JOIN worklogs w ON w.user_id = u.id WHERE w.id = $1 FOR UPDATE OF u",
+		frozen:   ErrUserNotFound,
+		snapshot: "SELECT " + worklogSnapshot + " FROM worklogs t WHERE id = $1",
+	},
+	models.EntityClient: {
+		lock:     "SELECT TRUE FROM clients WHERE id = $1 FOR UPDATE",
+		snapshot: "SELECT to_jsonb(t) FROM clients t WHERE id = $1",
+	},
+	models.EntityProject: {
+		lock:     "SELECT TRUE FROM projects WHERE id = $1 FOR UPDATE",
+		snapshot: "SELECT to_jsonb(t) FROM projects t WHERE id = $1",
+	},
+	models.EntityAPIKey: {
+		lock:     "SELECT TRUE FROM api_keys WHERE id = $1 FOR UPDATE",
+		snapshot: "SELECT to_jsonb(t) - 'key_hash' FROM api_keys t WHERE id = $1",
+	},
+}
+
+// lockEntity locks the entity and returns its frozen error if it may not change.
+// It is not an error if the entity does not exist, the change reports that.
+func lockEntity(ctx context.Context, tx pgx.Tx, entity string, id int32) error {
+	var mayChange bool
+	err := tx.QueryRow(ctx, auditedEntities[entity].lock, id).Scan(&mayChange)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if !mayChange {
+		return auditedEntities[entity].frozen
+	}
+	return nil
+}
+
+// snapshot returns the entity row as JSON, or nil if it does not exist
+func snapshot(ctx context.Context, tx pgx.Tx, entity string, id int32) ([]byte, error) {
+	var row []byte
+	err := tx.QueryRow(ctx, auditedEntities[entity].snapshot, id).Scan(&row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return row, err
+}
+
+// recordAudit writes an audit event in tx. The actor is taken from audit.Actor
+// and the request ID from middleware.GetReqID of ctx.
+func recordAudit(ctx context.Context, tx pgx.Tx, action, entity string, id int32, before, after []byte) error {
+	query := `
+		INSERT INTO audit_events (actor, action, entity, entity_id, before, after, request_id, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NOW())
+	`
+	_, err := tx.Exec(ctx, query, audit.Actor(ctx), action, entity, id, before, after, middleware.GetReqID(ctx))
+	return err
+}
+
+// auditChange snapshots the entity after a change in tx and records the change
+func auditChange(ctx context.Context, tx pgx.Tx, action, entity string, id int32, before []byte) error {
+	after, err := snapshot(ctx, tx, entity, id)
+	if err != nil {
+		return err
+	}
+	return recordAudit(ctx, tx, action, entity, id, before, after)
+}
+
+// audited runs change in a transaction and records it in the audit log of the same transaction.
+// The entity is snapshotted before and after the change, change returns the ID of the
+// changed entity, which is how creations report the ID of the new entity (id is zero for them).
+// Errors of change, and the frozen error of the entity, are returned as they are.
+func (db *DB) audited(ctx context.Context, action, entity string, id int32, change func(tx pgx.Tx) (int32, error)) error {
+	log := db.log.With(slog.String("action", action), slog.String("entity", entity), slog.Int("entity_id", int(id)))
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", l.Err(err))
+
+		return fmt.Errorf("%s: %w", "repo.audited", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var before []byte
+	if id != 0 {
+		if err := lockEntity(ctx, tx, entity, id); err != nil {
+			if errors.Is(err, auditedEntities[entity].frozen) {
+				log.Warn("entity may not change", l.Err(err))
+
+				return err
+			}
+			log.Error("failed to lock entity", l.Err(err))
+
+			return fmt.Errorf("%s: %w", "repo.audited", err)
+		}
+		if before, err = snapshot(ctx, tx, entity, id); err != nil {
+			log.Error("failed to snapshot entity", l.Err(err))
+
+			return fmt.Errorf("%s: %w", "repo.audited", err)
+		}
+	}
+
+	id, err = change(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := auditChange(ctx, tx, action, entity, id, before); err != nil {
+		log.Error("failed to record audit event", l.Err(err))
+
+		return fmt.Errorf("%s: %w", "repo.audited", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit transaction", l.Err(err))
+
+		return fmt.Errorf("%s: %w", "repo.audited", err)
+	}
+
+	return nil
+}
+
+const auditEventColumns = "id, occurred_at, actor, action, entity, entity_id, before, after, COALESCE(request_id, '')"
+
+func scanAuditEvent(row pgx.Row) (models.AuditEvent, error) {
+	var e models.AuditEvent
+	err := row.Scan(&e.ID, &e.OccurredAt, &e.Actor, &e.Action, &e.Entity, &e.EntityID, &e.Before, &e.After, &e.RequestID)
+	return e, err
+}
+
+// AuditEvents returns the audit events matching the filter, newest first
+func (db *DB) AuditEvents(ctx context.Context, filter models.AuditFilter, settings models.Pagination) ([]models.AuditEvent, error) {
+	var query strings.Builder
+	query.WriteString(`
+		SELECT ` + auditEventColumns + `
+		FROM audit_events WHERE 1=1`)
+	var args []interface{}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		query.WriteString(fmt.Sprintf(" AND "+condition, len(args)))
+	}
+
+	if filter.Entity != "" {
+		add("entity = $%d", filter.Entity)
+	}
+	if filter.EntityID != 0 {
+		add("entity_id = $%d", filter.EntityID)
+	}
+	if filter.Actor != "" {
+		add("actor = $%d", filter.Actor)
+	}
+	if !filter.From.IsZero() {
+		add("occurred_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("occurred_at < $%d", filter.To)
+	}
+	query.WriteString(" ORDER BY occurred_at DESC, id DESC")
+	if settings.Limit > 0 {
+		args = append(args, settings.Limit)
+		query.WriteString(fmt.Sprintf(" LIMIT $%d", len(args)))
+	}
+	if settings.Offset > 0 {
+		args = append(args, settings.Offset)
+		query.WriteString(fmt.Sprintf(" OFFSET $%d", len(args)))
+	}
+
+	log := db.log.With(slog.Any("filter", filter), slog.Any("pagination", settings))
+	log.Debug("executing query", slog.String("query", query.String()), slog.Any("args", args))
+
+	rows, err := db.pool.Query(ctx, query.String(), args...)
+	if err != nil {
+		log.Error("failed to execute query", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.AuditEvents", err)
+	}
+	defer rows.Close()
+
+	var events []models.AuditEvent
+	for rows.Next() {
+		e, err := scanAuditEvent(rows)
+		if err != nil {
+			log.Error("failed to scan row", l.Err(err))
+
+			return nil, fmt.Errorf("%s: %w", "repo.AuditEvents", err)
+		}
+
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("rows error", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.AuditEvents", err)
+	}
+
+	log.Debug("successfully retrieved audit events", slog.Int("count", len(events)))
+
+	return events, nil
+}
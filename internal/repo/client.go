@@ -0,0 +1,152 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kuromii5/time-tracker/internal/models"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+var (
+	ErrClientNotFound  = errors.New("client not found")
+	ErrClientDuplicate = errors.New("client with such name already exists")
+)
+
+func (db *DB) CreateClient(ctx context.Context, client models.Client) (int32, error) {
+	query := `
+		INSERT INTO clients (name, created_at, updated_at)
+		VALUES ($1, NOW(), NOW())
+		RETURNING id
+	`
+	log := db.log.With(slog.Any("client", client))
+	log.Debug("executing query", slog.String("query", query))
+
+	var clientID int32
+	err := db.audited(ctx, models.ActionCreate, models.EntityClient, 0, func(tx pgx.Tx) (int32, error) {
+		err := tx.QueryRow(ctx, query, client.Name).Scan(&clientID)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				log.Warn("client with such name already exists", l.Err(ErrClientDuplicate))
+
+				return 0, ErrClientDuplicate
+			}
+			log.Error("failed to execute query", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.CreateClient", err)
+		}
+		return clientID, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Debug("successfully created client", slog.Int("client_id", int(clientID)))
+
+	return clientID, nil
+}
+
+func (db *DB) Clients(ctx context.Context) ([]models.Client, error) {
+	query := "SELECT id, created_at, updated_at, name FROM clients ORDER BY name"
+
+	log := db.log
+	log.Debug("executing query", slog.String("query", query))
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		log.Error("failed to execute query", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.Clients", err)
+	}
+	defer rows.Close()
+
+	var clients []models.Client
+	for rows.Next() {
+		var client models.Client
+		if err := rows.Scan(&client.ID, &client.CreatedAt, &client.UpdatedAt, &client.Name); err != nil {
+			log.Error("failed to scan row", l.Err(err))
+
+			return nil, fmt.Errorf("%s: %w", "repo.Clients", err)
+		}
+
+		clients = append(clients, client)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("rows error", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.Clients", err)
+	}
+
+	log.Debug("successfully retrieved clients", slog.Int("count", len(clients)))
+
+	return clients, nil
+}
+
+func (db *DB) UpdateClient(ctx context.Context, client models.Client) error {
+	query := "UPDATE clients SET name = $2, updated_at = NOW() WHERE id = $1"
+
+	log := db.log.With(slog.Any("client", client))
+	log.Debug("executing query", slog.String("query", query))
+
+	err := db.audited(ctx, models.ActionUpdate, models.EntityClient, client.ID, func(tx pgx.Tx) (int32, error) {
+		tag, err := tx.Exec(ctx, query, client.ID, client.Name)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				log.Warn("client with such name already exists", l.Err(ErrClientDuplicate))
+
+				return 0, ErrClientDuplicate
+			}
+			log.Error("failed to execute query", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.UpdateClient", err)
+		}
+		if tag.RowsAffected() == 0 {
+			log.Warn("client not found")
+
+			return 0, ErrClientNotFound
+		}
+		return client.ID, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Debug("successfully updated client")
+
+	return nil
+}
+
+func (db *DB) DeleteClient(ctx context.Context, id int32) error {
+	query := "DELETE FROM clients WHERE id = $1"
+
+	log := db.log.With(slog.Int("client_id", int(id)))
+	log.Debug("executing query", slog.String("query", query))
+
+	err := db.audited(ctx, models.ActionDelete, models.EntityClient, id, func(tx pgx.Tx) (int32, error) {
+		tag, err := tx.Exec(ctx, query, id)
+		if err != nil {
+			log.Error("failed to execute query", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.DeleteClient", err)
+		}
+		if tag.RowsAffected() == 0 {
+			log.Warn("client not found")
+
+			return 0, ErrClientNotFound
+		}
+		return id, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Debug("successfully deleted client")
+
+	return nil
+}
@@ -7,15 +7,19 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kuromii5/time-tracker/pkg/fieldcrypt"
 	l "github.com/kuromii5/time-tracker/pkg/logger"
 )
 
 type DB struct {
-	pool *pgxpool.Pool
-	log  *slog.Logger
+	pool    *pgxpool.Pool
+	log     *slog.Logger
+	keyring *fieldcrypt.Keyring
 }
 
-func New(dbURL string, log *slog.Logger) (*DB, error) {
+// New connects to the database. Passports are encrypted with keyring,
+// if it is nil they can't be read or written.
+func New(dbURL string, log *slog.Logger, keyring *fieldcrypt.Keyring) (*DB, error) {
 	config, err := pgxpool.ParseConfig(dbURL)
 	if err != nil {
 		log.Error("failed to parse config", l.Err(err))
@@ -43,7 +47,7 @@ func New(dbURL string, log *slog.Logger) (*DB, error) {
 	}
 
 	log.Debug("database connection pool created")
-	return &DB{pool: pool, log: log}, nil
+	return &DB{pool: pool, log: log, keyring: keyring}, nil
 }
 
 func (db *DB) Close() {
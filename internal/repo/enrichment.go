@@ -0,0 +1,355 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kuromii5/time-tracker/internal/models"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+var (
+	ErrEnrichmentJobNotFound = errors.New("enrichment job not found")
+	// ErrEnrichmentJobLost means the lease of the job expired and it was taken by another worker
+	ErrEnrichmentJobLost = errors.New("enrichment job was taken over by another worker")
+)
+
+const enrichmentJobColumns = "j.id, j.user_id, j.status, j.attempts, j.run_at, COALESCE(j.last_error, ''), COALESCE(j.callback_url, ''), j.created_at, j.updated_at, j.finished_at"
+
+func scanEnrichmentJob(row pgx.Row, extra ...any) (models.EnrichmentJob, error) {
+	var j models.EnrichmentJob
+	dest := []any{&j.ID, &j.UserID, &j.Status, &j.Attempts, &j.RunAt, &j.LastError, &j.CallbackURL, &j.CreatedAt, &j.UpdatedAt, &j.FinishedAt}
+	err := row.Scan(append(dest, extra...)...)
+	return j, err
+}
+
+// CreateUserForEnrichment creates a user of whom only the passport is known yet, in the pending
+// enrichment status, and queues a job to fetch the people info in the same transaction.
+// The callback URL, if any, is notified when the job completes or is dead-lettered.
+func (db *DB) CreateUserForEnrichment(ctx context.Context, passport models.Passport, callbackURL string) (int32, error) {
+	log := db.log.With(slog.Any("passport", passport))
+
+	encrypted, index, err := db.encryptPassport(passport)
+	if err != nil {
+		log.Error("failed to encrypt passport", l.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", "repo.CreateUserForEnrichment", err)
+	}
+
+	jobQuery := "INSERT INTO enrichment_jobs (user_id, callback_url) VALUES ($1, NULLIF($2, ''))"
+
+	var userId int32
+	err = db.audited(ctx, models.ActionCreate, models.EntityUser, 0, func(tx pgx.Tx) (int32, error) {
+		user := models.User{Passport: passport, EnrichmentStatus: models.EnrichmentPending}
+		if userId, err = db.insertUser(ctx, tx, log, user, encrypted, index); err != nil {
+			return 0, err
+		}
+
+		log.Debug("executing query", slog.String("query", jobQuery))
+		if _, err := tx.Exec(ctx, jobQuery, userId, callbackURL); err != nil {
+			log.Error("failed to queue enrichment job", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.CreateUserForEnrichment", err)
+		}
+		return userId, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Debug("successfully created user for enrichment", slog.Int("user_id", int(userId)))
+
+	return userId, nil
+}
+
+// ClaimEnrichmentJob takes the next due job for lease and returns it with the user's passport.
+// Jobs whose lease expired, because their worker died, are due again. Jobs taken by others are
+// skipped, so any number of workers can claim at the same time. It reports false if no job is due.
+// If the passport can't be decrypted, the job is reported as claimed together with the error,
+// so that the attempt is retried or dead-lettered like a failed fetch.
+func (db *DB) ClaimEnrichmentJob(ctx context.Context, lease time.Duration) (models.EnrichmentJob, bool, error) {
+	query := `
+		WITH next AS (
+			SELECT id FROM enrichment_jobs
+			WHERE (status = 'queued' AND run_at <= NOW()) OR (status = 'running' AND locked_until < NOW())
+			ORDER BY run_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE enrichment_jobs j
+		SET status = 'running', attempts = j.attempts + 1, locked_until = NOW() + $1::interval, updated_at = NOW()
+		FROM next, users u
+		WHERE j.id = next.id AND u.id = j.user_id
+		RETURNING ` + enrichmentJobColumns + `, COALESCE(u.passport_serie, ''), COALESCE(u.passport_number, '')
+	`
+
+	var passport models.Passport
+	job, err := scanEnrichmentJob(db.pool.QueryRow(ctx, query, lease), &passport.Serie, &passport.Number)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.EnrichmentJob{}, false, nil
+		}
+		db.log.Error("failed to claim enrichment job", l.Err(err))
+
+		return models.EnrichmentJob{}, false, fmt.Errorf("%s: %w", "repo.ClaimEnrichmentJob", err)
+	}
+
+	if err := db.decryptPassport(&passport); err != nil {
+		db.log.Error("failed to decrypt passport", slog.Int64("job_id", job.ID), l.Err(err))
+
+		return job, true, fmt.Errorf("%s: job %d: %w", "repo.ClaimEnrichmentJob", job.ID, err)
+	}
+	job.Passport = passport
+
+	return job, true, nil
+}
+
+// CompleteEnrichmentJob fills in the people info of the job's user and marks the job done.
+// Users that were erased or enriched meanwhile are left as they are.
+// It returns ErrEnrichmentJobLost if the job is no longer held by the caller.
+func (db *DB) CompleteEnrichmentJob(ctx context.Context, job models.EnrichmentJob, people models.People) error {
+	query := `
+		UPDATE users
+		SET name = $2, surname = $3, patronymic = $4, address = $5, enrichment_status = 'enriched', updated_at = NOW()
+		WHERE id = $1 AND erased_at IS NULL AND enrichment_status <> 'enriched'
+	`
+	return db.finishEnrichmentJob(ctx, "repo.CompleteEnrichmentJob", job, models.JobDone, "", func(tx pgx.Tx) (bool, error) {
+		tag, err := tx.Exec(ctx, query, job.UserID, people.Name, people.Surname, people.Patronymic, people.Address)
+		return tag.RowsAffected() > 0, err
+	})
+}
+
+// DeadLetterEnrichmentJob gives up the job, keeping it with the error until it is retried,
+// and marks its pending user as failed. It returns ErrEnrichmentJobLost if the job is no longer held by the caller.
+func (db *DB) DeadLetterEnrichmentJob(ctx context.Context, job models.EnrichmentJob, jobErr string) error {
+	query := "UPDATE users SET enrichment_status = 'enrichment_failed', updated_at = NOW() WHERE id = $1 AND enrichment_status = 'pending_enrichment'"
+
+	return db.finishEnrichmentJob(ctx, "repo.DeadLetterEnrichmentJob", job, models.JobDead, jobErr, func(tx pgx.Tx) (bool, error) {
+		tag, err := tx.Exec(ctx, query, job.UserID)
+		return tag.RowsAffected() > 0, err
+	})
+}
+
+// finishEnrichmentJob changes the user with updateUser and sets the final status of the job in one transaction.
+// updateUser reports whether it changed the user, changes are recorded in the audit log.
+func (db *DB) finishEnrichmentJob(
+	ctx context.Context,
+	op string,
+	job models.EnrichmentJob,
+	status, jobErr string,
+	updateUser func(tx pgx.Tx) (bool, error),
+) error {
+	log := db.log.With(slog.Int64("job_id", job.ID), slog.Int("user_id", int(job.UserID)), slog.String("status", status))
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", l.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// the user is locked before the job, like everywhere else
+	if err := lockEntity(ctx, tx, models.EntityUser, job.UserID); err != nil {
+		log.Error("failed to lock user", l.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	before, err := snapshot(ctx, tx, models.EntityUser, job.UserID)
+	if err != nil {
+		log.Error("failed to snapshot user", l.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	jobQuery := `
+		UPDATE enrichment_jobs
+		SET status = $3, last_error = NULLIF($4, ''), locked_until = NULL, finished_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND attempts = $2 AND status = 'running'
+	`
+	log.Debug("executing query", slog.String("query", jobQuery))
+
+	tag, err := tx.Exec(ctx, jobQuery, job.ID, job.Attempts, status, jobErr)
+	if err != nil {
+		log.Error("failed to update enrichment job", l.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		log.Warn("enrichment job was lost")
+
+		return ErrEnrichmentJobLost
+	}
+
+	if before != nil {
+		changed, err := updateUser(tx)
+		if err != nil {
+			log.Error("failed to update user", l.Err(err))
+
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if changed {
+			if err := auditChange(ctx, tx, models.ActionEnrich, models.EntityUser, job.UserID, before); err != nil {
+				log.Error("failed to record audit event", l.Err(err))
+
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit transaction", l.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("enrichment job finished")
+
+	return nil
+}
+
+// RescheduleEnrichmentJob queues the failed job to run again at runAt.
+// It returns ErrEnrichmentJobLost if the job is no longer held by the caller.
+func (db *DB) RescheduleEnrichmentJob(ctx context.Context, job models.EnrichmentJob, jobErr string, runAt time.Time) error {
+	query := `
+		UPDATE enrichment_jobs
+		SET status = 'queued', last_error = $3, run_at = $4, locked_until = NULL, updated_at = NOW()
+		WHERE id = $1 AND attempts = $2 AND status = 'running'
+	`
+	log := db.log.With(slog.Int64("job_id", job.ID), slog.Time("run_at", runAt))
+	log.Debug("executing query", slog.String("query", query))
+
+	tag, err := db.pool.Exec(ctx, query, job.ID, job.Attempts, jobErr, runAt)
+	if err != nil {
+		log.Error("failed to reschedule enrichment job", l.Err(err))
+
+		return fmt.Errorf("%s: %w", "repo.RescheduleEnrichmentJob", err)
+	}
+	if tag.RowsAffected() == 0 {
+		log.Warn("enrichment job was lost")
+
+		return ErrEnrichmentJobLost
+	}
+
+	return nil
+}
+
+// RetryEnrichmentJob queues a dead-lettered job again with fresh attempts
+// and puts its failed user back to pending enrichment
+func (db *DB) RetryEnrichmentJob(ctx context.Context, id int64) (models.EnrichmentJob, error) {
+	log := db.log.With(slog.Int64("job_id", id))
+
+	var userID int32
+	err := db.pool.QueryRow(ctx, "SELECT user_id FROM enrichment_jobs WHERE id = $1 AND status = 'dead'", id).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Warn("dead enrichment job not found")
+
+			return models.EnrichmentJob{}, ErrEnrichmentJobNotFound
+		}
+		log.Error("failed to get enrichment job", l.Err(err))
+
+		return models.EnrichmentJob{}, fmt.Errorf("%s: %w", "repo.RetryEnrichmentJob", err)
+	}
+
+	jobQuery := `
+		UPDATE enrichment_jobs j
+		SET status = 'queued', attempts = 0, run_at = NOW(), finished_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'dead'
+		RETURNING ` + enrichmentJobColumns
+	userQuery := "UPDATE users SET enrichment_status = 'pending_enrichment', updated_at = NOW() WHERE id = $1 AND enrichment_status = 'enrichment_failed'"
+
+	var job models.EnrichmentJob
+	err = db.audited(ctx, models.ActionEnrich, models.EntityUser, userID, func(tx pgx.Tx) (int32, error) {
+		log.Debug("executing query", slog.String("query", jobQuery))
+
+		job, err = scanEnrichmentJob(tx.QueryRow(ctx, jobQuery, id))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				log.Warn("dead enrichment job not found")
+
+				return 0, ErrEnrichmentJobNotFound
+			}
+			log.Error("failed to requeue enrichment job", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.RetryEnrichmentJob", err)
+		}
+
+		log.Debug("executing query", slog.String("query", userQuery))
+		if _, err := tx.Exec(ctx, userQuery, userID); err != nil {
+			log.Error("failed to update user", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.RetryEnrichmentJob", err)
+		}
+		return userID, nil
+	})
+	if err != nil {
+		return models.EnrichmentJob{}, err
+	}
+
+	log.Debug("enrichment job requeued")
+
+	return job, nil
+}
+
+// Enrichment returns the enrichment status of the user and the user's latest job
+func (db *DB) Enrichment(ctx context.Context, userID int32) (models.Enrichment, error) {
+	log := db.log.With(slog.Int("user_id", int(userID)))
+
+	enrichment := models.Enrichment{UserID: userID}
+	err := db.pool.QueryRow(ctx, "SELECT enrichment_status FROM users WHERE id = $1", userID).Scan(&enrichment.Status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Warn("user not found")
+
+			return models.Enrichment{}, ErrUserNotFound
+		}
+		log.Error("failed to get user", l.Err(err))
+
+		return models.Enrichment{}, fmt.Errorf("%s: %w", "repo.Enrichment", err)
+	}
+
+	query := "SELECT " + enrichmentJobColumns + " FROM enrichment_jobs j WHERE j.user_id = $1 ORDER BY j.id DESC LIMIT 1"
+	log.Debug("executing query", slog.String("query", query))
+
+	job, err := scanEnrichmentJob(db.pool.QueryRow(ctx, query, userID))
+	switch {
+	case err == nil:
+		enrichment.Job = &job
+	case !errors.Is(err, pgx.ErrNoRows):
+		log.Error("failed to get enrichment job", l.Err(err))
+
+		return models.Enrichment{}, fmt.Errorf("%s: %w", "repo.Enrichment", err)
+	}
+
+	return enrichment, nil
+}
+
+// EnrichmentJobs returns the jobs in the status, or all jobs if status is empty, newest first
+func (db *DB) EnrichmentJobs(ctx context.Context, status string, settings models.Pagination) ([]models.EnrichmentJob, error) {
+	query := "SELECT " + enrichmentJobColumns + " FROM enrichment_jobs j WHERE ($1 = '' OR j.status = $1) ORDER BY j.id DESC LIMIT NULLIF($2, 0) OFFSET $3"
+
+	log := db.log.With(slog.String("status", status))
+	log.Debug("executing query", slog.String("query", query))
+
+	rows, err := db.pool.Query(ctx, query, status, settings.Limit, settings.Offset)
+	if err != nil {
+		log.Error("failed to get enrichment jobs", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.EnrichmentJobs", err)
+	}
+	jobs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.EnrichmentJob, error) {
+		return scanEnrichmentJob(row)
+	})
+	if err != nil {
+		log.Error("failed to scan enrichment jobs", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.EnrichmentJobs", err)
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,101 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/utils"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+// listPage fetches a page of the listing with scan, which scans a row followed by extra destinations.
+// The rows are counted as well if settings ask for the total.
+func listPage[T any](ctx context.Context, db *DB, log *slog.Logger, listing *utils.Listing, settings models.Pagination,
+	scan func(pgx.Row, ...any) (T, error),
+) ([]T, models.Page, error) {
+	query, args, err := listing.PageQuery(settings)
+	if err != nil {
+		log.Warn("invalid cursor", l.Err(err))
+
+		return nil, models.Page{}, err
+	}
+	log.Debug("executing query", slog.String("query", query), slog.Any("args", args))
+
+	rows, err := db.pool.Query(ctx, query, args...)
+	if err != nil {
+		if err := forgedCursorErr(settings, err); err != nil {
+			log.Warn("invalid cursor", l.Err(err))
+
+			return nil, models.Page{}, err
+		}
+		log.Error("failed to execute query", l.Err(err))
+
+		return nil, models.Page{}, err
+	}
+	defer rows.Close()
+
+	var (
+		items []T
+		keys  [][]string
+	)
+	for rows.Next() {
+		key := make([]string, len(listing.Keys))
+		dest := make([]any, len(key))
+		for i := range key {
+			dest[i] = &key[i]
+		}
+
+		item, err := scan(rows, dest...)
+		if err != nil {
+			log.Error("failed to scan row", l.Err(err))
+
+			return nil, models.Page{}, err
+		}
+
+		items = append(items, item)
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		if err := forgedCursorErr(settings, err); err != nil {
+			log.Warn("invalid cursor", l.Err(err))
+
+			return nil, models.Page{}, err
+		}
+		log.Error("rows error", l.Err(err))
+
+		return nil, models.Page{}, err
+	}
+
+	items, page := utils.Paginate(listing, settings, items, keys)
+
+	if settings.WithTotal {
+		query, args := listing.CountQuery()
+		log.Debug("executing query", slog.String("query", query), slog.Any("args", args))
+
+		var total int
+		if err := db.pool.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+			log.Error("failed to count rows", l.Err(err))
+
+			return nil, models.Page{}, err
+		}
+		page.Total = &total
+	}
+
+	return items, page, nil
+}
+
+// forgedCursorErr returns ErrInvalidCursor if err is a data exception, such as a cursor value
+// that was tampered with and can't be cast back to the type of its key. It returns nil otherwise.
+func forgedCursorErr(settings models.Pagination, err error) error {
+	var pgErr *pgconn.PgError
+	if settings.Cursor != "" && errors.As(err, &pgErr) && strings.HasPrefix(pgErr.Code, "22") { // class 22 are the data exceptions in PostgreSQL
+		return fmt.Errorf("%w: %s", utils.ErrInvalidCursor, pgErr.Message)
+	}
+	return nil
+}
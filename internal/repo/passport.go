@@ -0,0 +1,253 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/pkg/fieldcrypt"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+// Passports are stored encrypted in the passport_serie and passport_number columns.
+// Their blind indexes in passport_serie_idx and passport_number_idx keep the pair unique
+// and let users be found by passport.
+const (
+	fieldPassportSerie  = "passport_serie"
+	fieldPassportNumber = "passport_number"
+)
+
+var ErrNoKeyring = errors.New("passport encryption keys are not configured")
+
+// encryptPassport returns the ciphertexts of the passport and their blind indexes, empty fields stay empty
+func (db *DB) encryptPassport(passport models.Passport) (encrypted, index models.Passport, err error) {
+	if passport.Serie == "" && passport.Number == "" {
+		return models.Passport{}, models.Passport{}, nil
+	}
+	if db.keyring == nil {
+		return models.Passport{}, models.Passport{}, ErrNoKeyring
+	}
+
+	fields := []struct {
+		name             string
+		value            string
+		encrypted, index *string
+	}{
+		{fieldPassportSerie, passport.Serie, &encrypted.Serie, &index.Serie},
+		{fieldPassportNumber, passport.Number, &encrypted.Number, &index.Number},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		if *f.encrypted, err = db.keyring.Encrypt(f.name, f.value); err != nil {
+			return models.Passport{}, models.Passport{}, err
+		}
+		*f.index = db.keyring.BlindIndex(f.name, f.value)
+	}
+
+	return encrypted, index, nil
+}
+
+// passportIndex returns the blind indexes of the passport, empty fields stay empty
+func (db *DB) passportIndex(passport models.Passport) (models.Passport, error) {
+	if passport.Serie == "" && passport.Number == "" {
+		return models.Passport{}, nil
+	}
+	if db.keyring == nil {
+		return models.Passport{}, ErrNoKeyring
+	}
+
+	var index models.Passport
+	if passport.Serie != "" {
+		index.Serie = db.keyring.BlindIndex(fieldPassportSerie, passport.Serie)
+	}
+	if passport.Number != "" {
+		index.Number = db.keyring.BlindIndex(fieldPassportNumber, passport.Number)
+	}
+
+	return index, nil
+}
+
+// decryptPassport decrypts the passport in place.
+// Values written before encryption was introduced are plain and kept as they are.
+func (db *DB) decryptPassport(passport *models.Passport) error {
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{fieldPassportSerie, &passport.Serie},
+		{fieldPassportNumber, &passport.Number},
+	}
+	for _, f := range fields {
+		if !fieldcrypt.IsEncrypted(*f.value) {
+			continue
+		}
+		if db.keyring == nil {
+			return ErrNoKeyring
+		}
+
+		value, err := db.keyring.Decrypt(f.name, *f.value)
+		if err != nil {
+			return err
+		}
+		*f.value = value
+	}
+
+	return nil
+}
+
+// ReencryptPassports encrypts all passports with the primary key and recomputes their blind indexes.
+// It encrypts plain passports left from before encryption was introduced and, after a key rotation,
+// passports encrypted with retired keys. Rows are processed in batches, each in its own transaction,
+// so it can be interrupted and run again. It returns the number of re-encrypted rows.
+//
+// Re-encryption doesn't change the data, so it is not recorded in the audit log.
+func (db *DB) ReencryptPassports(ctx context.Context, batchSize int) (int, error) {
+	return db.rewritePassports(ctx, "repo.ReencryptPassports", batchSize, func(serie, number string) bool {
+		return db.keyring.NeedsRotation(serie) || db.keyring.NeedsRotation(number)
+	}, db.encryptPassport)
+}
+
+// EncryptPlainPassports encrypts and indexes the passports stored before encryption was introduced,
+// which duplicate checks and passport filters miss until they have blind indexes.
+// It fails with ErrNoKeyring if there are such passports and no keys. It returns the number of encrypted rows.
+func (db *DB) EncryptPlainPassports(ctx context.Context, batchSize int) (int, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM users
+			WHERE passport_serie IS NOT NULL AND passport_number IS NOT NULL
+				AND (passport_serie_idx IS NULL OR passport_number_idx IS NULL)
+		)
+	`
+	db.log.Debug("executing query", slog.String("query", query))
+
+	var unindexed bool
+	if err := db.pool.QueryRow(ctx, query).Scan(&unindexed); err != nil {
+		db.log.Error("failed to execute query", l.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", "repo.EncryptPlainPassports", err)
+	}
+	if !unindexed {
+		return 0, nil
+	}
+
+	return db.rewritePassports(ctx, "repo.EncryptPlainPassports", batchSize, func(serie, number string) bool {
+		return !fieldcrypt.IsEncrypted(serie) || !fieldcrypt.IsEncrypted(number)
+	}, db.encryptPassport)
+}
+
+// DecryptPassports writes all passports back in plain text, which is needed to migrate
+// below the encryption migration. It returns the number of decrypted rows.
+func (db *DB) DecryptPassports(ctx context.Context, batchSize int) (int, error) {
+	return db.rewritePassports(ctx, "repo.DecryptPassports", batchSize, func(serie, number string) bool {
+		return fieldcrypt.IsEncrypted(serie) || fieldcrypt.IsEncrypted(number)
+	}, func(passport models.Passport) (models.Passport, models.Passport, error) {
+		return passport, models.Passport{}, nil
+	})
+}
+
+// rewritePassports rewrites the passports that need it with the columns returned by rewrite
+func (db *DB) rewritePassports(
+	ctx context.Context,
+	op string,
+	batchSize int,
+	needs func(serie, number string) bool,
+	rewrite func(models.Passport) (stored, index models.Passport, err error),
+) (int, error) {
+	log := db.log.With(slog.String("op", op), slog.Int("batch_size", batchSize))
+
+	if db.keyring == nil {
+		return 0, ErrNoKeyring
+	}
+
+	selectQuery := `
+		SELECT id, passport_serie, passport_number FROM users
+		WHERE id > $1 AND passport_serie IS NOT NULL AND passport_number IS NOT NULL
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE
+	`
+	updateQuery := `
+		UPDATE users
+		SET passport_serie = $2, passport_number = $3, passport_serie_idx = NULLIF($4, ''), passport_number_idx = NULLIF($5, '')
+		WHERE id = $1
+	`
+
+	var lastID int32
+	total := 0
+	for {
+		count, done, err := func() (int, bool, error) {
+			tx, err := db.pool.Begin(ctx)
+			if err != nil {
+				return 0, false, err
+			}
+			defer tx.Rollback(ctx)
+
+			log.Debug("executing query", slog.String("query", selectQuery), slog.Int("after_id", int(lastID)))
+
+			type row struct {
+				id       int32
+				passport models.Passport
+			}
+			rows, err := tx.Query(ctx, selectQuery, lastID, batchSize)
+			if err != nil {
+				return 0, false, err
+			}
+			batch, err := pgx.CollectRows(rows, func(r pgx.CollectableRow) (row, error) {
+				var b row
+				err := r.Scan(&b.id, &b.passport.Serie, &b.passport.Number)
+				return b, err
+			})
+			if err != nil {
+				return 0, false, err
+			}
+			if len(batch) == 0 {
+				return 0, true, nil
+			}
+
+			count := 0
+			for _, b := range batch {
+				lastID = b.id
+
+				if !needs(b.passport.Serie, b.passport.Number) {
+					continue
+				}
+
+				passport := b.passport
+				if err := db.decryptPassport(&passport); err != nil {
+					return 0, false, fmt.Errorf("user %d: %w", b.id, err)
+				}
+				stored, index, err := rewrite(passport)
+				if err != nil {
+					return 0, false, fmt.Errorf("user %d: %w", b.id, err)
+				}
+				if _, err := tx.Exec(ctx, updateQuery, b.id, stored.Serie, stored.Number, index.Serie, index.Number); err != nil {
+					return 0, false, fmt.Errorf("user %d: %w", b.id, err)
+				}
+				count++
+			}
+
+			return count, len(batch) < batchSize, tx.Commit(ctx)
+		}()
+		if err != nil {
+			log.Error("failed to rewrite passports", slog.Int("after_id", int(lastID)), l.Err(err))
+
+			return total, fmt.Errorf("%s: %w", op, err)
+		}
+
+		total += count
+		log.Debug("batch of passports rewritten", slog.Int("count", count), slog.Int("last_id", int(lastID)))
+
+		if done {
+			break
+		}
+	}
+
+	log.Debug("passports rewritten successfully", slog.Int("count", total))
+
+	return total, nil
+}
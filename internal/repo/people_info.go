@@ -0,0 +1,117 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/peopleinfo"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+// The people info cache is a peopleinfo.Store shared by all instances of the server.
+// Passports are only stored as blind indexes, like in the users table.
+
+// CachedPeopleInfo returns the cached answer of the people info API for the passport, if it hasn't expired
+func (db *DB) CachedPeopleInfo(ctx context.Context, passport models.Passport) (peopleinfo.Entry, bool, error) {
+	index, err := db.passportIndex(passport)
+	if err != nil {
+		return peopleinfo.Entry{}, false, fmt.Errorf("%s: %w", "repo.CachedPeopleInfo", err)
+	}
+
+	query := `
+		SELECT people, expires_at FROM people_info_cache
+		WHERE passport_serie_idx = $1 AND passport_number_idx = $2 AND expires_at > NOW()
+	`
+	db.log.Debug("executing query", slog.String("query", query))
+
+	var entry peopleinfo.Entry
+	err = db.pool.QueryRow(ctx, query, index.Serie, index.Number).Scan(&entry.People, &entry.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return peopleinfo.Entry{}, false, nil
+		}
+		db.log.Error("failed to get cached people info", l.Err(err))
+
+		return peopleinfo.Entry{}, false, fmt.Errorf("%s: %w", "repo.CachedPeopleInfo", err)
+	}
+
+	return entry, true, nil
+}
+
+// CachePeopleInfo stores the answer of the people info API for the passport, replacing the previous one
+func (db *DB) CachePeopleInfo(ctx context.Context, passport models.Passport, entry peopleinfo.Entry) error {
+	index, err := db.passportIndex(passport)
+	if err != nil {
+		return fmt.Errorf("%s: %w", "repo.CachePeopleInfo", err)
+	}
+
+	query := `
+		INSERT INTO people_info_cache (passport_serie_idx, passport_number_idx, people, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (passport_serie_idx, passport_number_idx) DO UPDATE
+		SET people = EXCLUDED.people, expires_at = EXCLUDED.expires_at
+	`
+	db.log.Debug("executing query", slog.String("query", query))
+
+	if _, err := db.pool.Exec(ctx, query, index.Serie, index.Number, entry.People, entry.ExpiresAt); err != nil {
+		db.log.Error("failed to cache people info", l.Err(err))
+
+		return fmt.Errorf("%s: %w", "repo.CachePeopleInfo", err)
+	}
+
+	return nil
+}
+
+// InvalidatePeopleInfo removes the cached answer for the passport and returns the number of removed entries
+func (db *DB) InvalidatePeopleInfo(ctx context.Context, passport models.Passport) (int, error) {
+	index, err := db.passportIndex(passport)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", "repo.InvalidatePeopleInfo", err)
+	}
+
+	query := "DELETE FROM people_info_cache WHERE passport_serie_idx = $1 AND passport_number_idx = $2"
+	db.log.Debug("executing query", slog.String("query", query))
+
+	tag, err := db.pool.Exec(ctx, query, index.Serie, index.Number)
+	if err != nil {
+		db.log.Error("failed to invalidate people info", l.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", "repo.InvalidatePeopleInfo", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// ClearPeopleInfoCache removes all cached answers of the people info API and returns their number
+func (db *DB) ClearPeopleInfoCache(ctx context.Context) (int, error) {
+	query := "DELETE FROM people_info_cache"
+	db.log.Debug("executing query", slog.String("query", query))
+
+	tag, err := db.pool.Exec(ctx, query)
+	if err != nil {
+		db.log.Error("failed to clear people info cache", l.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", "repo.ClearPeopleInfoCache", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// SweepPeopleInfoCache removes expired answers and returns their number
+func (db *DB) SweepPeopleInfoCache(ctx context.Context) (int, error) {
+	query := "DELETE FROM people_info_cache WHERE expires_at <= NOW()"
+	db.log.Debug("executing query", slog.String("query", query))
+
+	tag, err := db.pool.Exec(ctx, query)
+	if err != nil {
+		db.log.Error("failed to sweep people info cache", l.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", "repo.SweepPeopleInfoCache", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,209 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/utils"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+var ErrUserErased = errors.New("personal data of the user was already erased")
+
+// personalDataFields are the columns of users that hold personal data. They are left out of
+// user snapshots, see userSnapshot, and scrubbed from the snapshots recorded before that.
+var personalDataFields = []string{"passport_serie", "passport_number", "passport_serie_idx", "passport_number_idx", "name", "surname", "patronymic", "address"}
+
+// PersonalData collects everything held about the user, soft-deleted users included.
+// The data is read from a single snapshot and the export is recorded in the audit log.
+func (db *DB) PersonalData(ctx context.Context, userID int32) (models.PersonalData, error) {
+	log := db.log.With(slog.Int("user_id", int(userID)))
+
+	tx, err := db.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead})
+	if err != nil {
+		log.Error("failed to begin transaction", l.Err(err))
+
+		return models.PersonalData{}, fmt.Errorf("%s: %w", "repo.PersonalData", err)
+	}
+	defer tx.Rollback(ctx)
+
+	data := models.PersonalData{}
+
+	userQuery := "SELECT " + utils.UserColumns + ", calendar_token_hash IS NOT NULL, NOW() FROM users WHERE id = $1"
+	log.Debug("executing query", slog.String("query", userQuery))
+
+	data.User, err = db.scanUser(tx.QueryRow(ctx, userQuery, userID), &data.CalendarFeed, &data.ExportedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Warn("user not found")
+
+			return models.PersonalData{}, ErrUserNotFound
+		}
+		log.Error("failed to get user", l.Err(err))
+
+		return models.PersonalData{}, fmt.Errorf("%s: %w", "repo.PersonalData", err)
+	}
+
+	worklogsQuery := worklogSelect + " WHERE w.user_id = $1 ORDER BY w.started_at, w.id"
+	log.Debug("executing query", slog.String("query", worklogsQuery))
+
+	rows, err := tx.Query(ctx, worklogsQuery, userID)
+	if err == nil {
+		data.Worklogs, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Worklog, error) {
+			return scanWorklog(row)
+		})
+	}
+	if err != nil {
+		log.Error("failed to get worklogs", l.Err(err))
+
+		return models.PersonalData{}, fmt.Errorf("%s: %w", "repo.PersonalData", err)
+	}
+
+	keysQuery := "SELECT " + apiKeyColumns + " FROM api_keys WHERE user_id = $1 ORDER BY id"
+	log.Debug("executing query", slog.String("query", keysQuery))
+
+	rows, err = tx.Query(ctx, keysQuery, userID)
+	if err == nil {
+		data.APIKeys, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.APIKey, error) {
+			return scanAPIKey(row)
+		})
+	}
+	if err != nil {
+		log.Error("failed to get api keys", l.Err(err))
+
+		return models.PersonalData{}, fmt.Errorf("%s: %w", "repo.PersonalData", err)
+	}
+
+	historyQuery := "SELECT " + auditEventColumns + " FROM audit_events WHERE entity = $1 AND entity_id = $2 ORDER BY occurred_at, id"
+	log.Debug("executing query", slog.String("query", historyQuery))
+
+	rows, err = tx.Query(ctx, historyQuery, models.EntityUser, userID)
+	if err == nil {
+		data.History, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.AuditEvent, error) {
+			return scanAuditEvent(row)
+		})
+	}
+	if err != nil {
+		log.Error("failed to get history", l.Err(err))
+
+		return models.PersonalData{}, fmt.Errorf("%s: %w", "repo.PersonalData", err)
+	}
+
+	if err := recordAudit(ctx, tx, models.ActionExport, models.EntityUser, userID, nil, nil); err != nil {
+		log.Error("failed to record audit event", l.Err(err))
+
+		return models.PersonalData{}, fmt.Errorf("%s: %w", "repo.PersonalData", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit transaction", l.Err(err))
+
+		return models.PersonalData{}, fmt.Errorf("%s: %w", "repo.PersonalData", err)
+	}
+
+	log.Debug("personal data exported successfully", slog.Int("worklogs", len(data.Worklogs)))
+
+	return data, nil
+}
+
+// scrubAuditEvents removes personal data from the snapshots of the users in the audit log
+func scrubAuditEvents(ctx context.Context, tx pgx.Tx, userIDs []int32) error {
+	query := `
+		UPDATE audit_events
+		SET before = before - $3::text[], after = after - $3::text[]
+		WHERE entity = $1 AND entity_id = ANY($2)
+	`
+	_, err := tx.Exec(ctx, query, models.EntityUser, userIDs, personalDataFields)
+	return err
+}
+
+// EraseUser anonymizes the user: the passport, name and address are cleared, also from
+// the audit log, and the calendar feed and API keys are revoked. Erased users can't sign in
+// anymore, see UserAccess. Worklogs are kept, so the user's time still counts in reports.
+// The erasure itself is recorded in the audit log.
+// It returns the erased passport, so that the people info cached for it can be invalidated.
+func (db *DB) EraseUser(ctx context.Context, userID int32) (models.Passport, error) {
+	log := db.log.With(slog.Int("user_id", int(userID)))
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", l.Err(err))
+
+		return models.Passport{}, fmt.Errorf("%s: %w", "repo.EraseUser", err)
+	}
+	defer tx.Rollback(ctx)
+
+	lockQuery := "SELECT erased_at IS NOT NULL, COALESCE(passport_serie, ''), COALESCE(passport_number, '') FROM users WHERE id = $1 FOR UPDATE"
+
+	var erased bool
+	var passport models.Passport
+	err = tx.QueryRow(ctx, lockQuery, userID).Scan(&erased, &passport.Serie, &passport.Number)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Warn("user not found")
+
+			return models.Passport{}, ErrUserNotFound
+		}
+		log.Error("failed to lock user", l.Err(err))
+
+		return models.Passport{}, fmt.Errorf("%s: %w", "repo.EraseUser", err)
+	}
+	if erased {
+		log.Warn("user was already erased")
+
+		return models.Passport{}, ErrUserErased
+	}
+
+	query := `
+		UPDATE users
+		SET passport_serie = NULL, passport_number = NULL, passport_serie_idx = NULL, passport_number_idx = NULL, name = '', surname = '', patronymic = NULL, address = '',
+			calendar_token_hash = NULL, erased_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`
+	log.Debug("executing query", slog.String("query", query))
+
+	if _, err := tx.Exec(ctx, query, userID); err != nil {
+		log.Error("failed to execute query", l.Err(err))
+
+		return models.Passport{}, fmt.Errorf("%s: %w", "repo.EraseUser", err)
+	}
+
+	if err := revokeUserAPIKeys(ctx, tx, userID); err != nil {
+		log.Error("failed to revoke api keys", l.Err(err))
+
+		return models.Passport{}, fmt.Errorf("%s: %w", "repo.EraseUser", err)
+	}
+
+	if err := scrubAuditEvents(ctx, tx, []int32{userID}); err != nil {
+		log.Error("failed to scrub audit events", l.Err(err))
+
+		return models.Passport{}, fmt.Errorf("%s: %w", "repo.EraseUser", err)
+	}
+
+	if err := auditChange(ctx, tx, models.ActionErase, models.EntityUser, userID, nil); err != nil {
+		log.Error("failed to record audit event", l.Err(err))
+
+		return models.Passport{}, fmt.Errorf("%s: %w", "repo.EraseUser", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit transaction", l.Err(err))
+
+		return models.Passport{}, fmt.Errorf("%s: %w", "repo.EraseUser", err)
+	}
+
+	// the user is erased anyway, an undecryptable passport only can't be uncached
+	if err := db.decryptPassport(&passport); err != nil {
+		log.Warn("failed to decrypt erased passport", l.Err(err))
+
+		passport = models.Passport{}
+	}
+
+	log.Debug("user erased successfully")
+
+	return passport, nil
+}
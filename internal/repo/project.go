@@ -0,0 +1,178 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kuromii5/time-tracker/internal/models"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+var (
+	ErrProjectNotFound  = errors.New("project not found")
+	ErrProjectDuplicate = errors.New("project with such name already exists")
+)
+
+func (db *DB) CreateProject(ctx context.Context, project models.Project) (int32, error) {
+	query := `
+		INSERT INTO projects (name, client_id, created_at, updated_at)
+		VALUES ($1, NULLIF($2, 0), NOW(), NOW())
+		RETURNING id
+	`
+	log := db.log.With(slog.Any("project", project))
+	log.Debug("executing query", slog.String("query", query))
+
+	var projectID int32
+	err := db.audited(ctx, models.ActionCreate, models.EntityProject, 0, func(tx pgx.Tx) (int32, error) {
+		err := tx.QueryRow(ctx, query, project.Name, project.ClientID).Scan(&projectID)
+		if err != nil {
+			if err := projectConstraintErr(err); err != nil {
+				log.Warn("project violates constraints", l.Err(err))
+
+				return 0, err
+			}
+			log.Error("failed to execute query", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.CreateProject", err)
+		}
+		return projectID, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Debug("successfully created project", slog.Int("project_id", int(projectID)))
+
+	return projectID, nil
+}
+
+// Projects returns all projects, or only the projects of the given client if clientID is not zero
+func (db *DB) Projects(ctx context.Context, clientID int32) ([]models.Project, error) {
+	query := `
+		SELECT id, created_at, updated_at, COALESCE(client_id, 0), name FROM projects
+		WHERE $1 = 0 OR client_id = $1
+		ORDER BY name
+	`
+	log := db.log.With(slog.Int("client_id", int(clientID)))
+	log.Debug("executing query", slog.String("query", query))
+
+	rows, err := db.pool.Query(ctx, query, clientID)
+	if err != nil {
+		log.Error("failed to execute query", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.Projects", err)
+	}
+	defer rows.Close()
+
+	var projects []models.Project
+	for rows.Next() {
+		var project models.Project
+		if err := rows.Scan(&project.ID, &project.CreatedAt, &project.UpdatedAt, &project.ClientID, &project.Name); err != nil {
+			log.Error("failed to scan row", l.Err(err))
+
+			return nil, fmt.Errorf("%s: %w", "repo.Projects", err)
+		}
+
+		projects = append(projects, project)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("rows error", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.Projects", err)
+	}
+
+	log.Debug("successfully retrieved projects", slog.Int("count", len(projects)))
+
+	return projects, nil
+}
+
+// UpdateProject updates the non-empty fields of the project
+func (db *DB) UpdateProject(ctx context.Context, project models.Project) error {
+	query := `
+		UPDATE projects
+		SET name = COALESCE(NULLIF($2, ''), name),
+			client_id = COALESCE(NULLIF($3, 0), client_id),
+			updated_at = NOW()
+		WHERE id = $1
+	`
+	log := db.log.With(slog.Any("project", project))
+	log.Debug("executing query", slog.String("query", query))
+
+	err := db.audited(ctx, models.ActionUpdate, models.EntityProject, project.ID, func(tx pgx.Tx) (int32, error) {
+		tag, err := tx.Exec(ctx, query, project.ID, project.Name, project.ClientID)
+		if err != nil {
+			if err := projectConstraintErr(err); err != nil {
+				log.Warn("project violates constraints", l.Err(err))
+
+				return 0, err
+			}
+			log.Error("failed to execute query", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.UpdateProject", err)
+		}
+		if tag.RowsAffected() == 0 {
+			log.Warn("project not found")
+
+			return 0, ErrProjectNotFound
+		}
+		return project.ID, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Debug("successfully updated project")
+
+	return nil
+}
+
+func (db *DB) DeleteProject(ctx context.Context, id int32) error {
+	query := "DELETE FROM projects WHERE id = $1"
+
+	log := db.log.With(slog.Int("project_id", int(id)))
+	log.Debug("executing query", slog.String("query", query))
+
+	err := db.audited(ctx, models.ActionDelete, models.EntityProject, id, func(tx pgx.Tx) (int32, error) {
+		tag, err := tx.Exec(ctx, query, id)
+		if err != nil {
+			log.Error("failed to execute query", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.DeleteProject", err)
+		}
+		if tag.RowsAffected() == 0 {
+			log.Warn("project not found")
+
+			return 0, ErrProjectNotFound
+		}
+		return id, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Debug("successfully deleted project")
+
+	return nil
+}
+
+// projectConstraintErr maps constraint violations on projects to repo errors.
+// It returns nil for any other error.
+func projectConstraintErr(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+
+	switch pgErr.Code {
+	case "23505": // unique_violation
+		return ErrProjectDuplicate
+	case "23503": // foreign_key_violation
+		return ErrClientNotFound
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/utils"
+	l "github.com/kuromii5/time-tracker/pkg/logger"
+)
+
+func (db *DB) TimeReport(ctx context.Context, filter models.ReportFilter) ([]models.ReportRow, error) {
+	log := db.log.With(slog.Any("filter", filter))
+
+	query, args, err := utils.BuildTimeReportQuery(filter)
+	if err != nil {
+		log.Error("failed to build query", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.TimeReport", err)
+	}
+	log.Debug("executing query", slog.String("query", query), slog.Any("args", args))
+
+	rows, err := db.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Error("failed to execute query", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.TimeReport", err)
+	}
+	defer rows.Close()
+
+	var report []models.ReportRow
+	for rows.Next() {
+		var row models.ReportRow
+
+		// dimension columns come first, in the order they were requested
+		dest := make([]any, 0, len(filter.GroupBy)+2)
+		for _, dim := range filter.GroupBy {
+			switch dim {
+			case models.GroupByUser:
+				dest = append(dest, &row.UserID)
+			case models.GroupByTask:
+				dest = append(dest, &row.Task)
+			case models.GroupByDay:
+				dest = append(dest, &row.Day)
+			case models.GroupByWeek:
+				dest = append(dest, &row.Week)
+			case models.GroupByMonth:
+				dest = append(dest, &row.Month)
+			}
+		}
+		dest = append(dest, &row.Duration, &row.Worklogs)
+
+		if err := rows.Scan(dest...); err != nil {
+			log.Error("failed to scan row", l.Err(err))
+
+			return nil, fmt.Errorf("%s: %w", "repo.TimeReport", err)
+		}
+
+		report = append(report, row)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("rows error", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.TimeReport", err)
+	}
+
+	log.Debug("time report built successfully", slog.Int("rows", len(report)))
+
+	return report, nil
+}
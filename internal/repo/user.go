@@ -5,9 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
-	"github.com/jackc/pgconn"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kuromii5/time-tracker/internal/audit"
 	"github.com/kuromii5/time-tracker/internal/models"
 	"github.com/kuromii5/time-tracker/internal/utils"
 	l "github.com/kuromii5/time-tracker/pkg/logger"
@@ -19,17 +22,41 @@ var (
 )
 
 func (db *DB) CreateUser(ctx context.Context, user models.User) (int32, error) {
+	log := db.log.With(slog.Any("user", user))
+
+	passport, index, err := db.encryptPassport(user.Passport)
+	if err != nil {
+		log.Error("failed to encrypt passport", l.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", "repo.CreateUser", err)
+	}
+
+	var userId int32
+	err = db.audited(ctx, models.ActionCreate, models.EntityUser, 0, func(tx pgx.Tx) (int32, error) {
+		userId, err = db.insertUser(ctx, tx, log, user, passport, index)
+		return userId, err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Debug("successfully created user", slog.Int("user_id", int(userId)))
+
+	return userId, nil
+}
+
+// insertUser inserts the user with the encrypted passport and its index
+func (db *DB) insertUser(ctx context.Context, tx pgx.Tx, log *slog.Logger, user models.User, passport, index models.Passport) (int32, error) {
 	query := `
-		INSERT INTO users (passport_serie, passport_number, name, surname, patronymic, address, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		INSERT INTO users (passport_serie, passport_number, passport_serie_idx, passport_number_idx, name, surname, patronymic, address, enrichment_status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE(NULLIF($9, ''), 'enriched'), NOW(), NOW())
 		RETURNING id
 	`
-	log := db.log.With(slog.Any("user", user))
 	log.Debug("executing query", slog.String("query", query))
 
 	var userId int32
-	err := db.pool.QueryRow(ctx, query,
-		user.Passport.Serie, user.Passport.Number, user.People.Name, user.People.Surname, user.People.Patronymic, user.People.Address).
+	err := tx.QueryRow(ctx, query,
+		passport.Serie, passport.Number, index.Serie, index.Number, user.People.Name, user.People.Surname, user.People.Patronymic, user.People.Address, user.EnrichmentStatus).
 		Scan(&userId)
 	if err != nil {
 		// Check if the error is a unique constraint violation
@@ -41,67 +68,181 @@ func (db *DB) CreateUser(ctx context.Context, user models.User) (int32, error) {
 		}
 		log.Error("failed to execute query", l.Err(err))
 
-		return 0, fmt.Errorf("%s: %w", "repo.CreateUser", err)
+		return 0, fmt.Errorf("%s: %w", "repo.insertUser", err)
 	}
 
-	log.Debug("successfully created user", slog.Int("user_id", int(userId)))
-
 	return userId, nil
 }
 
-func (db *DB) Users(ctx context.Context, filter models.FilterBy, settings models.Pagination) ([]models.User, error) {
-	query, args := utils.BuildGetUsersQuery(filter, settings)
+// CreateUsers creates the users in one transaction. They are copied into a staging table with COPY and
+// inserted from there, skipping users whose passport is taken, whose IDs are returned as zero.
+// Passports of the users must be distinct. Every created user is recorded in the audit log.
+func (db *DB) CreateUsers(ctx context.Context, users []models.User) ([]int32, error) {
+	log := db.log.With(slog.Int("count", len(users)))
 
-	log := db.log.With(slog.Any("filter", filter), slog.Any("pagination", settings))
-	log.Debug("executing query", slog.String("query", query), slog.Any("args", args))
+	staged := make([][]any, len(users))
+	for i, user := range users {
+		passport, index, err := db.encryptPassport(user.Passport)
+		if err != nil {
+			log.Error("failed to encrypt passport", l.Err(err))
+
+			return nil, fmt.Errorf("%s: %w", "repo.CreateUsers", err)
+		}
+		staged[i] = []any{int32(i), passport.Serie, passport.Number, index.Serie, index.Number,
+			user.People.Name, user.People.Surname, user.People.Patronymic, user.People.Address}
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.CreateUsers", err)
+	}
+	defer tx.Rollback(ctx)
+
+	stageQuery := `
+		CREATE TEMP TABLE staged_users (
+			n INTEGER, passport_serie TEXT, passport_number TEXT, passport_serie_idx CHAR(64), passport_number_idx CHAR(64),
+			name TEXT, surname TEXT, patronymic TEXT, address TEXT
+		) ON COMMIT DROP
+	`
+	log.Debug("executing query", slog.String("query", stageQuery))
+
+	if _, err := tx.Exec(ctx, stageQuery); err != nil {
+		log.Error("failed to create staging table", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.CreateUsers", err)
+	}
+	columns := []string{"n", "passport_serie", "passport_number", "passport_serie_idx", "passport_number_idx", "name", "surname", "patronymic", "address"}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"staged_users"}, columns, pgx.CopyFromRows(staged)); err != nil {
+		log.Error("failed to copy users", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.CreateUsers", err)
+	}
+
+	// created users are matched with the staged ones by the passport index, and audited with the snapshot CreateUser records
+	query := `
+		WITH created AS (
+			INSERT INTO users (passport_serie, passport_number, passport_serie_idx, passport_number_idx, name, surname, patronymic, address, created_at, updated_at)
+			SELECT passport_serie, passport_number, passport_serie_idx, passport_number_idx, name, surname, patronymic, address, NOW(), NOW()
+			FROM staged_users
+			ORDER BY n
+			ON CONFLICT DO NOTHING
+			RETURNING *
+		), audited AS (
+			INSERT INTO audit_events (actor, action, entity, entity_id, before, after, request_id, occurred_at)
+			SELECT $1::text, $2::text, $3::text, t.id, NULL, ` + userSnapshot + `, NULLIF($4::text, ''), NOW()
+			FROM created t
+		)
+		SELECT s.n, c.id
+		FROM staged_users s
+		JOIN created c ON c.passport_serie_idx = s.passport_serie_idx AND c.passport_number_idx = s.passport_number_idx
+	`
+	log.Debug("executing query", slog.String("query", query))
 
-	rows, err := db.pool.Query(ctx, query, args...)
+	rows, err := tx.Query(ctx, query, audit.Actor(ctx), models.ActionCreate, models.EntityUser, middleware.GetReqID(ctx))
 	if err != nil {
 		log.Error("failed to execute query", l.Err(err))
 
-		return nil, fmt.Errorf("%s: %w", "repo.Users", err)
+		return nil, fmt.Errorf("%s: %w", "repo.CreateUsers", err)
 	}
+
 	defer rows.Close()
 
-	var users []models.User
+	ids := make([]int32, len(users))
 	for rows.Next() {
-		var user models.User
-		err := rows.Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt, &user.Passport.Serie, &user.Passport.Number, &user.People.Name, &user.People.Surname, &user.People.Patronymic, &user.People.Address)
-		if err != nil {
+		var n, id int32
+		if err := rows.Scan(&n, &id); err != nil {
 			log.Error("failed to scan row", l.Err(err))
 
-			return nil, fmt.Errorf("%s: %w", "repo.Users", err)
+			return nil, fmt.Errorf("%s: %w", "repo.CreateUsers", err)
 		}
-
-		users = append(users, user)
+		ids[n] = id
 	}
 	if err := rows.Err(); err != nil {
 		log.Error("rows error", l.Err(err))
 
-		return nil, fmt.Errorf("%s: %w", "repo.Users", err)
+		return nil, fmt.Errorf("%s: %w", "repo.CreateUsers", err)
+	}
+	rows.Close()
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit transaction", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.CreateUsers", err)
+	}
+
+	log.Debug("successfully created users")
+
+	return ids, nil
+}
+
+// scanUser scans utils.UserColumns followed by any extra destinations and decrypts the passport
+func (db *DB) scanUser(row pgx.Row, extra ...any) (models.User, error) {
+	var user models.User
+	dest := []any{&user.ID, &user.CreatedAt, &user.UpdatedAt, &user.Passport.Serie, &user.Passport.Number, &user.People.Name, &user.People.Surname, &user.People.Patronymic, &user.People.Address, &user.WorklogPolicy, &user.EndOfDay, &user.Timezone, &user.Role, &user.ManagerID, &user.DeletedAt, &user.ErasedAt, &user.EnrichmentStatus}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return models.User{}, err
+	}
+	if err := db.decryptPassport(&user.Passport); err != nil {
+		return models.User{}, fmt.Errorf("user %d: %w", user.ID, err)
+	}
+
+	return user, nil
+}
+
+// Users returns a page of the users matching the filter. Passports are looked up by their blind indexes.
+func (db *DB) Users(ctx context.Context, filter models.FilterBy, settings models.Pagination) ([]models.User, models.Page, error) {
+	log := db.log.With(slog.Any("filter", filter), slog.Any("pagination", settings))
+
+	index, err := db.passportIndex(models.Passport{Serie: filter.PassportSerie, Number: filter.PassportNumber})
+	if err != nil {
+		log.Error("failed to index passport", l.Err(err))
+
+		return nil, models.Page{}, fmt.Errorf("%s: %w", "repo.Users", err)
+	}
+	filter.PassportSerie, filter.PassportNumber = index.Serie, index.Number
+
+	listing, err := utils.UsersListing(filter, settings.Sort)
+	if err != nil {
+		log.Warn("invalid sort", l.Err(err))
+
+		return nil, models.Page{}, fmt.Errorf("%s: %w", "repo.Users", err)
+	}
+	users, page, err := listPage(ctx, db, log, &listing, settings, db.scanUser)
+	if err != nil {
+		return nil, models.Page{}, fmt.Errorf("%s: %w", "repo.Users", err)
 	}
 
 	log.Debug("successfully retrieved users")
 
-	return users, nil
+	return users, page, nil
 }
 
+// DeleteUser soft-deletes the user. The user and their worklogs are kept
+// until they are restored with RestoreUser or purged by PurgeDeletedUsers.
 func (db *DB) DeleteUser(ctx context.Context, id int32) error {
-	query := "DELETE FROM users WHERE id = $1"
+	query := "UPDATE users SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL"
 
 	log := db.log.With(slog.Int("user_id", int(id)))
 	log.Debug("executing query", slog.String("query", query))
 
-	_, err := db.pool.Exec(ctx, query, id)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			log.Error("user not found", l.Err(err))
+	err := db.audited(ctx, models.ActionDelete, models.EntityUser, id, func(tx pgx.Tx) (int32, error) {
+		tag, err := tx.Exec(ctx, query, id)
+		if err != nil {
+			log.Error("failed to execute query", l.Err(err))
 
-			return fmt.Errorf("%s: %w", "repo.DeleteUser", ErrUserNotFound)
+			return 0, fmt.Errorf("%s: %w", "repo.DeleteUser", err)
 		}
-		log.Error("failed to execute query", l.Err(err))
+		if tag.RowsAffected() == 0 {
+			log.Warn("user not found")
 
-		return fmt.Errorf("%s: %w", "repo.DeleteUser", err)
+			return 0, ErrUserNotFound
+		}
+		return id, nil
+	})
+	if err != nil {
+		return err
 	}
 
 	log.Debug("successfully deleted user")
@@ -109,20 +250,266 @@ func (db *DB) DeleteUser(ctx context.Context, id int32) error {
 	return nil
 }
 
-func (db *DB) UpdateUser(ctx context.Context, user models.User) error {
-	query, args := utils.BuildUpdateUserQuery(user)
+// RestoreUser brings back a soft-deleted user
+func (db *DB) RestoreUser(ctx context.Context, id int32) error {
+	query := "UPDATE users SET deleted_at = NULL, updated_at = NOW() WHERE id = $1 AND deleted_at IS NOT NULL"
 
-	log := db.log.With(slog.Any("user", user))
-	log.Debug("executing query", slog.String("query", query), slog.Any("args", args))
+	log := db.log.With(slog.Int("user_id", int(id)))
+	log.Debug("executing query", slog.String("query", query))
+
+	err := db.audited(ctx, models.ActionRestore, models.EntityUser, id, func(tx pgx.Tx) (int32, error) {
+		tag, err := tx.Exec(ctx, query, id)
+		if err != nil {
+			log.Error("failed to execute query", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.RestoreUser", err)
+		}
+		if tag.RowsAffected() == 0 {
+			log.Warn("deleted user not found")
+
+			return 0, ErrUserNotFound
+		}
+		return id, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Debug("successfully restored user")
+
+	return nil
+}
+
+// PurgeDeletedUsers permanently deletes users that were soft-deleted more than retention ago,
+// together with their worklogs. The purge of every user and worklog is audited, and the users'
+// personal data is removed from the audit log, see EraseUser. Rows locked by a concurrent call
+// are skipped. It returns the IDs and passports of the purged users, so that the people info
+// cached for them can be invalidated.
+func (db *DB) PurgeDeletedUsers(ctx context.Context, retention time.Duration) ([]models.User, error) {
+	// worklogs are deleted by the cascade of the users, they are audited in the same statement
+	query := `
+		WITH expired AS (
+			SELECT id FROM users
+			WHERE deleted_at < NOW() - $1::interval
+			FOR UPDATE SKIP LOCKED
+		), purged_worklogs AS (
+			INSERT INTO audit_events (actor, action, entity, entity_id, before, after, request_id, occurred_at)
+			SELECT $2::text, $3::text, $4::text, t.id, ` + worklogSnapshot + `, NULL, NULLIF($5::text, ''), NOW()
+			FROM worklogs t
+			JOIN expired ON t.user_id = expired.id
+		)
+		DELETE FROM users t
+		USING expired
+		WHERE t.id = expired.id
+		RETURNING t.id, COALESCE(t.passport_serie, ''), COALESCE(t.passport_number, ''), ` + userSnapshot + `
+	`
+	log := db.log.With(slog.Duration("retention", retention))
+	log.Debug("executing query", slog.String("query", query))
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", l.Err(err))
 
-	_, err := db.pool.Exec(ctx, query, args...)
+		return nil, fmt.Errorf("%s: %w", "repo.PurgeDeletedUsers", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, query, retention, audit.Actor(ctx), models.ActionPurge, models.EntityWorklog, middleware.GetReqID(ctx))
 	if err != nil {
 		log.Error("failed to execute query", l.Err(err))
 
+		return nil, fmt.Errorf("%s: %w", "repo.PurgeDeletedUsers", err)
+	}
+
+	type purge struct {
+		id       int32
+		passport models.Passport
+		before   []byte
+	}
+	purges, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (purge, error) {
+		var p purge
+		err := row.Scan(&p.id, &p.passport.Serie, &p.passport.Number, &p.before)
+		return p, err
+	})
+	if err != nil {
+		log.Error("failed to collect rows", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.PurgeDeletedUsers", err)
+	}
+
+	// each purge is audited in the same transaction
+	purgedIDs := make([]int32, 0, len(purges))
+	for _, p := range purges {
+		if err := recordAudit(ctx, tx, models.ActionPurge, models.EntityUser, p.id, p.before, nil); err != nil {
+			log.Error("failed to record audit event", slog.Int("user_id", int(p.id)), l.Err(err))
+
+			return nil, fmt.Errorf("%s: %w", "repo.PurgeDeletedUsers", err)
+		}
+		purgedIDs = append(purgedIDs, p.id)
+	}
+
+	if err := scrubAuditEvents(ctx, tx, purgedIDs); err != nil {
+		log.Error("failed to scrub audit events", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.PurgeDeletedUsers", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit transaction", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.PurgeDeletedUsers", err)
+	}
+
+	// the users are purged anyway, undecryptable passports only can't be uncached
+	purged := make([]models.User, len(purges))
+	for i, p := range purges {
+		purged[i] = models.User{ID: p.id, Passport: p.passport}
+		if err := db.decryptPassport(&purged[i].Passport); err != nil {
+			log.Warn("failed to decrypt purged passport", slog.Int("user_id", int(p.id)), l.Err(err))
+
+			purged[i].Passport = models.Passport{}
+		}
+	}
+
+	log.Debug("deleted users purged", slog.Int("count", len(purged)))
+
+	return purged, nil
+}
+
+func (db *DB) UpdateUser(ctx context.Context, user models.User) error {
+	log := db.log.With(slog.Any("user", user))
+
+	passport, index, err := db.encryptPassport(user.Passport)
+	if err != nil {
+		log.Error("failed to encrypt passport", l.Err(err))
+
 		return fmt.Errorf("%s: %w", "repo.UpdateUser", err)
 	}
+	user.Passport = passport
+
+	query, args := utils.BuildUpdateUserQuery(user, index)
+	log.Debug("executing query", slog.String("query", query), slog.Any("args", args))
+
+	err = db.audited(ctx, models.ActionUpdate, models.EntityUser, user.ID, func(tx pgx.Tx) (int32, error) {
+		tag, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" { // the only unique columns are the passport indexes
+				log.Warn("user with such serie and number already exists")
+
+				return 0, ErrPassportDuplicate
+			}
+			log.Error("failed to execute query", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.UpdateUser", err)
+		}
+		if tag.RowsAffected() == 0 {
+			log.Warn("user not found")
+
+			return 0, ErrUserNotFound
+		}
+		return user.ID, nil
+	})
+	if err != nil {
+		return err
+	}
 
 	log.Debug("successfully updated user")
 
 	return nil
 }
+
+// SetCalendarToken replaces the hash of the user's calendar feed token
+func (db *DB) SetCalendarToken(ctx context.Context, userID int32, tokenHash string) error {
+	query := "UPDATE users SET calendar_token_hash = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL"
+
+	log := db.log.With(slog.Int("user_id", int(userID)))
+	log.Debug("executing query", slog.String("query", query))
+
+	tag, err := db.pool.Exec(ctx, query, userID, tokenHash)
+	if err != nil {
+		log.Error("failed to execute query", l.Err(err))
+
+		return fmt.Errorf("%s: %w", "repo.SetCalendarToken", err)
+	}
+	if tag.RowsAffected() == 0 {
+		log.Warn("user not found")
+
+		return ErrUserNotFound
+	}
+
+	log.Debug("successfully set calendar token")
+
+	return nil
+}
+
+// UserByCalendarToken finds the owner of a calendar feed token
+func (db *DB) UserByCalendarToken(ctx context.Context, tokenHash string) (int32, error) {
+	query := "SELECT id FROM users WHERE calendar_token_hash = $1 AND deleted_at IS NULL"
+
+	log := db.log
+	log.Debug("executing query", slog.String("query", query))
+
+	var userID int32
+	err := db.pool.QueryRow(ctx, query, tokenHash).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Warn("no user with such calendar token")
+
+			return 0, ErrUserNotFound
+		}
+		log.Error("failed to execute query", l.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", "repo.UserByCalendarToken", err)
+	}
+
+	log.Debug("found calendar token owner", slog.Int("user_id", int(userID)))
+
+	return userID, nil
+}
+
+// UserTimezone returns the name of the user's time zone
+func (db *DB) UserTimezone(ctx context.Context, userID int32) (string, error) {
+	query := "SELECT timezone FROM users WHERE id = $1"
+
+	log := db.log.With(slog.Int("user_id", int(userID)))
+	log.Debug("executing query", slog.String("query", query))
+
+	var timezone string
+	err := db.pool.QueryRow(ctx, query, userID).Scan(&timezone)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Warn("user not found")
+
+			return "", ErrUserNotFound
+		}
+		log.Error("failed to execute query", l.Err(err))
+
+		return "", fmt.Errorf("%s: %w", "repo.UserTimezone", err)
+	}
+
+	return timezone, nil
+}
+
+// UserAccess returns the role of the user and the manager whose team they are in.
+// Soft-deleted and erased users are not found, so their credentials stop working.
+func (db *DB) UserAccess(ctx context.Context, userID int32) (role string, managerID int32, err error) {
+	query := "SELECT role, COALESCE(manager_id, 0) FROM users WHERE id = $1 AND deleted_at IS NULL AND erased_at IS NULL"
+
+	log := db.log.With(slog.Int("user_id", int(userID)))
+	log.Debug("executing query", slog.String("query", query))
+
+	err = db.pool.QueryRow(ctx, query, userID).Scan(&role, &managerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Warn("user not found")
+
+			return "", 0, ErrUserNotFound
+		}
+		log.Error("failed to execute query", l.Err(err))
+
+		return "", 0, fmt.Errorf("%s: %w", "repo.UserAccess", err)
+	}
+
+	return role, managerID, nil
+}
@@ -8,99 +8,905 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/kuromii5/time-tracker/internal/models"
+	"github.com/kuromii5/time-tracker/internal/utils"
 	l "github.com/kuromii5/time-tracker/pkg/logger"
 )
 
 var (
-	ErrAlreadyDone = errors.New("worklog was already finished")
+	ErrAlreadyDone     = errors.New("worklog was already finished")
+	ErrWorklogNotFound = errors.New("worklog not found")
+	ErrAlreadyPaused   = errors.New("worklog is already paused")
+	ErrNotPaused       = errors.New("worklog is not paused")
+	ErrWorklogRunning  = errors.New("user already has a running worklog")
+	ErrWorklogOverlap  = errors.New("worklog overlaps with other worklogs of the user")
+	ErrInvalidRange    = errors.New("worklog should finish after it starts")
 )
 
-func (db *DB) StartWorklog(ctx context.Context, task string, userID int32) (int32, error) {
-	query := `
-		INSERT INTO worklogs (user_id, task, started_at)
-		VALUES ($1, $2, NOW())
-		RETURNING ID
-	`
-	log := db.log.With(slog.String("task", task), slog.Int("user_id", int(userID)))
-	log.Debug("executing query", slog.String("query", query))
+// OverlapError lists the worklogs of the same user that a worklog would overlap with.
+// It matches ErrWorklogOverlap with errors.Is.
+type OverlapError struct {
+	Conflicts []models.Worklog
+}
+
+func (e *OverlapError) Error() string {
+	return fmt.Sprintf("%s: %d conflicting worklogs", ErrWorklogOverlap, len(e.Conflicts))
+}
+
+func (e *OverlapError) Unwrap() error {
+	return ErrWorklogOverlap
+}
+
+// worklogColumns are worklogs together with their active and paused time, scanned by scanWorklog.
+// Active time is the sum of all segments, the open one is counted up to now.
+// Paused time is whatever is left of the worklog's wall-clock time.
+const worklogColumns = `
+	w.id, w.user_id, COALESCE(w.project_id, 0), w.task, w.started_at, w.finished_at,
+	CASE
+		WHEN w.finished_at IS NOT NULL THEN 'finished'
+		WHEN seg.open THEN 'running'
+		ELSE 'paused'
+	END AS status,
+	seg.active,
+	COALESCE(w.finished_at, NOW()) - w.started_at - seg.active AS paused,
+	w.auto_stopped
+`
+
+// worklogFrom joins worklogs with the totals of their segments used by worklogColumns
+const worklogFrom = `
+	FROM worklogs w
+	CROSS JOIN LATERAL (
+		SELECT
+			COALESCE(SUM(COALESCE(s.finished_at, NOW()) - s.started_at), INTERVAL '0') AS active,
+			COALESCE(BOOL_OR(s.finished_at IS NULL), FALSE) AS open
+		FROM worklog_segments s
+		WHERE s.worklog_id = w.id
+	) seg
+`
 
-	var worklogId int32
-	err := db.pool.QueryRow(ctx, query, userID, task).Scan(&worklogId)
+const worklogSelect = "SELECT " + worklogColumns + worklogFrom
+
+// finishWorklogQuery finishes an open worklog together with its open segment, if it is not paused
+const finishWorklogQuery = `
+	WITH w AS (
+		UPDATE worklogs
+		SET finished_at = NOW()
+		WHERE id = $1 AND finished_at IS NULL
+		RETURNING id, finished_at
+	), s AS (
+		UPDATE worklog_segments
+		SET finished_at = w.finished_at
+		FROM w
+		WHERE worklog_segments.worklog_id = w.id AND worklog_segments.finished_at IS NULL
+	)
+	SELECT id FROM w
+`
+
+// scanWorklog scans worklogColumns followed by any extra destinations
+func scanWorklog(row pgx.Row, extra ...any) (models.Worklog, error) {
+	var (
+		worklog    models.Worklog
+		finishedAt *time.Time
+	)
+	dest := []any{&worklog.ID, &worklog.UserID, &worklog.ProjectID, &worklog.Task, &worklog.StartedAt, &finishedAt, &worklog.Status, &worklog.Duration, &worklog.Paused, &worklog.AutoStopped}
+	err := row.Scan(append(dest, extra...)...)
 	if err != nil {
+		return models.Worklog{}, err
+	}
+	if finishedAt != nil {
+		worklog.FinishedAt = *finishedAt
+	}
+
+	return worklog, nil
+}
+
+// StartWorklog starts a new worklog for the user, optionally within a project.
+// If the user already has an open
+// worklog, the user's worklog policy decides whether to refuse with ErrWorklogRunning
+// or to finish the open one first. In the latter case its ID is returned as stoppedID.
+func (db *DB) StartWorklog(ctx context.Context, task string, userID, projectID int32) (worklogID, stoppedID int32, err error) {
+	log := db.log.With(slog.String("task", task), slog.Int("user_id", int(userID)), slog.Int("project_id", int(projectID)))
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", l.Err(err))
+
+		return 0, 0, fmt.Errorf("%s: %w", "repo.StartWorklog", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the user row, so concurrent starts for the same user are serialized
+	policyQuery := "SELECT worklog_policy FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE"
+	log.Debug("executing query", slog.String("query", policyQuery))
+
+	var policy string
+	if err := tx.QueryRow(ctx, policyQuery, userID).Scan(&policy); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Warn("user not found", l.Err(err))
+
+			return 0, 0, ErrUserNotFound
+		}
+		log.Error("failed to execute query", l.Err(err))
+
+		return 0, 0, fmt.Errorf("%s: %w", "repo.StartWorklog", err)
+	}
+
+	runningQuery := "SELECT id FROM worklogs WHERE user_id = $1 AND finished_at IS NULL"
+	log.Debug("executing query", slog.String("query", runningQuery))
+
+	var runningID int32
+	err = tx.QueryRow(ctx, runningQuery, userID).Scan(&runningID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// nothing is running
+	case err != nil:
+		log.Error("failed to execute query", l.Err(err))
+
+		return 0, 0, fmt.Errorf("%s: %w", "repo.StartWorklog", err)
+	case policy == models.PolicySwitch:
+		before, err := snapshot(ctx, tx, models.EntityWorklog, runningID)
+		if err != nil {
+			log.Error("failed to snapshot running worklog", slog.Int("running_id", int(runningID)), l.Err(err))
+
+			return 0, 0, fmt.Errorf("%s: %w", "repo.StartWorklog", err)
+		}
+
+		log.Debug("executing query", slog.String("query", finishWorklogQuery))
+
+		if err := tx.QueryRow(ctx, finishWorklogQuery, runningID).Scan(&stoppedID); err != nil {
+			log.Error("failed to finish running worklog", slog.Int("running_id", int(runningID)), l.Err(err))
+
+			return 0, 0, fmt.Errorf("%s: %w", "repo.StartWorklog", err)
+		}
+
+		if err := auditChange(ctx, tx, models.ActionFinish, models.EntityWorklog, stoppedID, before); err != nil {
+			log.Error("failed to record audit event", l.Err(err))
+
+			return 0, 0, fmt.Errorf("%s: %w", "repo.StartWorklog", err)
+		}
+	default:
+		log.Warn("user already has a running worklog", slog.Int("running_id", int(runningID)))
+
+		return 0, 0, fmt.Errorf("%w (worklog_id=%d)", ErrWorklogRunning, runningID)
+	}
+
+	startQuery := `
+		WITH w AS (
+			INSERT INTO worklogs (user_id, task, project_id, started_at)
+			VALUES ($1, $2, NULLIF($3, 0), NOW())
+			RETURNING id, started_at
+		)
+		INSERT INTO worklog_segments (worklog_id, started_at)
+		SELECT id, started_at FROM w
+		RETURNING worklog_id
+	`
+	log.Debug("executing query", slog.String("query", startQuery))
+
+	if err := tx.QueryRow(ctx, startQuery, userID, task, projectID).Scan(&worklogID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505": // the one-running-worklog index was violated
+				log.Warn("user already has a running worklog", l.Err(err))
+
+				return 0, 0, ErrWorklogRunning
+			case "23503": // the only nullable reference is the project
+				log.Warn("project not found", l.Err(err))
+
+				return 0, 0, ErrProjectNotFound
+			}
+		}
 		log.Error("failed to execute query", l.Err(err))
 
-		return 0, fmt.Errorf("%s: %w", "repo.StartWorklog", err)
+		return 0, 0, fmt.Errorf("%s: %w", "repo.StartWorklog", err)
 	}
 
-	log.Debug("worklog started successfully", slog.Int("worklog_id", int(worklogId)))
+	if err := auditChange(ctx, tx, models.ActionStart, models.EntityWorklog, worklogID, nil); err != nil {
+		log.Error("failed to record audit event", l.Err(err))
+
+		return 0, 0, fmt.Errorf("%s: %w", "repo.StartWorklog", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit transaction", l.Err(err))
+
+		return 0, 0, fmt.Errorf("%s: %w", "repo.StartWorklog", err)
+	}
 
-	return worklogId, nil
+	log.Debug("worklog started successfully", slog.Int("worklog_id", int(worklogID)), slog.Int("stopped_id", int(stoppedID)))
+
+	return worklogID, stoppedID, nil
 }
 
 func (db *DB) FinishWorklog(ctx context.Context, worklogID int32) error {
+	query := finishWorklogQuery
+	log := db.log.With(slog.Int("worklog_id", int(worklogID)))
+	log.Debug("executing query", slog.String("query", query))
+
+	err := db.audited(ctx, models.ActionFinish, models.EntityWorklog, worklogID, func(tx pgx.Tx) (int32, error) {
+		var id int32
+		err := tx.QueryRow(ctx, query, worklogID).Scan(&id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// No rows were updated, meaning the worklog was already finished
+				return 0, ErrAlreadyDone
+			}
+			log.Error("failed to execute query", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.FinishWorklog", err)
+		}
+		return id, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Debug("worklog finished successfully")
+
+	return nil
+}
+
+func (db *DB) PauseWorklog(ctx context.Context, worklogID int32) error {
 	query := `
-		UPDATE worklogs
+		UPDATE worklog_segments s
 		SET finished_at = NOW()
-		WHERE id = $1 AND finished_at IS NULL
+		FROM worklogs w
+		WHERE w.id = $1 AND w.finished_at IS NULL
+			AND s.worklog_id = w.id AND s.finished_at IS NULL
+		RETURNING s.id
+	`
+	log := db.log.With(slog.Int("worklog_id", int(worklogID)))
+	log.Debug("executing query", slog.String("query", query))
+
+	var segmentID int32
+	err := db.audited(ctx, models.ActionPause, models.EntityWorklog, worklogID, func(tx pgx.Tx) (int32, error) {
+		err := tx.QueryRow(ctx, query, worklogID).Scan(&segmentID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// Nothing was paused, find out why
+				return 0, worklogStateErr(ctx, tx, worklogID, ErrAlreadyPaused)
+			}
+			log.Error("failed to execute query", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.PauseWorklog", err)
+		}
+		return worklogID, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Debug("worklog paused successfully", slog.Int("segment_id", int(segmentID)))
+
+	return nil
+}
+
+func (db *DB) ResumeWorklog(ctx context.Context, worklogID int32) error {
+	query := `
+		INSERT INTO worklog_segments (worklog_id, started_at)
+		SELECT w.id, NOW() FROM worklogs w
+		WHERE w.id = $1 AND w.finished_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM worklog_segments s
+				WHERE s.worklog_id = w.id AND s.finished_at IS NULL
+			)
 		RETURNING id
 	`
 	log := db.log.With(slog.Int("worklog_id", int(worklogID)))
 	log.Debug("executing query", slog.String("query", query))
 
-	var id int32
-	err := db.pool.QueryRow(ctx, query, worklogID).Scan(&id)
+	var segmentID int32
+	err := db.audited(ctx, models.ActionResume, models.EntityWorklog, worklogID, func(tx pgx.Tx) (int32, error) {
+		err := tx.QueryRow(ctx, query, worklogID).Scan(&segmentID)
+		if err != nil {
+			// 23505 means a concurrent resume already opened a segment
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return 0, ErrNotPaused
+			}
+			if errors.Is(err, pgx.ErrNoRows) {
+				// Nothing was resumed, find out why
+				return 0, worklogStateErr(ctx, tx, worklogID, ErrNotPaused)
+			}
+			log.Error("failed to execute query", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.ResumeWorklog", err)
+		}
+		return worklogID, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Debug("worklog resumed successfully", slog.Int("segment_id", int(segmentID)))
+
+	return nil
+}
+
+// worklogStateErr explains why a state change did not touch the worklog:
+// it does not exist, it is finished, or it is already in the target state.
+// It reads through the transaction of the change, which may hold the worklog's lock.
+func worklogStateErr(ctx context.Context, tx pgx.Tx, worklogID int32, sameState error) error {
+	query := "SELECT finished_at IS NOT NULL FROM worklogs WHERE id = $1"
+
+	var finished bool
+	err := tx.QueryRow(ctx, query, worklogID).Scan(&finished)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			// No rows were updated, meaning the worklog was already finished
-			return ErrAlreadyDone
+			return ErrWorklogNotFound
 		}
-		log.Error("failed to execute query", l.Err(err))
 
-		return fmt.Errorf("%s: %w", "repo.FinishWorklog", err)
+		return fmt.Errorf("%s: %w", "repo.worklogStateErr", err)
+	}
+	if finished {
+		return ErrAlreadyDone
 	}
 
-	log.Debug("worklog finished successfully")
+	return sameState
+}
 
-	return nil
+// worklogSortKeys are the keys worklogs can be sorted by, named after the fields of the response.
+// Running worklogs end last. The duration of running worklogs grows between requests,
+// so cursors over it may skip or repeat them.
+var worklogSortKeys = map[string]utils.SortKey{
+	"id":         {Expr: "w.id", Type: "integer"},
+	"project_id": {Expr: "COALESCE(w.project_id, 0)", Type: "integer"},
+	"task":       {Expr: "w.task", Type: "text"},
+	"start_time": {Expr: "w.started_at", Type: "timestamptz"},
+	"end_time":   {Expr: "COALESCE(w.finished_at, 'infinity')", Type: "timestamptz"},
+	"duration":   {Expr: "seg.active", Type: "interval"},
 }
 
-func (db *DB) Worklogs(ctx context.Context, userID int32, startDate, endDate time.Time) ([]models.Worklog, error) {
-	query := `
-		SELECT * FROM worklogs
-		WHERE user_id = $1 AND started_at >= $2 AND (finished_at <= $3 OR finished_at IS NULL)
-		ORDER BY duration DESC
+// Worklogs returns a page of the user's worklogs matching the filter, ordered by settings.Sort or the latest ones first
+func (db *DB) Worklogs(ctx context.Context, userID int32, filter models.WorklogFilter, settings models.Pagination) ([]models.Worklog, models.Page, error) {
+	listing := utils.Listing{Columns: worklogColumns, From: worklogFrom}
+	listing.Where("w.user_id = " + listing.Arg(userID))
+	if !filter.From.IsZero() {
+		listing.Where("COALESCE(w.finished_at, NOW()) > " + listing.Arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		listing.Where("w.started_at < " + listing.Arg(filter.To))
+	}
+	if filter.ProjectID != 0 {
+		listing.Where("w.project_id = " + listing.Arg(filter.ProjectID))
+	}
+	if filter.ClientID != 0 {
+		listing.Where("w.project_id IN (SELECT id FROM projects WHERE client_id = " + listing.Arg(filter.ClientID) + ")")
+	}
+	if filter.AutoStopped {
+		listing.Where("w.auto_stopped")
+	}
+	switch filter.Status {
+	case models.WorklogRunning:
+		listing.Where("w.finished_at IS NULL AND seg.open")
+	case models.WorklogPaused:
+		listing.Where("w.finished_at IS NULL AND NOT seg.open")
+	case models.WorklogFinished:
+		listing.Where("w.finished_at IS NOT NULL")
+	}
+	if filter.Task != "" {
+		listing.Where("w.task ILIKE " + listing.Arg("%"+utils.EscapeLike(filter.Task)+"%"))
+	}
+	if filter.MinDuration > 0 {
+		listing.Where("seg.active >= " + listing.Arg(filter.MinDuration))
+	}
+	listing.Keys = []utils.SortKey{
+		{Name: "start_time", Expr: "w.started_at", Type: "timestamptz", Desc: true},
+		{Name: "id", Expr: "w.id", Type: "integer"},
+	}
+
+	log := db.log.With(slog.Int("user_id", int(userID)), slog.Any("filter", filter), slog.Any("pagination", settings))
+
+	if err := listing.OrderBy(settings.Sort, worklogSortKeys, utils.SortKey{Name: "id", Expr: "w.id", Type: "integer"}); err != nil {
+		log.Warn("invalid sort", l.Err(err))
+
+		return nil, models.Page{}, fmt.Errorf("%s: %w", "repo.Worklogs", err)
+	}
+
+	worklogs, page, err := listPage(ctx, db, log, &listing, settings, scanWorklog)
+	if err != nil {
+		return nil, models.Page{}, fmt.Errorf("%s: %w", "repo.Worklogs", err)
+	}
+
+	log.Debug("worklogs retrieved successfully", slog.Int("count", len(worklogs)))
+
+	return worklogs, page, nil
+}
+
+// ExportWorklogs passes worklogs started within the filter range to fn one by one, ordered by start time,
+// so that callers can stream them without loading the whole range into memory.
+// Iteration stops at the first error returned by fn.
+func (db *DB) ExportWorklogs(ctx context.Context, filter models.ExportFilter, fn func(models.WorklogExportRow) error) error {
+	query := "SELECT " + worklogColumns + `,
+			u.name, u.surname, COALESCE(u.patronymic, ''), COALESCE(p.name, ''), COALESCE(c.name, ''), u.timezone
+		` + worklogFrom + `
+		JOIN users u ON u.id = w.user_id
+		LEFT JOIN projects p ON p.id = w.project_id
+		LEFT JOIN clients c ON c.id = p.client_id
+		WHERE w.started_at >= $1 AND w.started_at < $2
+			AND ($3 = 0 OR w.user_id = $3)
+			AND ($4 = 0 OR w.project_id = $4)
+			AND ($5 = 0 OR p.client_id = $5)
+		ORDER BY w.started_at, w.id
 	`
-	log := db.log.With(slog.Int("user_id", int(userID)), slog.Time("start_date", startDate), slog.Time("end_date", endDate))
+	log := db.log.With(slog.Any("filter", filter))
 	log.Debug("executing query", slog.String("query", query))
 
-	rows, err := db.pool.Query(ctx, query, userID, startDate, endDate)
+	rows, err := db.pool.Query(ctx, query, filter.From, filter.To, filter.UserID, filter.ProjectID, filter.ClientID)
 	if err != nil {
 		log.Error("failed to execute query", l.Err(err))
 
-		return nil, fmt.Errorf("%s: %w", "repo.Worklogs", err)
+		return fmt.Errorf("%s: %w", "repo.ExportWorklogs", err)
 	}
 	defer rows.Close()
 
-	var worklogs []models.Worklog
+	count := 0
 	for rows.Next() {
-		var worklog models.Worklog
-		err := rows.Scan(&worklog.ID, &worklog.UserID, &worklog.StartedAt, &worklog.FinishedAt, &worklog.Task, &worklog.Duration)
+		var row models.WorklogExportRow
+		row.Worklog, err = scanWorklog(rows, &row.Name, &row.Surname, &row.Patronymic, &row.Project, &row.Client, &row.Timezone)
 		if err != nil {
 			log.Error("failed to scan row", l.Err(err))
 
-			return nil, fmt.Errorf("%s: %w", "repo.Worklogs", err)
+			return fmt.Errorf("%s: %w", "repo.ExportWorklogs", err)
 		}
 
-		worklogs = append(worklogs, worklog)
+		if err := fn(row); err != nil {
+			log.Error("failed to export row", slog.Int("worklog_id", int(row.ID)), l.Err(err))
+
+			return fmt.Errorf("%s: %w", "repo.ExportWorklogs", err)
+		}
+		count++
 	}
 	if err := rows.Err(); err != nil {
 		log.Error("rows error", l.Err(err))
 
-		return nil, fmt.Errorf("%s: %w", "repo.Worklogs", err)
+		return fmt.Errorf("%s: %w", "repo.ExportWorklogs", err)
 	}
 
-	log.Debug("worklogs retrieved successfully", slog.Int("count", len(worklogs)))
+	log.Debug("worklogs exported successfully", slog.Int("count", count))
+
+	return nil
+}
+
+// lockUser locks the user row for the rest of the transaction.
+// All changes of a user's worklog timeline take this lock, so their checks can't race.
+// Soft-deleted users are not found, their timeline can't change until they are restored.
+func lockUser(ctx context.Context, tx pgx.Tx, userID int32) error {
+	var id int32
+	err := tx.QueryRow(ctx, "SELECT id FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", userID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrUserNotFound
+	}
+
+	return err
+}
+
+// checkOverlap returns an OverlapError if [start, end) intersects any other worklog of the user.
+// Open worklogs are treated as lasting forever, zero end means the checked worklog is open too.
+func checkOverlap(ctx context.Context, tx pgx.Tx, userID, worklogID int32, start, end time.Time) error {
+	query := worklogSelect + `
+		WHERE w.user_id = $1 AND w.id <> $2
+			AND w.started_at < COALESCE($4, 'infinity'::timestamptz)
+			AND COALESCE(w.finished_at, 'infinity'::timestamptz) > $3
+		ORDER BY w.started_at
+	`
+	var endArg *time.Time
+	if !end.IsZero() {
+		endArg = &end
+	}
+
+	rows, err := tx.Query(ctx, query, userID, worklogID, start, endArg)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var conflicts []models.Worklog
+	for rows.Next() {
+		worklog, err := scanWorklog(rows)
+		if err != nil {
+			return err
+		}
+		conflicts = append(conflicts, worklog)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(conflicts) > 0 {
+		return &OverlapError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// CreateWorklog records a finished worklog with explicit start and finish time as a single segment
+func (db *DB) CreateWorklog(ctx context.Context, worklog models.Worklog) (int32, error) {
+	log := db.log.With(slog.Any("worklog", worklog))
+
+	if !worklog.FinishedAt.After(worklog.StartedAt) {
+		return 0, ErrInvalidRange
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", l.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", "repo.CreateWorklog", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockUser(ctx, tx, worklog.UserID); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			log.Warn("user not found")
+
+			return 0, err
+		}
+		log.Error("failed to lock user", l.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", "repo.CreateWorklog", err)
+	}
+
+	if err := checkOverlap(ctx, tx, worklog.UserID, 0, worklog.StartedAt, worklog.FinishedAt); err != nil {
+		if errors.Is(err, ErrWorklogOverlap) {
+			log.Warn("worklog overlaps with other worklogs", l.Err(err))
+
+			return 0, err
+		}
+		log.Error("failed to check overlap", l.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", "repo.CreateWorklog", err)
+	}
+
+	query := `
+		WITH w AS (
+			INSERT INTO worklogs (user_id, task, project_id, started_at, finished_at)
+			VALUES ($1, $2, NULLIF($3, 0), $4, $5)
+			RETURNING id, started_at, finished_at
+		)
+		INSERT INTO worklog_segments (worklog_id, started_at, finished_at)
+		SELECT id, started_at, finished_at FROM w
+		RETURNING worklog_id
+	`
+	log.Debug("executing query", slog.String("query", query))
+
+	var worklogID int32
+	err = tx.QueryRow(ctx, query, worklog.UserID, worklog.Task, worklog.ProjectID, worklog.StartedAt, worklog.FinishedAt).Scan(&worklogID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // the user is locked, so it's the project
+			log.Warn("project not found", l.Err(err))
+
+			return 0, ErrProjectNotFound
+		}
+		log.Error("failed to execute query", l.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", "repo.CreateWorklog", err)
+	}
+
+	if err := auditChange(ctx, tx, models.ActionCreate, models.EntityWorklog, worklogID, nil); err != nil {
+		log.Error("failed to record audit event", l.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", "repo.CreateWorklog", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit transaction", l.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", "repo.CreateWorklog", err)
+	}
+
+	log.Debug("worklog created successfully", slog.Int("worklog_id", int(worklogID)))
+
+	return worklogID, nil
+}
+
+// UpdateWorklog changes the task, project or time range of a worklog. Zero fields are left unchanged.
+// An edited worklog counts as reviewed, so it loses its auto-stopped flag.
+// Segments are clipped to the new range and the outermost ones are stretched to its bounds,
+// so pauses inside the range are kept. Setting the finish time of an open worklog finishes it.
+func (db *DB) UpdateWorklog(ctx context.Context, worklog models.Worklog) error {
+	log := db.log.With(slog.Any("worklog", worklog))
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", l.Err(err))
+
+		return fmt.Errorf("%s: %w", "repo.UpdateWorklog", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID int32
+	err = tx.QueryRow(ctx, "SELECT user_id FROM worklogs WHERE id = $1", worklog.ID).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Warn("worklog not found")
+
+			return ErrWorklogNotFound
+		}
+		log.Error("failed to get worklog owner", l.Err(err))
+
+		return fmt.Errorf("%s: %w", "repo.UpdateWorklog", err)
+	}
+
+	if err := lockUser(ctx, tx, userID); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			log.Warn("worklog owner is deleted")
+
+			return err
+		}
+		log.Error("failed to lock user", l.Err(err))
+
+		return fmt.Errorf("%s: %w", "repo.UpdateWorklog", err)
+	}
+
+	var (
+		start      time.Time
+		finishedAt *time.Time
+	)
+	err = tx.QueryRow(ctx, "SELECT started_at, finished_at FROM worklogs WHERE id = $1 FOR UPDATE", worklog.ID).Scan(&start, &finishedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Warn("worklog not found")
+
+			return ErrWorklogNotFound
+		}
+		log.Error("failed to get worklog", l.Err(err))
+
+		return fmt.Errorf("%s: %w", "repo.UpdateWorklog", err)
+	}
+
+	before, err := snapshot(ctx, tx, models.EntityWorklog, worklog.ID)
+	if err != nil {
+		log.Error("failed to snapshot worklog", l.Err(err))
+
+		return fmt.Errorf("%s: %w", "repo.UpdateWorklog", err)
+	}
+
+	var end time.Time
+	if finishedAt != nil {
+		end = *finishedAt
+	}
+	if !worklog.StartedAt.IsZero() {
+		start = worklog.StartedAt
+	}
+	if !worklog.FinishedAt.IsZero() {
+		end = worklog.FinishedAt
+	}
+	if !end.IsZero() && !end.After(start) {
+		return ErrInvalidRange
+	}
+
+	if err := checkOverlap(ctx, tx, userID, worklog.ID, start, end); err != nil {
+		if errors.Is(err, ErrWorklogOverlap) {
+			log.Warn("worklog overlaps with other worklogs", l.Err(err))
+
+			return err
+		}
+		log.Error("failed to check overlap", l.Err(err))
+
+		return fmt.Errorf("%s: %w", "repo.UpdateWorklog", err)
+	}
+
+	var endArg *time.Time
+	if !end.IsZero() {
+		endArg = &end
+	}
+
+	id := worklog.ID
+	queries := []struct {
+		query string
+		args  []any
+	}{
+		// update the worklog itself
+		{`UPDATE worklogs
+		SET task = COALESCE(NULLIF($4, ''), task),
+			project_id = CASE WHEN $6::boolean THEN NULL ELSE COALESCE(NULLIF($5, 0), project_id) END,
+			started_at = $2,
+			finished_at = $3,
+			auto_stopped = FALSE
+		WHERE id = $1`, []any{id, start, endArg, worklog.Task, worklog.ProjectID, worklog.ClearProject}},
+		// drop segments outside of the new range
+		{`DELETE FROM worklog_segments
+		WHERE worklog_id = $1
+			AND (COALESCE(finished_at, 'infinity'::timestamptz) <= $2 OR started_at >= COALESCE($3, 'infinity'::timestamptz))`, []any{id, start, endArg}},
+		// clip the rest to the new range
+		{`UPDATE worklog_segments
+		SET started_at = GREATEST(started_at, $2),
+			finished_at = CASE WHEN $3::timestamptz IS NULL THEN finished_at ELSE LEAST(COALESCE(finished_at, $3), $3) END
+		WHERE worklog_id = $1`, []any{id, start, endArg}},
+		// stretch the first segment to the new start
+		{`UPDATE worklog_segments
+		SET started_at = $2
+		WHERE id = (SELECT id FROM worklog_segments WHERE worklog_id = $1 ORDER BY started_at LIMIT 1)`, []any{id, start}},
+		// stretch the last segment to the new finish, unless the worklog is still open
+		{`UPDATE worklog_segments
+		SET finished_at = $2
+		WHERE $2::timestamptz IS NOT NULL
+			AND id = (SELECT id FROM worklog_segments WHERE worklog_id = $1 ORDER BY started_at DESC LIMIT 1)`, []any{id, endArg}},
+		// a range that didn't intersect with any segment becomes a single segment
+		{`INSERT INTO worklog_segments (worklog_id, started_at, finished_at)
+		SELECT $1::int, $2::timestamptz, $3::timestamptz
+		WHERE NOT EXISTS (SELECT 1 FROM worklog_segments WHERE worklog_id = $1)`, []any{id, start, endArg}},
+	}
+	for _, q := range queries {
+		log.Debug("executing query", slog.String("query", q.query))
+
+		if _, err := tx.Exec(ctx, q.query, q.args...); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				log.Warn("project not found", l.Err(err))
+
+				return ErrProjectNotFound
+			}
+			log.Error("failed to execute query", l.Err(err))
+
+			return fmt.Errorf("%s: %w", "repo.UpdateWorklog", err)
+		}
+	}
+
+	if err := auditChange(ctx, tx, models.ActionUpdate, models.EntityWorklog, worklog.ID, before); err != nil {
+		log.Error("failed to record audit event", l.Err(err))
+
+		return fmt.Errorf("%s: %w", "repo.UpdateWorklog", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit transaction", l.Err(err))
+
+		return fmt.Errorf("%s: %w", "repo.UpdateWorklog", err)
+	}
+
+	log.Debug("worklog updated successfully")
+
+	return nil
+}
+
+func (db *DB) DeleteWorklog(ctx context.Context, worklogID int32) error {
+	query := "DELETE FROM worklogs WHERE id = $1"
+
+	log := db.log.With(slog.Int("worklog_id", int(worklogID)))
+	log.Debug("executing query", slog.String("query", query))
+
+	err := db.audited(ctx, models.ActionDelete, models.EntityWorklog, worklogID, func(tx pgx.Tx) (int32, error) {
+		tag, err := tx.Exec(ctx, query, worklogID)
+		if err != nil {
+			log.Error("failed to execute query", l.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", "repo.DeleteWorklog", err)
+		}
+		if tag.RowsAffected() == 0 {
+			log.Warn("worklog not found")
+
+			return 0, ErrWorklogNotFound
+		}
+		return worklogID, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Debug("worklog deleted successfully")
+
+	return nil
+}
+
+// AutoStopWorklogs finishes worklogs that were left running for too long and flags them as auto-stopped.
+// A worklog is stopped at its start plus maxDuration, or at the owner's end of day
+// in the owner's time zone if that comes earlier.
+// Segments after the stop time are dropped. Rows locked by a concurrent call are skipped.
+func (db *DB) AutoStopWorklogs(ctx context.Context, maxDuration time.Duration) ([]int32, error) {
+	query := `
+		WITH stale AS (
+			SELECT w.id, stop.at, (SELECT ` + worklogSnapshot + ` FROM worklogs t WHERE t.id = w.id) AS before
+			FROM worklogs w
+			JOIN users u ON u.id = w.user_id
+			CROSS JOIN LATERAL (
+				SELECT w.started_at AT TIME ZONE u.timezone AS local_start
+			) l
+			CROSS JOIN LATERAL (
+				SELECT LEAST(
+					w.started_at + $1::interval,
+					CASE
+						WHEN u.end_of_day IS NULL THEN NULL
+						WHEN l.local_start::date + u.end_of_day > l.local_start THEN l.local_start::date + u.end_of_day
+						ELSE l.local_start::date + 1 + u.end_of_day
+					END AT TIME ZONE u.timezone
+				) AS at
+			) stop
+			WHERE w.finished_at IS NULL AND stop.at < NOW()
+			FOR UPDATE OF w SKIP LOCKED
+		), dropped AS (
+			DELETE FROM worklog_segments s
+			USING stale
+			WHERE s.worklog_id = stale.id AND s.started_at >= stale.at
+		), clipped AS (
+			UPDATE worklog_segments s
+			SET finished_at = stale.at
+			FROM stale
+			WHERE s.worklog_id = stale.id AND s.started_at < stale.at
+				AND COALESCE(s.finished_at, 'infinity'::timestamptz) > stale.at
+		)
+		UPDATE worklogs w
+		SET finished_at = stale.at, auto_stopped = TRUE
+		FROM stale
+		WHERE w.id = stale.id
+		RETURNING w.id, stale.before
+	`
+	log := db.log.With(slog.Duration("max_duration", maxDuration))
+	log.Debug("executing query", slog.String("query", query))
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.AutoStopWorklogs", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, query, maxDuration)
+	if err != nil {
+		log.Error("failed to execute query", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.AutoStopWorklogs", err)
+	}
+
+	type stop struct {
+		id     int32
+		before []byte
+	}
+	stops, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (stop, error) {
+		var s stop
+		err := row.Scan(&s.id, &s.before)
+		return s, err
+	})
+	if err != nil {
+		log.Error("failed to collect rows", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.AutoStopWorklogs", err)
+	}
+
+	// each stop is audited in the same transaction
+	stopped := make([]int32, 0, len(stops))
+	for _, s := range stops {
+		if err := auditChange(ctx, tx, models.ActionAutoStop, models.EntityWorklog, s.id, s.before); err != nil {
+			log.Error("failed to record audit event", slog.Int("worklog_id", int(s.id)), l.Err(err))
+
+			return nil, fmt.Errorf("%s: %w", "repo.AutoStopWorklogs", err)
+		}
+		stopped = append(stopped, s.id)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit transaction", l.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", "repo.AutoStopWorklogs", err)
+	}
+
+	log.Debug("forgotten worklogs stopped", slog.Int("count", len(stopped)))
+
+	return stopped, nil
+}
+
+// WorklogOwner returns the ID of the user the worklog belongs to
+func (db *DB) WorklogOwner(ctx context.Context, worklogID int32) (int32, error) {
+	query := "SELECT user_id FROM worklogs WHERE id = $1"
+
+	log := db.log.With(slog.Int("worklog_id", int(worklogID)))
+	log.Debug("executing query", slog.String("query", query))
+
+	var userID int32
+	err := db.pool.QueryRow(ctx, query, worklogID).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Warn("worklog not found")
+
+			return 0, ErrWorklogNotFound
+		}
+		log.Error("failed to execute query", l.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", "repo.WorklogOwner", err)
+	}
 
-	return worklogs, nil
+	return userID, nil
 }
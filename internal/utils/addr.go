@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+var ErrNonPublicAddr = errors.New("address is not public")
+
+// nonPublicPrefixes are the ranges netip doesn't classify but that are not reachable on the internet,
+// like the shared address space that cloud providers put metadata services in
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// IsPublicAddr reports whether addr is a global unicast address, so not a loopback,
+// private, link-local, multicast or unspecified one
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckPublicHost resolves the host and returns ErrNonPublicAddr if any of its addresses is not public
+func CheckPublicHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !IsPublicAddr(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrNonPublicAddr, host, addr)
+		}
+	}
+	return nil
+}
+
+// PublicDialer returns a dialer that only connects to public addresses. The check is made on
+// the address actually dialed, so hosts can't be made to resolve to internal addresses later.
+func PublicDialer() *net.Dialer {
+	return &net.Dialer{
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !IsPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrNonPublicAddr, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,276 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+)
+
+// DefaultPageSize is the page size of cursor pagination if no limit is given
+const DefaultPageSize = 50
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort")
+)
+
+// SortKey is an expression a listing is ordered by
+type SortKey struct {
+	// Name identifies the key in cursors
+	Name string
+	// Expr is the SQL expression of the key, it must never be NULL
+	Expr string
+	// Type is the SQL type cursor values are cast back to from text
+	Type string
+	Desc bool
+}
+
+// cursor points at the row a page starts after, or, going backward, ends before.
+// It is sent to clients as opaque base64 of JSON.
+type cursor struct {
+	// Sort are the names of the sort keys, a cursor is only valid for the same order
+	Sort string `json:"s"`
+	// Values are the sort keys of the row, as text
+	Values   []string `json:"v"`
+	Backward bool     `json:"b,omitempty"`
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// Listing builds the queries of a page of rows and of the count of all rows.
+// Rows are ordered by Keys, the last of which must be unique, so that pages can continue
+// after the last row of the previous one (keyset pagination) instead of skipping rows by offset.
+type Listing struct {
+	Columns string
+	// From is everything between the columns and the conditions
+	From       string
+	Conditions []string
+	Args       []interface{}
+	Keys       []SortKey
+}
+
+// Arg adds a query argument and returns its placeholder
+func (l *Listing) Arg(value interface{}) string {
+	l.Args = append(l.Args, value)
+	return fmt.Sprintf("$%d", len(l.Args))
+}
+
+// Where adds a condition
+func (l *Listing) Where(condition string) {
+	l.Conditions = append(l.Conditions, condition)
+}
+
+// OrderBy orders the listing by sort, a comma-separated list of keys, each optionally prefixed with "-"
+// for descending order, e.g. "-created_at,surname". Keys are looked up in sortable, and unique is appended
+// unless it is already there, so that the order is stable. The listing keeps its order if sort is empty.
+func (l *Listing) OrderBy(sort string, sortable map[string]SortKey, unique SortKey) error {
+	if sort == "" {
+		return nil
+	}
+
+	var keys []SortKey
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(sort, ",") {
+		name = strings.TrimSpace(name)
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		key, ok := sortable[name]
+		if !ok {
+			names := make([]string, 0, len(sortable))
+			for name := range sortable {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			return fmt.Errorf("%w: unknown key %q, should be one of %s", ErrInvalidSort, name, strings.Join(names, ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("%w: key %q is repeated", ErrInvalidSort, name)
+		}
+		seen[name] = true
+
+		key.Name, key.Desc = name, desc
+		keys = append(keys, key)
+	}
+	if !seen[unique.Name] {
+		keys = append(keys, unique)
+	}
+
+	l.Keys = keys
+	return nil
+}
+
+func (l *Listing) sortNames() string {
+	names := make([]string, len(l.Keys))
+	for i, key := range l.Keys {
+		names[i] = key.Name
+		if key.Desc {
+			names[i] = "-" + key.Name
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func (l *Listing) where(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// CountQuery returns the query of the number of all rows matching the conditions
+func (l *Listing) CountQuery() (string, []interface{}) {
+	query := "SELECT COUNT(*) " + l.From + l.where(l.Conditions)
+
+	// arguments used only by the keys are left out, Postgres refuses arguments it is not given placeholders for
+	used := 0
+	for _, m := range placeholder.FindAllStringSubmatch(query, -1) {
+		n, _ := strconv.Atoi(m[1])
+		used = max(used, n)
+	}
+
+	return query, l.Args[:used]
+}
+
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+// PageQuery returns the query of the page. Every row is followed by the text of its sort keys,
+// which Paginate needs. With a cursor, the offset is ignored and one row more than the limit is
+// fetched to learn if there are more.
+func (l Listing) PageQuery(p models.Pagination) (string, []interface{}, error) {
+	// arguments of the page are added to a copy, so that the listing can still be counted
+	l.Args = slices.Clip(l.Args)
+
+	var query strings.Builder
+	query.WriteString("SELECT " + l.Columns)
+	for _, key := range l.Keys {
+		query.WriteString(", (" + key.Expr + ")::text")
+	}
+	query.WriteString(" " + l.From)
+
+	conditions := l.Conditions
+	backward := false
+	limit := p.Limit
+	if p.Cursor != "" {
+		c, err := decodeCursor(p.Cursor)
+		if err != nil {
+			return "", nil, err
+		}
+		if c.Sort != l.sortNames() || len(c.Values) != len(l.Keys) {
+			return "", nil, fmt.Errorf("%w: it belongs to another order", ErrInvalidCursor)
+		}
+		backward = c.Backward
+		conditions = append(slices.Clip(conditions), l.after(c.Values, backward))
+
+		if limit <= 0 {
+			limit = DefaultPageSize
+		}
+	}
+	query.WriteString(l.where(conditions))
+
+	order := make([]string, len(l.Keys))
+	for i, key := range l.Keys {
+		// going backward, rows are fetched in reverse and put back in order by Paginate
+		desc := key.Desc != backward
+		order[i] = key.Expr
+		if desc {
+			order[i] += " DESC"
+		}
+	}
+	query.WriteString(" ORDER BY " + strings.Join(order, ", "))
+
+	if limit > 0 {
+		query.WriteString(" LIMIT " + l.Arg(limit+1))
+	}
+	if p.Cursor == "" && p.Offset > 0 {
+		query.WriteString(" OFFSET " + l.Arg(p.Offset))
+	}
+
+	return query.String(), l.Args, nil
+}
+
+// after returns the condition of rows following the values in the order of the keys, or preceding them going backward:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+func (l *Listing) after(values []string, backward bool) string {
+	var or []string
+	for i, key := range l.Keys {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, fmt.Sprintf("%s = %s::text::%s", l.Keys[j].Expr, l.Arg(values[j]), l.Keys[j].Type))
+		}
+		op := ">"
+		if key.Desc != backward {
+			op = "<"
+		}
+		and = append(and, fmt.Sprintf("%s %s %s::text::%s", key.Expr, op, l.Arg(values[i]), key.Type))
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+	return "(" + strings.Join(or, " OR ") + ")"
+}
+
+// Paginate turns the rows fetched by the page query, with the text of their sort keys, into the page:
+// the row fetched to learn if there are more is dropped, rows fetched backward are put in order,
+// and the cursors of the next and previous pages are set.
+func Paginate[T any](l *Listing, p models.Pagination, rows []T, keys [][]string) ([]T, models.Page) {
+	var page models.Page
+
+	limit := p.Limit
+	if p.Cursor != "" && limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit <= 0 {
+		// everything was fetched, there are no other pages
+		return rows, page
+	}
+
+	backward := false
+	if p.Cursor != "" {
+		c, _ := decodeCursor(p.Cursor)
+		backward = c.Backward
+	}
+
+	more := len(rows) > limit
+	if more {
+		rows, keys = rows[:limit], keys[:limit]
+	}
+	if backward {
+		slices.Reverse(rows)
+		slices.Reverse(keys)
+	}
+	if len(rows) == 0 {
+		return rows, page
+	}
+
+	sort := l.sortNames()
+	// the page we came from is on the other side of the cursor, so it is always there
+	if backward || more {
+		page.NextCursor = encodeCursor(cursor{Sort: sort, Values: keys[len(keys)-1]})
+	}
+	if backward && more || !backward && (p.Cursor != "" || p.Offset > 0) {
+		page.PrevCursor = encodeCursor(cursor{Sort: sort, Values: keys[0], Backward: true})
+	}
+
+	return rows, page
+}
@@ -0,0 +1,297 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+)
+
+var testSortable = map[string]SortKey{
+	"id":      {Expr: "id", Type: "integer"},
+	"name":    {Expr: "name", Type: "text"},
+	"created": {Expr: "created_at", Type: "timestamptz"},
+}
+
+var testUnique = SortKey{Name: "id", Expr: "id", Type: "integer"}
+
+func newTestListing(t *testing.T, sort string) *Listing {
+	t.Helper()
+
+	l := &Listing{Columns: "id, name", From: "FROM users"}
+	l.Where("deleted_at IS NULL")
+	if err := l.OrderBy(sort, testSortable, testUnique); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []cursor{
+		{Sort: "id", Values: []string{"1"}},
+		{Sort: "-created,id", Values: []string{"2024-06-01 10:00:00+00", "42"}, Backward: true},
+		{Sort: "name,id", Values: []string{"O'Brien, \"Jr\"", "7"}},
+	}
+
+	for _, c := range tests {
+		encoded := encodeCursor(c)
+		if strings.ContainsAny(encoded, "+/=") {
+			t.Errorf("encodeCursor(%v) = %q, want URL-safe base64", c, encoded)
+		}
+		got, err := decodeCursor(encoded)
+		if err != nil {
+			t.Fatalf("decodeCursor(%q) error = %v", encoded, err)
+		}
+		if !reflect.DeepEqual(got, c) {
+			t.Errorf("decodeCursor(encodeCursor(%v)) = %v", c, got)
+		}
+	}
+}
+
+func TestDecodeCursorErrors(t *testing.T) {
+	for _, s := range []string{"not base64!", "bm90IGpzb24"} {
+		if _, err := decodeCursor(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeCursor(%q) error = %v, want %v", s, err, ErrInvalidCursor)
+		}
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		sort    string
+		want    string
+		wantErr error
+	}{
+		{"unique appended", "-created", "-created,id", nil},
+		{"unique given", "id,name", "id,name", nil},
+		{"unique descending", "name,-id", "name,-id", nil},
+		{"spaces", " name , -created ", "name,-created,id", nil},
+		{"unknown key", "age", "", ErrInvalidSort},
+		{"repeated key", "name,-name", "", ErrInvalidSort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Listing{}
+			err := l.OrderBy(tt.sort, testSortable, testUnique)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("OrderBy() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && l.sortNames() != tt.want {
+				t.Errorf("OrderBy() keys = %q, want %q", l.sortNames(), tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderByEmptyKeepsOrder(t *testing.T) {
+	l := &Listing{Keys: []SortKey{testUnique}}
+	if err := l.OrderBy("", testSortable, testUnique); err != nil {
+		t.Fatal(err)
+	}
+	if l.sortNames() != "id" {
+		t.Errorf("OrderBy(\"\") keys = %q, want %q", l.sortNames(), "id")
+	}
+}
+
+func TestAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     string
+		backward bool
+		want     string
+	}{
+		{
+			name: "single key",
+			sort: "id",
+			want: "((id > $1::text::integer))",
+		},
+		{
+			name: "descending key",
+			sort: "-created",
+			want: "((created_at < $1::text::timestamptz) OR (created_at = $2::text::timestamptz AND id > $3::text::integer))",
+		},
+		{
+			name:     "backward",
+			sort:     "-created",
+			backward: true,
+			want:     "((created_at > $1::text::timestamptz) OR (created_at = $2::text::timestamptz AND id < $3::text::integer))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Listing{}
+			if err := l.OrderBy(tt.sort, testSortable, testUnique); err != nil {
+				t.Fatal(err)
+			}
+			values := make([]string, len(l.Keys))
+			for i := range values {
+				values[i] = "v"
+			}
+			if got := l.after(values, tt.backward); got != tt.want {
+				t.Errorf("after() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageQuery(t *testing.T) {
+	l := newTestListing(t, "-created")
+	next := encodeCursor(cursor{Sort: "-created,id", Values: []string{"2024-06-01", "42"}})
+	prev := encodeCursor(cursor{Sort: "-created,id", Values: []string{"2024-06-01", "42"}, Backward: true})
+
+	tests := []struct {
+		name     string
+		page     models.Pagination
+		want     string
+		wantArgs []interface{}
+		wantErr  error
+	}{
+		{
+			name: "everything",
+			want: "SELECT id, name, (created_at)::text, (id)::text FROM users WHERE deleted_at IS NULL ORDER BY created_at DESC, id",
+		},
+		{
+			name:     "offset",
+			page:     models.Pagination{Limit: 10, Offset: 20},
+			want:     "SELECT id, name, (created_at)::text, (id)::text FROM users WHERE deleted_at IS NULL ORDER BY created_at DESC, id LIMIT $1 OFFSET $2",
+			wantArgs: []interface{}{11, 20},
+		},
+		{
+			name: "next page",
+			page: models.Pagination{Cursor: next, Offset: 20},
+			want: "SELECT id, name, (created_at)::text, (id)::text FROM users WHERE deleted_at IS NULL AND " +
+				"((created_at < $1::text::timestamptz) OR (created_at = $2::text::timestamptz AND id > $3::text::integer)) " +
+				"ORDER BY created_at DESC, id LIMIT $4",
+			wantArgs: []interface{}{"2024-06-01", "2024-06-01", "42", DefaultPageSize + 1},
+		},
+		{
+			name: "previous page",
+			page: models.Pagination{Cursor: prev, Limit: 5},
+			want: "SELECT id, name, (created_at)::text, (id)::text FROM users WHERE deleted_at IS NULL AND " +
+				"((created_at > $1::text::timestamptz) OR (created_at = $2::text::timestamptz AND id < $3::text::integer)) " +
+				"ORDER BY created_at, id DESC LIMIT $4",
+			wantArgs: []interface{}{"2024-06-01", "2024-06-01", "42", 6},
+		},
+		{
+			name:    "cursor of another order",
+			page:    models.Pagination{Cursor: encodeCursor(cursor{Sort: "name,id", Values: []string{"a", "1"}})},
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "cursor with missing values",
+			page:    models.Pagination{Cursor: encodeCursor(cursor{Sort: "-created,id", Values: []string{"42"}})},
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "garbage cursor",
+			page:    models.Pagination{Cursor: "garbage"},
+			wantErr: ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := l.PageQuery(tt.page)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PageQuery() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if query != tt.want {
+				t.Errorf("PageQuery() query = %q, want %q", query, tt.want)
+			}
+			if len(args) != len(tt.wantArgs) || len(args) > 0 && !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("PageQuery() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	// pages are built on copies, the listing can still be counted
+	if query, args := l.CountQuery(); query != "SELECT COUNT(*) FROM users WHERE deleted_at IS NULL" || len(args) != 0 {
+		t.Errorf("CountQuery() = %q, %v", query, args)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	l := newTestListing(t, "id")
+	rows := []int{1, 2, 3}
+	keys := [][]string{{"1"}, {"2"}, {"3"}}
+	cursorAt := func(id string, backward bool) string {
+		return encodeCursor(cursor{Sort: "id", Values: []string{id}, Backward: backward})
+	}
+
+	tests := []struct {
+		name     string
+		page     models.Pagination
+		rows     []int
+		keys     [][]string
+		wantRows []int
+		wantNext string
+		wantPrev string
+	}{
+		{
+			name:     "no limit",
+			rows:     rows,
+			keys:     keys,
+			wantRows: []int{1, 2, 3},
+		},
+		{
+			name:     "first page with more",
+			page:     models.Pagination{Limit: 2},
+			rows:     rows,
+			keys:     keys,
+			wantRows: []int{1, 2},
+			wantNext: cursorAt("2", false),
+		},
+		{
+			name:     "last page by offset",
+			page:     models.Pagination{Limit: 2, Offset: 2},
+			rows:     rows[2:],
+			keys:     keys[2:],
+			wantRows: []int{3},
+			wantPrev: cursorAt("3", true),
+		},
+		{
+			name:     "middle page by cursor",
+			page:     models.Pagination{Limit: 1, Cursor: cursorAt("1", false)},
+			rows:     rows[1:],
+			keys:     keys[1:],
+			wantRows: []int{2},
+			wantNext: cursorAt("2", false),
+			wantPrev: cursorAt("2", true),
+		},
+		{
+			name:     "backward to the first page",
+			page:     models.Pagination{Limit: 2, Cursor: cursorAt("3", true)},
+			rows:     []int{2, 1},
+			keys:     [][]string{{"2"}, {"1"}},
+			wantRows: []int{1, 2},
+			wantNext: cursorAt("2", false),
+		},
+		{
+			name: "empty page",
+			page: models.Pagination{Limit: 2, Cursor: cursorAt("3", false)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, page := Paginate(l, tt.page, tt.rows, tt.keys)
+			if len(got) != len(tt.wantRows) || len(got) > 0 && !reflect.DeepEqual(got, tt.wantRows) {
+				t.Errorf("Paginate() rows = %v, want %v", got, tt.wantRows)
+			}
+			if page.NextCursor != tt.wantNext {
+				t.Errorf("Paginate() next cursor = %q, want %q", page.NextCursor, tt.wantNext)
+			}
+			if page.PrevCursor != tt.wantPrev {
+				t.Errorf("Paginate() prev cursor = %q, want %q", page.PrevCursor, tt.wantPrev)
+			}
+		})
+	}
+}
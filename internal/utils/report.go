@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kuromii5/time-tracker/internal/models"
+)
+
+// ReportDimensions maps every dimension a time report can be grouped by to its SQL expression.
+// Time buckets are taken from the start of a piece of a segment in the report time zone,
+// or in the worklog owner's time zone if there is none, see reportPieces.
+var ReportDimensions = map[string]string{
+	models.GroupByUser:  "w.user_id",
+	models.GroupByTask:  "w.task",
+	models.GroupByDay:   `to_char(` + reportLocalTime + `, 'YYYY-MM-DD')`,
+	models.GroupByWeek:  `to_char(` + reportLocalTime + `, 'IYYY-"W"IW')`,
+	models.GroupByMonth: `to_char(` + reportLocalTime + `, 'YYYY-MM')`,
+}
+
+// reportLocalTime is the start of a piece in the report time zone
+const reportLocalTime = `p.started_at AT TIME ZONE c.tz`
+
+// reportPieces clips every segment to the report range ($1, $2), open ones last until now, and splits it
+// at the midnights of the report time zone, whose argument is tz. Every piece falls into a single day,
+// week and month then, so segments crossing midnight are counted in each bucket they cover.
+func reportPieces(tz string) string {
+	return `
+		CROSS JOIN LATERAL (
+			SELECT GREATEST(s.started_at, $1) AS started_at,
+				LEAST(COALESCE(s.finished_at, NOW()), $2) AS finished_at,
+				COALESCE(NULLIF(` + tz + `::text, ''), u.timezone) AS tz
+		) c
+		CROSS JOIN LATERAL (
+			SELECT GREATEST(c.started_at, d AT TIME ZONE c.tz) AS started_at,
+				LEAST(c.finished_at, (d + INTERVAL '1 day') AT TIME ZONE c.tz) AS finished_at
+			FROM generate_series(date_trunc('day', c.started_at AT TIME ZONE c.tz), c.finished_at AT TIME ZONE c.tz, INTERVAL '1 day') d
+		) p`
+}
+
+// Helper function to build SQL query for the time report.
+// Time is summed per piece of a worklog segment clipped to the [From, To) range,
+// so running worklogs are counted up to now and paused time is not counted at all.
+// The selected columns are the requested dimensions in order, then the duration and the worklogs count.
+func BuildTimeReportQuery(filter models.ReportFilter) (string, []interface{}, error) {
+	var dims []string
+	for _, dim := range filter.GroupBy {
+		expr, ok := ReportDimensions[dim]
+		if !ok {
+			return "", nil, fmt.Errorf("unknown report dimension: %q", dim)
+		}
+		dims = append(dims, expr)
+	}
+
+	args := []interface{}{filter.From, filter.To}
+	argIndex := 3
+
+	tz := fmt.Sprintf("$%d", argIndex)
+	args = append(args, filter.Timezone)
+	argIndex++
+
+	var query strings.Builder
+	query.WriteString("SELECT ")
+	for _, dim := range dims {
+		query.WriteString(dim + ", ")
+	}
+	query.WriteString(`
+		COALESCE(SUM(p.finished_at - p.started_at), INTERVAL '0'),
+		COUNT(DISTINCT w.id)
+		FROM worklog_segments s
+		JOIN worklogs w ON w.id = s.worklog_id
+		JOIN users u ON u.id = w.user_id` + reportPieces(tz) + `
+		WHERE s.started_at < $2 AND COALESCE(s.finished_at, NOW()) > $1 AND p.started_at < p.finished_at`)
+	intFields := []struct {
+		condition string
+		value     int32
+	}{
+		{"w.user_id = $%d", filter.UserID},
+		{"w.project_id = $%d", filter.ProjectID},
+		{"w.project_id IN (SELECT id FROM projects WHERE client_id = $%d)", filter.ClientID},
+	}
+	for _, field := range intFields {
+		if field.value != 0 {
+			query.WriteString(" AND " + fmt.Sprintf(field.condition, argIndex))
+			args = append(args, field.value)
+			argIndex++
+		}
+	}
+	if filter.Task != "" {
+		query.WriteString(fmt.Sprintf(" AND w.task = $%d", argIndex))
+		args = append(args, filter.Task)
+		argIndex++
+	}
+
+	// group and order by the dimension columns' positions
+	if len(dims) > 0 {
+		positions := make([]string, len(dims))
+		for i := range dims {
+			positions[i] = strconv.Itoa(i + 1)
+		}
+		query.WriteString(" GROUP BY " + strings.Join(positions, ", "))
+		query.WriteString(" ORDER BY " + strings.Join(positions, ", "))
+	}
+
+	return query.String(), args, nil
+}
@@ -10,57 +10,100 @@ import (
 	"github.com/kuromii5/time-tracker/internal/models"
 )
 
-// Helper function to build SQL query for getting filtered and paginated users
-func BuildGetUsersQuery(filter models.FilterBy, settings models.Pagination) (string, []interface{}) {
-	var baseQuery strings.Builder
-	baseQuery.WriteString(`SELECT * FROM users WHERE 1=1`)
-	var args []interface{}
-	argIndex := 1
+// UserColumns lists users columns in the order they are scanned into models.User
+const UserColumns = "id, created_at, updated_at, COALESCE(passport_serie, ''), COALESCE(passport_number, ''), name, surname, COALESCE(patronymic, ''), address, worklog_policy, COALESCE(to_char(end_of_day, 'HH24:MI'), ''), timezone, role, COALESCE(manager_id, 0), deleted_at, erased_at, enrichment_status"
+
+// userSearchText is the text of a user that search results are ranked by
+const userSearchText = "name || ' ' || surname || ' ' || COALESCE(patronymic, '') || ' ' || address"
+
+// EscapeLike escapes the wildcards of LIKE patterns in s
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// userSortKeys are the keys users can be sorted by
+var userSortKeys = map[string]SortKey{
+	"id":         {Expr: "id", Type: "integer"},
+	"created_at": {Expr: "created_at", Type: "timestamptz"},
+	"updated_at": {Expr: "updated_at", Type: "timestamptz"},
+	"name":       {Expr: "name", Type: "text"},
+	"surname":    {Expr: "surname", Type: "text"},
+	"patronymic": {Expr: "COALESCE(patronymic, '')", Type: "text"},
+	"address":    {Expr: "address", Type: "text"},
+}
+
+// UsersListing builds the listing of users matching the filter, ordered by sort,
+// or by ID or search rank if sort is empty
+func UsersListing(filter models.FilterBy, sort string) (Listing, error) {
+	l := Listing{Columns: UserColumns, From: "FROM users"}
 
 	stringFields := map[string]string{
-		"surname":         filter.Surname,
-		"name":            filter.Name,
-		"patronymic":      filter.Patronymic,
-		"address":         filter.Address,
-		"passport_serie":  filter.PassportSerie,
-		"passport_number": filter.PassportNumber,
+		"surname":    filter.Surname,
+		"name":       filter.Name,
+		"patronymic": filter.Patronymic,
+		"address":    filter.Address,
+		// passports are encrypted, they are compared by blind indexes, see repo.Users
+		"passport_serie_idx":  filter.PassportSerie,
+		"passport_number_idx": filter.PassportNumber,
 	}
 	for field, value := range stringFields {
 		if value != "" {
-			baseQuery.WriteString(fmt.Sprintf(" AND %s = $%d", field, argIndex))
-			args = append(args, value)
-			argIndex++
+			l.Where(fmt.Sprintf("%s = %s", field, l.Arg(value)))
 		}
 	}
 
 	// filter user creation time
 	if !filter.CreatedAfter.IsZero() {
-		baseQuery.WriteString(fmt.Sprintf(" AND created_at > $%d", argIndex))
-		args = append(args, filter.CreatedAfter)
-		argIndex++
+		l.Where("created_at > " + l.Arg(filter.CreatedAfter))
 	}
 	if !filter.CreatedBefore.IsZero() {
-		baseQuery.WriteString(fmt.Sprintf(" AND created_at < $%d", argIndex))
-		args = append(args, filter.CreatedBefore)
-		argIndex++
+		l.Where("created_at < " + l.Arg(filter.CreatedBefore))
 	}
 
-	// pagination
-	if settings.Limit > 0 {
-		baseQuery.WriteString(fmt.Sprintf(" LIMIT $%d", argIndex))
-		args = append(args, settings.Limit)
-		argIndex++
+	if !filter.IncludeDeleted {
+		l.Where("deleted_at IS NULL")
 	}
-	if settings.Offset > 0 {
-		baseQuery.WriteString(fmt.Sprintf(" OFFSET $%d", argIndex))
-		args = append(args, settings.Offset)
-		argIndex++
+
+	if filter.TeamOf != 0 {
+		arg := l.Arg(filter.TeamOf)
+		l.Where(fmt.Sprintf("(id = %s OR manager_id = %s)", arg, arg))
 	}
 
-	return baseQuery.String(), args
+	l.Keys = []SortKey{{Name: "id", Expr: "id", Type: "integer"}}
+
+	// search for substrings of the names and address, served by trigram indexes
+	if filter.Query != "" {
+		arg := l.Arg("%" + EscapeLike(filter.Query) + "%")
+		l.Where(fmt.Sprintf("(name ILIKE %s OR surname ILIKE %s OR patronymic ILIKE %s OR address ILIKE %s)", arg, arg, arg, arg))
+	}
+
+	// names starting with the query come first, then the most similar ones, unless asked otherwise
+	if filter.Query != "" && sort == "" {
+		prefix, query := l.Arg(EscapeLike(filter.Query)+"%"), l.Arg(filter.Query)
+		l.Keys = []SortKey{
+			{
+				Name: "prefix",
+				Expr: fmt.Sprintf("(name ILIKE %s OR surname ILIKE %s OR COALESCE(patronymic, '') ILIKE %s)", prefix, prefix, prefix),
+				Type: "boolean",
+				Desc: true,
+			},
+			{Name: "similarity", Expr: fmt.Sprintf("word_similarity(%s, %s)", query, userSearchText), Type: "real", Desc: true},
+			{Name: "id", Expr: "id", Type: "integer"},
+		}
+	}
+
+	if err := l.OrderBy(sort, userSortKeys, SortKey{Name: "id", Expr: "id", Type: "integer"}); err != nil {
+		return Listing{}, err
+	}
+
+	return l, nil
 }
 
-func BuildUpdateUserQuery(user models.User) (string, []interface{}) {
+// BuildUpdateUserQuery builds the update of the user's non-empty fields.
+// The passport is expected to be encrypted, and passportIndex holds its blind indexes.
+func BuildUpdateUserQuery(user models.User, passportIndex models.Passport) (string, []interface{}) {
 	var statements strings.Builder
 	var args []interface{}
 	argIndex := 1
@@ -78,9 +121,11 @@ func BuildUpdateUserQuery(user models.User) (string, []interface{}) {
 	// Add fields to the query if they are not empty
 	if user.Passport.Serie != "" {
 		addField("passport_serie", user.Passport.Serie)
+		addField("passport_serie_idx", passportIndex.Serie)
 	}
 	if user.Passport.Number != "" {
 		addField("passport_number", user.Passport.Number)
+		addField("passport_number_idx", passportIndex.Number)
 	}
 	if user.People.Name != "" {
 		addField("name", user.People.Name)
@@ -94,6 +139,27 @@ func BuildUpdateUserQuery(user models.User) (string, []interface{}) {
 	if user.People.Address != "" {
 		addField("address", user.People.Address)
 	}
+	if user.WorklogPolicy != "" {
+		addField("worklog_policy", user.WorklogPolicy)
+	}
+	if user.EndOfDay != "" {
+		addField("end_of_day", user.EndOfDay)
+	}
+	if user.ClearEndOfDay {
+		addField("end_of_day", nil)
+	}
+	if user.Timezone != "" {
+		addField("timezone", user.Timezone)
+	}
+	if user.Role != "" {
+		addField("role", user.Role)
+	}
+	if user.ManagerID != 0 {
+		addField("manager_id", user.ManagerID)
+	}
+	if user.ClearManager {
+		addField("manager_id", nil)
+	}
 
 	// Add the updated_at field
 	if statements.Len() > 0 {
@@ -104,7 +170,8 @@ func BuildUpdateUserQuery(user models.User) (string, []interface{}) {
 	// Append the user ID to the arguments
 	args = append(args, user.ID)
 
-	query := fmt.Sprintf("UPDATE users SET %s WHERE id = $%d", &statements, argIndex)
+	// soft-deleted users have to be restored before they can be changed, erased users can't be changed
+	query := fmt.Sprintf("UPDATE users SET %s WHERE id = $%d AND deleted_at IS NULL AND erased_at IS NULL", &statements, argIndex)
 
 	return query, args
 }
@@ -124,26 +191,83 @@ func ParseQueryParamInt(r *http.Request, key string) int {
 	return value
 }
 
-// Helper function to parse query parameters as time
-func ParseQueryParamTime(r *http.Request, key string) time.Time {
+// ParseQueryParamID parses a query parameter as a positive ID.
+// It returns 0 if the parameter is not set.
+func ParseQueryParamID(r *http.Request, key string) (int32, error) {
+	valueStr := r.URL.Query().Get(key)
+	if valueStr == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseInt(valueStr, 10, 32)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid %s %q, expected a positive integer", key, valueStr)
+	}
+
+	return int32(value), nil
+}
+
+// ParseQueryParamPagination parses the limit, offset, cursor, with_total and sort query parameters
+func ParseQueryParamPagination(r *http.Request) models.Pagination {
+	return models.Pagination{
+		Limit:     ParseQueryParamInt(r, "limit"),
+		Offset:    ParseQueryParamInt(r, "offset"),
+		Cursor:    r.URL.Query().Get("cursor"),
+		WithTotal: r.URL.Query().Get("with_total") == "true",
+		Sort:      r.URL.Query().Get("sort"),
+	}
+}
+
+// ParseQueryParamTimeIn parses a query parameter as RFC3339 time,
+// or as a YYYY-MM-DD date meaning midnight in loc.
+// It returns the zero time if the parameter is not set.
+func ParseQueryParamTimeIn(r *http.Request, key string, loc *time.Location) (time.Time, error) {
 	valueStr := r.URL.Query().Get(key)
 	if valueStr == "" {
-		return time.Time{}
+		return time.Time{}, nil
 	}
 
 	value, err := time.Parse(time.RFC3339, valueStr)
+	if err == nil {
+		return value, nil
+	}
+
+	value, err = time.ParseInLocation(time.DateOnly, valueStr, loc)
 	if err != nil {
-		return time.Time{}
+		return time.Time{}, fmt.Errorf("invalid %s %q, expected a YYYY-MM-DD date or RFC 3339 time", key, valueStr)
 	}
 
-	return value
+	return value, nil
+}
+
+// ParseQueryParamLocation parses a query parameter as an IANA time zone name.
+// It returns nil if the parameter is not set.
+func ParseQueryParamLocation(r *http.Request, key string) (*time.Location, error) {
+	valueStr := r.URL.Query().Get(key)
+	if valueStr == "" {
+		return nil, nil
+	}
+
+	loc, err := time.LoadLocation(valueStr)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone: %q", valueStr)
+	}
+
+	return loc, nil
+}
+
+// FormatDuration formats duration as hours and minutes, e.g. "12h 5m"
+func FormatDuration(duration time.Duration) string {
+	hours := int(duration.Hours())
+	minutes := int(duration.Minutes()) % 60
+	return fmt.Sprintf("%dh %dm", hours, minutes)
 }
 
 // ParsePassportData parses JSON containing passport serie and number into PassportData struct
 func ParsePassportData(data string) (models.Passport, error) {
 	// Split passportNumber into serie and number
 	parts := strings.Fields(data)
-	if len(parts) != 2 && len(parts[0]) != 4 && len(parts[1]) != 6 {
+	if len(parts) != 2 || len(parts[0]) != 4 || len(parts[1]) != 6 {
 		return models.Passport{}, fmt.Errorf("invalid passportNumber format, expected '**** ******'")
 	}
 
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseQueryParamTimeIn(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"not set", "", time.Time{}, false},
+		{"date", "2024-06-01", time.Date(2024, 6, 1, 0, 0, 0, 0, moscow), false},
+		{"rfc 3339", "2024-06-01T10:00:00Z", time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC), false},
+		{"invalid month", "2024-13-01", time.Time{}, true},
+		{"garbage", "yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/?from="+url.QueryEscape(tt.value), nil)
+
+			got, err := ParseQueryParamTimeIn(r, "from", moscow)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseQueryParamTimeIn() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseQueryParamTimeIn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryParamID(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int32
+		wantErr bool
+	}{
+		{"not set", "", 0, false},
+		{"id", "42", 42, false},
+		{"zero", "0", 0, true},
+		{"negative", "-1", 0, true},
+		{"too large", "2147483648", 0, true},
+		{"garbage", "abc", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/?user_id="+url.QueryEscape(tt.value), nil)
+
+			got, err := ParseQueryParamID(r, "user_id")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseQueryParamID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseQueryParamID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
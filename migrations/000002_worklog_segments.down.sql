@@ -0,0 +1,3 @@
+ALTER TABLE worklogs ADD COLUMN IF NOT EXISTS duration INTERVAL GENERATED ALWAYS AS (finished_at - started_at) STORED;
+
+DROP TABLE IF EXISTS worklog_segments;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS worklog_segments (
+    id SERIAL PRIMARY KEY,
+    worklog_id INT NOT NULL,
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP,
+    FOREIGN KEY (worklog_id) REFERENCES worklogs (id) ON DELETE CASCADE
+);
+CREATE INDEX idx_worklog_segments_worklog_id ON worklog_segments (worklog_id);
+-- a worklog can have at most one active (open) segment at a time
+CREATE UNIQUE INDEX idx_worklog_segments_open ON worklog_segments (worklog_id) WHERE finished_at IS NULL;
+
+-- every existing worklog becomes a single segment
+INSERT INTO worklog_segments (worklog_id, started_at, finished_at)
+SELECT id, started_at, finished_at FROM worklogs;
+
+-- duration is now the sum of active segments and is computed on read
+ALTER TABLE worklogs DROP COLUMN IF EXISTS duration;
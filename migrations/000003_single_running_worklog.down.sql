@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_worklogs_user_running;
+
+ALTER TABLE users DROP COLUMN IF EXISTS worklog_policy;
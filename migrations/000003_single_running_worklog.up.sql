@@ -0,0 +1,27 @@
+-- what happens when a user starts a worklog while another one is still open:
+-- 'reject' refuses to start, 'switch' finishes the open one first
+ALTER TABLE users ADD COLUMN IF NOT EXISTS worklog_policy VARCHAR(16) NOT NULL DEFAULT 'reject'
+    CHECK (worklog_policy IN ('reject', 'switch'));
+
+-- finish every open worklog except the latest one of each user, when the user's next worklog started,
+-- so that forgotten worklogs don't get all the time until now; never before the worklog started
+WITH stale AS (
+    SELECT id, GREATEST(next_started_at, started_at) AS stopped_at FROM (
+        SELECT id, started_at, finished_at,
+            LEAD(started_at) OVER (PARTITION BY user_id ORDER BY started_at, id) AS next_started_at,
+            ROW_NUMBER() OVER (PARTITION BY user_id, finished_at IS NULL ORDER BY started_at DESC, id DESC) AS rn
+        FROM worklogs
+    ) user_worklogs
+    WHERE finished_at IS NULL AND rn > 1
+), closed_segments AS (
+    UPDATE worklog_segments s
+    SET finished_at = GREATEST(stale.stopped_at, s.started_at)
+    FROM stale
+    WHERE s.finished_at IS NULL AND s.worklog_id = stale.id
+)
+UPDATE worklogs w
+SET finished_at = stale.stopped_at
+FROM stale
+WHERE w.id = stale.id;
+
+CREATE UNIQUE INDEX idx_worklogs_user_running ON worklogs (user_id) WHERE finished_at IS NULL;
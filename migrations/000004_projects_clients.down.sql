@@ -0,0 +1,4 @@
+ALTER TABLE worklogs DROP COLUMN IF EXISTS project_id;
+
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS clients;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS clients (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    name VARCHAR(255) NOT NULL,
+    UNIQUE (name)
+);
+
+CREATE TABLE IF NOT EXISTS projects (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    client_id INT,
+    name VARCHAR(255) NOT NULL,
+    UNIQUE (name),
+    FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE SET NULL
+);
+CREATE INDEX idx_projects_client_id ON projects (client_id);
+
+ALTER TABLE worklogs ADD COLUMN IF NOT EXISTS project_id INT REFERENCES projects (id) ON DELETE SET NULL;
+CREATE INDEX idx_worklogs_project_id ON worklogs (project_id);
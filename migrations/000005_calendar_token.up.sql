@@ -0,0 +1,2 @@
+-- sha256 of the secret token of the user's calendar feed URL
+ALTER TABLE users ADD COLUMN IF NOT EXISTS calendar_token_hash CHAR(64) UNIQUE;
@@ -0,0 +1,3 @@
+ALTER TABLE worklogs DROP COLUMN IF EXISTS auto_stopped;
+
+ALTER TABLE users DROP COLUMN IF EXISTS end_of_day;
@@ -0,0 +1,6 @@
+-- time of day after which forgotten worklogs of the user are stopped
+ALTER TABLE users ADD COLUMN IF NOT EXISTS end_of_day TIME;
+
+-- worklogs stopped by the reaper, to be reviewed by their owners
+ALTER TABLE worklogs ADD COLUMN IF NOT EXISTS auto_stopped BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX idx_worklogs_auto_stopped ON worklogs (user_id) WHERE auto_stopped;
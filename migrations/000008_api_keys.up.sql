@@ -0,0 +1,13 @@
+-- API keys authenticate scripts and integrations. Only the sha256 of a key is stored,
+-- the prefix is kept in clear text to tell keys apart.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    admin BOOLEAN NOT NULL DEFAULT FALSE,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_manager_id;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS manager_id,
+    DROP COLUMN IF EXISTS role;
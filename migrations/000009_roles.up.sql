@@ -0,0 +1,7 @@
+-- Roles decide what a user may do, a manager's team is the users they manage
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT 'employee'
+        CHECK (role IN ('admin', 'manager', 'employee')),
+    ADD COLUMN IF NOT EXISTS manager_id INT REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_manager_id ON users(manager_id);
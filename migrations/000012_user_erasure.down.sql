@@ -0,0 +1,13 @@
+-- erased users have no passport to put back, and deleting them would lose their worklogs
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users WHERE erased_at IS NOT NULL) THEN
+        RAISE EXCEPTION 'erased users exist, they can''t be migrated down';
+    END IF;
+END
+$$;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS erased_at,
+    ALTER COLUMN passport_serie SET NOT NULL,
+    ALTER COLUMN passport_number SET NOT NULL;
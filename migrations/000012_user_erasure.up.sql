@@ -0,0 +1,5 @@
+-- Erased users are anonymized, their passports are cleared so they don't take up the unique pair
+ALTER TABLE users
+    ALTER COLUMN passport_serie DROP NOT NULL,
+    ALTER COLUMN passport_number DROP NOT NULL,
+    ADD COLUMN IF NOT EXISTS erased_at TIMESTAMPTZ;
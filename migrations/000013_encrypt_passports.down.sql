@@ -0,0 +1,17 @@
+-- Passports have to be decrypted with `passport decrypt` first,
+-- ciphertexts don't fit the narrower columns and fail the migration
+DROP INDEX IF EXISTS idx_users_passport_number_idx;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_passport_idx_key;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS passport_serie_idx,
+    DROP COLUMN IF EXISTS passport_number_idx,
+    ALTER COLUMN passport_serie TYPE CHAR(4),
+    ALTER COLUMN passport_number TYPE CHAR(6);
+
+ALTER TABLE users ADD CONSTRAINT users_passport_serie_passport_number_key UNIQUE (passport_serie, passport_number);
+
+CREATE INDEX IF NOT EXISTS idx_users_passport_serie ON users (passport_serie);
+CREATE INDEX IF NOT EXISTS idx_users_passport_number ON users (passport_number);
+CREATE INDEX IF NOT EXISTS idx_users_passport ON users (passport_serie, passport_number);
@@ -0,0 +1,17 @@
+-- Passports are encrypted by the application, blind indexes (keyed hashes) of the plain values
+-- keep them unique and searchable. Existing rows are encrypted by `passport reencrypt`.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_passport_serie_passport_number_key;
+
+DROP INDEX IF EXISTS idx_users_passport_serie;
+DROP INDEX IF EXISTS idx_users_passport_number;
+DROP INDEX IF EXISTS idx_users_passport;
+
+ALTER TABLE users
+    ALTER COLUMN passport_serie TYPE TEXT,
+    ALTER COLUMN passport_number TYPE TEXT,
+    ADD COLUMN IF NOT EXISTS passport_serie_idx CHAR(64),
+    ADD COLUMN IF NOT EXISTS passport_number_idx CHAR(64);
+
+ALTER TABLE users ADD CONSTRAINT users_passport_idx_key UNIQUE (passport_serie_idx, passport_number_idx);
+
+CREATE INDEX IF NOT EXISTS idx_users_passport_number_idx ON users(passport_number_idx);
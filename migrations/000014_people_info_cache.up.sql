@@ -0,0 +1,11 @@
+-- Answers of the people info API shared by all instances, keyed by the blind indexes of the passport.
+-- A NULL people means the API doesn't know anybody with the passport.
+CREATE TABLE IF NOT EXISTS people_info_cache (
+    passport_serie_idx CHAR(64) NOT NULL,
+    passport_number_idx CHAR(64) NOT NULL,
+    people JSONB,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (passport_serie_idx, passport_number_idx)
+);
+
+CREATE INDEX IF NOT EXISTS idx_people_info_cache_expires_at ON people_info_cache(expires_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS enrichment_jobs;
+
+ALTER TABLE users DROP COLUMN IF EXISTS enrichment_status;
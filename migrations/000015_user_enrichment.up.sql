@@ -0,0 +1,23 @@
+-- Users are created right away and enriched with people info in the background
+ALTER TABLE users ADD COLUMN IF NOT EXISTS enrichment_status TEXT NOT NULL DEFAULT 'enriched'
+    CHECK (enrichment_status IN ('pending_enrichment', 'enriched', 'enrichment_failed'));
+
+-- Queue of users to enrich. Failed jobs are retried at run_at until they run out of attempts
+-- and are dead-lettered. Running jobs whose lease expired are picked up again.
+CREATE TABLE IF NOT EXISTS enrichment_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'done', 'dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMPTZ,
+    last_error TEXT,
+    callback_url TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_runnable ON enrichment_jobs(run_at) WHERE status IN ('queued', 'running');
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_user_id ON enrichment_jobs(user_id);
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_status ON enrichment_jobs(status, id);
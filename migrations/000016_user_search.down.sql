@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_users_name_trgm;
+DROP INDEX IF EXISTS idx_users_surname_trgm;
+DROP INDEX IF EXISTS idx_users_patronymic_trgm;
+DROP INDEX IF EXISTS idx_users_address_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_users_name ON users (name);
+CREATE INDEX IF NOT EXISTS idx_users_surname ON users (surname);
+CREATE INDEX IF NOT EXISTS idx_users_patronymic ON users (patronymic);
+CREATE INDEX IF NOT EXISTS idx_users_address ON users (address);
+
+-- the extension is kept, other databases objects may depend on it
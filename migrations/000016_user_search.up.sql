@@ -0,0 +1,13 @@
+-- Users are searched by substrings of their names and address, which B-tree indexes can't serve.
+-- Trigram indexes serve ILIKE '%...%' as well as the exact matches of the filters.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+DROP INDEX IF EXISTS idx_users_name;
+DROP INDEX IF EXISTS idx_users_surname;
+DROP INDEX IF EXISTS idx_users_patronymic;
+DROP INDEX IF EXISTS idx_users_address;
+
+CREATE INDEX IF NOT EXISTS idx_users_name_trgm ON users USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_surname_trgm ON users USING GIN (surname gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_patronymic_trgm ON users USING GIN (patronymic gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_address_trgm ON users USING GIN (address gin_trgm_ops);
@@ -0,0 +1 @@
+-- scrubbed passports can't be put back into the audit log
@@ -0,0 +1,7 @@
+-- User snapshots recorded before passports were encrypted hold them in plain text,
+-- and snapshots after that hold ciphertexts that outlive key rotations. Audit events
+-- don't need passports at all, so they are removed from every user snapshot.
+UPDATE audit_events
+SET before = before - '{passport_serie,passport_number,passport_serie_idx,passport_number_idx}'::text[],
+    after = after - '{passport_serie,passport_number,passport_serie_idx,passport_number_idx}'::text[]
+WHERE entity = 'user';
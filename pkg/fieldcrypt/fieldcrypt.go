@@ -0,0 +1,169 @@
+// Package fieldcrypt encrypts single database fields with AES-256-GCM and computes
+// deterministic blind indexes, so that encrypted fields can still be looked up by equality.
+//
+// Ciphertexts look like "enc:<key id>:<base64 of nonce and sealed data>". The key ID
+// lets values encrypted with retired keys be decrypted and re-encrypted after rotation.
+// The field name is authenticated with the data, so ciphertexts can't be moved between fields.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize is the size of encryption and index keys
+const KeySize = 32
+
+const prefix = "enc:"
+
+var (
+	ErrUnknownKey = errors.New("unknown encryption key")
+	ErrMalformed  = errors.New("malformed ciphertext")
+)
+
+// Keyring holds the keys fields are encrypted and indexed with.
+// New values are encrypted with the primary key, the others are only used for decryption.
+type Keyring struct {
+	keys    map[string]cipher.AEAD
+	primary string
+	index   []byte
+}
+
+// NewKeyring creates a keyring of the keys by their IDs. The index key must never change,
+// or all blind indexes have to be recomputed.
+func NewKeyring(keys map[string][]byte, primary string, indexKey []byte) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("%w: primary key %q", ErrUnknownKey, primary)
+	}
+	if len(indexKey) != KeySize {
+		return nil, fmt.Errorf("index key must be %d bytes", KeySize)
+	}
+
+	k := &Keyring{keys: make(map[string]cipher.AEAD, len(keys)), primary: primary, index: indexKey}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("key %q must be %d bytes", id, KeySize)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		if k.keys[id], err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+// ParseKeys parses a comma-separated list of "<id>:<base64 key>" pairs
+func ParseKeys(s string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("key %q should be <id>:<base64 key>", pair)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+
+	return keys, nil
+}
+
+// IsEncrypted reports whether s is a ciphertext rather than a plain value
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
+
+// Encrypt encrypts the value of the field with the primary key
+func (k *Keyring) Encrypt(field, value string) (string, error) {
+	aead := k.keys[k.primary]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(field))
+
+	return prefix + k.primary + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a ciphertext of the field with whichever key it was encrypted with
+func (k *Keyring) Decrypt(field, ciphertext string) (string, error) {
+	id, sealed, err := k.split(ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	aead := k.keys[id]
+	if len(sealed) < aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	value, err := aead.Open(nil, nonce, sealed, []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+
+	return string(value), nil
+}
+
+// NeedsRotation reports whether the ciphertext was not encrypted with the primary key
+func (k *Keyring) NeedsRotation(ciphertext string) bool {
+	id, _, err := k.split(ciphertext)
+	return err != nil || id != k.primary
+}
+
+// BlindIndex returns a deterministic keyed hash of the value of the field as 64 hex digits.
+// Equal values of the same field have equal indexes.
+func (k *Keyring) BlindIndex(field, value string) string {
+	mac := hmac.New(sha256.New, k.index)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (k *Keyring) split(ciphertext string) (string, []byte, error) {
+	rest, ok := strings.CutPrefix(ciphertext, prefix)
+	if !ok {
+		return "", nil, ErrMalformed
+	}
+	id, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", nil, ErrMalformed
+	}
+	if _, ok := k.keys[id]; !ok {
+		return "", nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+
+	return id, sealed, nil
+}
@@ -0,0 +1,232 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func newTestKeyring(t *testing.T, keys map[string][]byte, primary string) *Keyring {
+	t.Helper()
+
+	k, err := NewKeyring(keys, primary, testKey(0xff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestRoundTrip(t *testing.T) {
+	k := newTestKeyring(t, map[string][]byte{"1": testKey(1)}, "1")
+
+	for _, value := range []string{"", "1234", "567890", "паспорт"} {
+		ciphertext, err := k.Encrypt("passport_number", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsEncrypted(ciphertext) || !strings.HasPrefix(ciphertext, "enc:1:") {
+			t.Errorf("Encrypt(%q) = %q, want an enc:1: ciphertext", value, ciphertext)
+		}
+		if strings.Contains(ciphertext, value) && value != "" {
+			t.Errorf("Encrypt(%q) = %q leaks the value", value, ciphertext)
+		}
+
+		got, err := k.Decrypt("passport_number", ciphertext)
+		if err != nil {
+			t.Fatalf("Decrypt(%q) error = %v", ciphertext, err)
+		}
+		if got != value {
+			t.Errorf("Decrypt(Encrypt(%q)) = %q", value, got)
+		}
+	}
+}
+
+func TestEncryptIsRandomized(t *testing.T) {
+	k := newTestKeyring(t, map[string][]byte{"1": testKey(1)}, "1")
+
+	a, _ := k.Encrypt("passport_serie", "1234")
+	b, _ := k.Encrypt("passport_serie", "1234")
+	if a == b {
+		t.Errorf("equal values encrypt to equal ciphertexts %q", a)
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	k := newTestKeyring(t, map[string][]byte{"1": testKey(1)}, "1")
+	other := newTestKeyring(t, map[string][]byte{"2": testKey(2)}, "2")
+
+	valid, err := k.Encrypt("passport_serie", "1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromOther, err := other.Encrypt("passport_serie", "1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, encoded, _ := strings.Cut(strings.TrimPrefix(valid, prefix), ":")
+	sealed, _ := base64.RawStdEncoding.DecodeString(encoded)
+	sealed[len(sealed)-1] ^= 1
+	tampered := prefix + id + ":" + base64.RawStdEncoding.EncodeToString(sealed)
+
+	tests := []struct {
+		name       string
+		field      string
+		ciphertext string
+		wantErr    error
+	}{
+		{"plain value", "passport_serie", "1234", ErrMalformed},
+		{"no key id", "passport_serie", "enc:abc", ErrMalformed},
+		{"unknown key", "passport_serie", fromOther, ErrUnknownKey},
+		{"invalid base64", "passport_serie", "enc:1:!!!", ErrMalformed},
+		{"too short", "passport_serie", "enc:1:AAAA", ErrMalformed},
+		{"tampered", "passport_serie", tampered, ErrMalformed},
+		{"other field", "passport_number", valid, ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := k.Decrypt(tt.field, tt.ciphertext)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decrypt() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	old := newTestKeyring(t, map[string][]byte{"1": testKey(1)}, "1")
+	rotated := newTestKeyring(t, map[string][]byte{"1": testKey(1), "2": testKey(2)}, "2")
+
+	oldCiphertext, err := old.Encrypt("passport_serie", "1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newCiphertext, err := rotated.Encrypt("passport_serie", "1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		ciphertext    string
+		needsRotation bool
+	}{
+		{"retired key", oldCiphertext, true},
+		{"primary key", newCiphertext, false},
+		{"plain value", "1234", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rotated.NeedsRotation(tt.ciphertext); got != tt.needsRotation {
+				t.Errorf("NeedsRotation() = %v, want %v", got, tt.needsRotation)
+			}
+			if !IsEncrypted(tt.ciphertext) {
+				return
+			}
+			if got, err := rotated.Decrypt("passport_serie", tt.ciphertext); err != nil || got != "1234" {
+				t.Errorf("Decrypt() = %q, %v, want %q", got, err, "1234")
+			}
+		})
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	k := newTestKeyring(t, map[string][]byte{"1": testKey(1)}, "1")
+	rotated := newTestKeyring(t, map[string][]byte{"1": testKey(1), "2": testKey(2)}, "2")
+	otherIndex, err := NewKeyring(map[string][]byte{"1": testKey(1)}, "1", testKey(0xee))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index := k.BlindIndex("passport_serie", "1234")
+	if len(index) != 64 {
+		t.Errorf("BlindIndex() = %q, want 64 hex digits", index)
+	}
+
+	tests := []struct {
+		name  string
+		got   string
+		equal bool
+	}{
+		{"same value", k.BlindIndex("passport_serie", "1234"), true},
+		{"after rotation", rotated.BlindIndex("passport_serie", "1234"), true},
+		{"other value", k.BlindIndex("passport_serie", "1235"), false},
+		{"other field", k.BlindIndex("passport_number", "1234"), false},
+		{"field and value not concatenated", k.BlindIndex("passport_serie1", "234"), false},
+		{"other index key", otherIndex.BlindIndex("passport_serie", "1234"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.got == index) != tt.equal {
+				t.Errorf("BlindIndex() = %q, equal to %q should be %v", tt.got, index, tt.equal)
+			}
+		})
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     map[string][]byte
+		primary  string
+		indexKey []byte
+		wantErr  bool
+	}{
+		{"valid", map[string][]byte{"1": testKey(1)}, "1", testKey(0xff), false},
+		{"unknown primary", map[string][]byte{"1": testKey(1)}, "2", testKey(0xff), true},
+		{"short key", map[string][]byte{"1": testKey(1)[:16]}, "1", testKey(0xff), true},
+		{"short index key", map[string][]byte{"1": testKey(1)}, "1", testKey(0xff)[:16], true},
+		{"colon in key id", map[string][]byte{"1": testKey(1), "a:b": testKey(2)}, "1", testKey(0xff), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(tt.keys, tt.primary, tt.indexKey)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewKeyring() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(testKey(1))
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"one", "1:" + key, []string{"1"}, false},
+		{"several with spaces", "1:" + key + ", 2:" + key + ",", []string{"1", "2"}, false},
+		{"no id", key, nil, true},
+		{"invalid base64", "1:???", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := ParseKeys(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(keys) != len(tt.want) {
+				t.Fatalf("ParseKeys() = %d keys, want %d", len(keys), len(tt.want))
+			}
+			for _, id := range tt.want {
+				if !bytes.Equal(keys[id], testKey(1)) {
+					t.Errorf("ParseKeys()[%q] = %x", id, keys[id])
+				}
+			}
+		})
+	}
+}
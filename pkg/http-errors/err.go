@@ -26,6 +26,36 @@ func ErrInternal(err error) render.Renderer {
 	}
 }
 
+// ErrUnauthorized generates a response for requests without valid credentials
+func ErrUnauthorized(err error) render.Renderer {
+	return &ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusUnauthorized,
+		StatusText:     "Unauthorized.",
+		ErrorText:      err.Error(),
+	}
+}
+
+// ErrForbidden generates a response for requests the principal is not allowed to make
+func ErrForbidden(err error) render.Renderer {
+	return &ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusForbidden,
+		StatusText:     "Forbidden.",
+		ErrorText:      err.Error(),
+	}
+}
+
+// ErrNotFound generates a response for missing resources
+func ErrNotFound(err error) render.Renderer {
+	return &ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusNotFound,
+		StatusText:     "Resource not found.",
+		ErrorText:      err.Error(),
+	}
+}
+
 func ErrConflict(err error) render.Renderer {
 	return &ErrResponse{
 		Err:            err,
@@ -35,12 +65,24 @@ func ErrConflict(err error) render.Renderer {
 	}
 }
 
+// ErrConflictDetails generates a conflict response that explains what the request conflicts with
+func ErrConflictDetails(err error, details interface{}) render.Renderer {
+	return &ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusConflict,
+		StatusText:     "Conflict.",
+		ErrorText:      err.Error(),
+		Details:        details,
+	}
+}
+
 // ErrResponse is a generic error response renderer
 type ErrResponse struct {
-	Err            error  `json:"-"`               // low-level runtime error
-	HTTPStatusCode int    `json:"-"`               // http response status code
-	StatusText     string `json:"status"`          // user-level status message
-	ErrorText      string `json:"error,omitempty"` // application-level error message, for debugging
+	Err            error       `json:"-"`                 // low-level runtime error
+	HTTPStatusCode int         `json:"-"`                 // http response status code
+	StatusText     string      `json:"status"`            // user-level status message
+	ErrorText      string      `json:"error,omitempty"`   // application-level error message, for debugging
+	Details        interface{} `json:"details,omitempty"` // structured error details, if any
 }
 
 // Render sets the status code for ErrResponse
@@ -0,0 +1,223 @@
+// Package jwt verifies compact JWS tokens (RFC 7519) signed with HS256 or RS256.
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Supported signing algorithms
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+var (
+	ErrMalformed        = errors.New("malformed token")
+	ErrUnsupportedAlg   = errors.New("unsupported signing algorithm")
+	ErrInvalidSignature = errors.New("invalid token signature")
+	ErrExpired          = errors.New("token is expired")
+	ErrMissingExpiry    = errors.New("token has no expiry")
+	ErrNotYetValid      = errors.New("token is not valid yet")
+	ErrInvalidIssuer    = errors.New("invalid token issuer")
+	ErrInvalidAudience  = errors.New("invalid token audience")
+)
+
+// Config holds the keys and expectations of a Verifier.
+// Only the algorithms whose keys are set are accepted.
+type Config struct {
+	HMACSecret   []byte
+	RSAPublicKey *rsa.PublicKey
+	Issuer       string        // required iss, if set
+	Audience     string        // required aud, if set
+	Leeway       time.Duration // allowed clock skew for exp and nbf
+}
+
+// Verifier checks token signatures and registered claims
+type Verifier struct {
+	cfg Config
+	now func() time.Time
+}
+
+// NumericDate is a JWT timestamp in seconds since the epoch. It is any JSON number, e.g. 1700000000.5.
+type NumericDate float64
+
+// Time converts d to time, down to microseconds
+func (d NumericDate) Time() time.Time {
+	return time.UnixMicro(int64(math.Round(float64(d) * 1e6)))
+}
+
+// Audience is the aud claim, which may be either a string or an array of strings
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// RegisteredClaims are the claims defined by RFC 7519 that the verifier checks
+type RegisteredClaims struct {
+	Subject   string       `json:"sub"`
+	Issuer    string       `json:"iss"`
+	Audience  Audience     `json:"aud"`
+	ExpiresAt *NumericDate `json:"exp"`
+	NotBefore *NumericDate `json:"nbf"`
+	IssuedAt  *NumericDate `json:"iat"`
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// NewVerifier creates a verifier. At least one key must be configured.
+func NewVerifier(cfg Config) (*Verifier, error) {
+	if len(cfg.HMACSecret) == 0 && cfg.RSAPublicKey == nil {
+		return nil, errors.New("jwt: no verification key configured")
+	}
+
+	return &Verifier{cfg: cfg, now: time.Now}, nil
+}
+
+// ParseRSAPublicKeyPEM parses a PEM encoded PKIX or PKCS #1 RSA public key
+func ParseRSAPublicKeyPEM(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("jwt: no PEM block found")
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: parse public key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("jwt: public key is not an RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+// Verify checks the token and decodes its payload into the registered claims and, if not nil, into claims
+func (v *Verifier) Verify(token string, claims interface{}) (RegisteredClaims, error) {
+	var registered RegisteredClaims
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return registered, ErrMalformed
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return registered, ErrMalformed
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return registered, ErrMalformed
+	}
+	if err := v.verifySignature(h.Alg, parts[0]+"."+parts[1], signature); err != nil {
+		return registered, err
+	}
+
+	if err := decodeSegment(parts[1], &registered); err != nil {
+		return registered, ErrMalformed
+	}
+	if err := v.validate(registered); err != nil {
+		return registered, err
+	}
+	if claims != nil {
+		if err := decodeSegment(parts[1], claims); err != nil {
+			return registered, ErrMalformed
+		}
+	}
+
+	return registered, nil
+}
+
+// verifySignature only accepts algorithms with a configured key,
+// so an RS256 public key can never be used as an HS256 secret
+func (v *Verifier) verifySignature(alg, signed string, signature []byte) error {
+	switch {
+	case alg == HS256 && len(v.cfg.HMACSecret) > 0:
+		mac := hmac.New(sha256.New, v.cfg.HMACSecret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case alg == RS256 && v.cfg.RSAPublicKey != nil:
+		digest := sha256.Sum256([]byte(signed))
+		if err := rsa.VerifyPKCS1v15(v.cfg.RSAPublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedAlg, alg)
+	}
+}
+
+func (v *Verifier) validate(claims RegisteredClaims) error {
+	now := v.now()
+
+	// tokens without exp would be valid forever
+	if claims.ExpiresAt == nil {
+		return ErrMissingExpiry
+	}
+	if !now.Before(claims.ExpiresAt.Time().Add(v.cfg.Leeway)) {
+		return ErrExpired
+	}
+	if claims.NotBefore != nil && now.Add(v.cfg.Leeway).Before(claims.NotBefore.Time()) {
+		return ErrNotYetValid
+	}
+	if v.cfg.Issuer != "" && claims.Issuer != v.cfg.Issuer {
+		return ErrInvalidIssuer
+	}
+	if v.cfg.Audience != "" {
+		found := false
+		for _, aud := range claims.Audience {
+			if aud == v.cfg.Audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrInvalidAudience
+		}
+	}
+
+	return nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
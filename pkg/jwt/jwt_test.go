@@ -0,0 +1,133 @@
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("secret")
+
+// sign builds an HS256 token with the payload as is, so malformed claims can be tested too
+func sign(alg, payload string) string {
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString([]byte(`{"alg":"`+alg+`","typ":"JWT"}`)) + "." + enc.EncodeToString([]byte(payload))
+
+	mac := hmac.New(sha256.New, testSecret)
+	mac.Write([]byte(signed))
+	return signed + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name    string
+		token   string
+		cfg     Config
+		wantErr error
+	}{
+		{
+			name:  "valid",
+			token: sign(HS256, `{"sub":"1","exp":1700000060}`),
+		},
+		{
+			name:  "fractional exp",
+			token: sign(HS256, `{"sub":"1","exp":1700000000.5,"iat":1699999999.25}`),
+		},
+		{
+			name:    "missing exp",
+			token:   sign(HS256, `{"sub":"1"}`),
+			wantErr: ErrMissingExpiry,
+		},
+		{
+			name:    "expired",
+			token:   sign(HS256, `{"sub":"1","exp":1700000000}`),
+			wantErr: ErrExpired,
+		},
+		{
+			name:  "expired within leeway",
+			token: sign(HS256, `{"sub":"1","exp":1699999990}`),
+			cfg:   Config{Leeway: time.Minute},
+		},
+		{
+			name:    "not yet valid",
+			token:   sign(HS256, `{"sub":"1","exp":1700000600,"nbf":1700000060}`),
+			wantErr: ErrNotYetValid,
+		},
+		{
+			name:    "exp is not a number",
+			token:   sign(HS256, `{"sub":"1","exp":"1700000060"}`),
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "wrong issuer",
+			token:   sign(HS256, `{"sub":"1","exp":1700000060,"iss":"other"}`),
+			cfg:     Config{Issuer: "tracker"},
+			wantErr: ErrInvalidIssuer,
+		},
+		{
+			name:  "audience in array",
+			token: sign(HS256, `{"sub":"1","exp":1700000060,"aud":["other","tracker"]}`),
+			cfg:   Config{Audience: "tracker"},
+		},
+		{
+			name:    "wrong audience",
+			token:   sign(HS256, `{"sub":"1","exp":1700000060,"aud":"other"}`),
+			cfg:     Config{Audience: "tracker"},
+			wantErr: ErrInvalidAudience,
+		},
+		{
+			name:    "unsupported algorithm",
+			token:   sign("none", `{"sub":"1","exp":1700000060}`),
+			wantErr: ErrUnsupportedAlg,
+		},
+		{
+			name:    "invalid signature",
+			token:   sign(HS256, `{"sub":"1","exp":1700000060}`) + "x",
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "malformed",
+			token:   "not.a-token",
+			wantErr: ErrMalformed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.HMACSecret = testSecret
+			v, err := NewVerifier(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v.now = func() time.Time { return now }
+
+			_, err = v.Verify(tt.token, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNumericDateTime(t *testing.T) {
+	tests := []struct {
+		date NumericDate
+		want time.Time
+	}{
+		{1700000000, time.Unix(1700000000, 0)},
+		{1700000000.5, time.Unix(1700000000, 500_000_000)},
+		{1700000000.000001, time.Unix(1700000000, 1000)},
+	}
+
+	for _, tt := range tests {
+		if got := tt.date.Time(); !got.Equal(tt.want) {
+			t.Errorf("NumericDate(%v).Time() = %v, want %v", float64(tt.date), got, tt.want)
+		}
+	}
+}